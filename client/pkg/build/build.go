@@ -57,14 +57,19 @@ func NewServiceBuild(appName string, buildType protos2.BuildType) *BuildArgs {
 	}
 }
 
+// NewLibraryBuild builds and tests the application without a container image
+func NewLibraryBuild(appName string, buildType protos2.BuildType) *BuildArgs {
+	lib := NewServiceBuild(appName, buildType)
+	lib.Image = ""
+	return lib
+}
+
 func NewGoServiceBuild(appName string) *BuildArgs {
 	return NewServiceBuild(appName, protos2.BuildType_GoLang)
 }
 
 func NewGoLibraryBuild(appName string) *BuildArgs {
-	lib := NewGoServiceBuild(appName)
-	lib.Image = ""
-	return lib
+	return NewLibraryBuild(appName, protos2.BuildType_GoLang)
 }
 
 func NewMavenServiceBuild(appName string) *BuildArgs {
@@ -84,7 +89,47 @@ func NewPythonServiceBuild(appName string) *BuildArgs {
 }
 
 func NewPythonLibraryBuild(appName string) *BuildArgs {
-	lib := NewPythonServiceBuild(appName)
-	lib.Image = ""
-	return lib
+	return NewLibraryBuild(appName, protos2.BuildType_Python)
+}
+
+func NewNodeServiceBuild(appName string) *BuildArgs {
+	return NewServiceBuild(appName, protos2.BuildType_NodeJS)
+}
+
+func NewNodeLibraryBuild(appName string) *BuildArgs {
+	return NewLibraryBuild(appName, protos2.BuildType_NodeJS)
+}
+
+func NewTypescriptServiceBuild(appName string) *BuildArgs {
+	return NewServiceBuild(appName, protos2.BuildType_Typescript)
+}
+
+func NewTypescriptLibraryBuild(appName string) *BuildArgs {
+	return NewLibraryBuild(appName, protos2.BuildType_Typescript)
+}
+
+func NewRustServiceBuild(appName string) *BuildArgs {
+	return NewServiceBuild(appName, protos2.BuildType_Rust)
+}
+
+func NewRustLibraryBuild(appName string) *BuildArgs {
+	return NewLibraryBuild(appName, protos2.BuildType_Rust)
+}
+
+func NewZigServiceBuild(appName string) *BuildArgs {
+	return NewServiceBuild(appName, protos2.BuildType_Zig)
+}
+
+func NewZigLibraryBuild(appName string) *BuildArgs {
+	return NewLibraryBuild(appName, protos2.BuildType_Zig)
+}
+
+// NewDockerfileBuild builds and pushes the image of the Dockerfile in the build folder,
+// the dockerfile property selects another Dockerfile like Dockerfile.worker
+func NewDockerfileBuild(appName string) *BuildArgs {
+	build := NewServiceBuild(appName, protos2.BuildType_Generic)
+	build.Properties = map[string]*ListValue{
+		"dockerfile": NewList("Dockerfile"),
+	}
+	return build
 }
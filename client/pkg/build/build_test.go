@@ -135,3 +135,42 @@ func TestNewPythonLibraryBuild(t *testing.T) {
 	assert.Equal(t, protos2.BuildType_Python, build.BuildType)
 	assert.Empty(t, build.Image, "library should have empty image")
 }
+
+func TestNewNodeBuilds(t *testing.T) {
+	t.Parallel()
+	service := NewNodeServiceBuild("test-node")
+	assert.Equal(t, protos2.BuildType_NodeJS, service.BuildType)
+	assert.Equal(t, "test-node", service.Image)
+
+	lib := NewTypescriptLibraryBuild("test-ts-lib")
+	assert.Equal(t, protos2.BuildType_Typescript, lib.BuildType)
+	assert.Empty(t, lib.Image, "library should have empty image")
+}
+
+func TestNewRustBuilds(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, protos2.BuildType_Rust, NewRustServiceBuild("test-rust").BuildType)
+	assert.Empty(t, NewRustLibraryBuild("test-rust-lib").Image, "library should have empty image")
+}
+
+func TestNewZigBuilds(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, protos2.BuildType_Zig, NewZigServiceBuild("test-zig").BuildType)
+	assert.Empty(t, NewZigLibraryBuild("test-zig-lib").Image, "library should have empty image")
+}
+
+func TestNewDockerfileBuild(t *testing.T) {
+	t.Parallel()
+	build := NewDockerfileBuild("worker")
+	assert.Equal(t, protos2.BuildType_Generic, build.BuildType)
+	assert.Equal(t, "worker", build.Image)
+	assert.Equal(t, "Dockerfile", build.Properties["dockerfile"].GetValues()[0].GetStringValue())
+}
+
+func TestNewLibraryBuild(t *testing.T) {
+	t.Parallel()
+	lib := NewLibraryBuild("test-lib", protos2.BuildType_Zig)
+	assert.Equal(t, "test-lib", lib.Application)
+	assert.Equal(t, protos2.BuildType_Zig, lib.BuildType)
+	assert.Empty(t, lib.Image, "library should have empty image")
+}
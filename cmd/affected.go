@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/containifyci/engine-ci/pkg/autodiscovery"
+	"github.com/containifyci/engine-ci/pkg/config"
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/github"
+	"github.com/containifyci/engine-ci/pkg/report"
+)
+
+// affectedBuilds keeps the builds affected by the changes since the git ref and
+// reports the skipped builds. Changes of the build configuration affect all builds.
+func affectedBuilds(groups container.BuildGroups, ref string) (container.BuildGroups, error) {
+	root, err := github.RepositoryRoot()
+	if err != nil {
+		return nil, err
+	}
+	files, err := github.GetChangedFilesSince(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// git reports the root with resolved symlinks, the build folders are relative to the working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+
+	configFiles := configurationFiles()
+	changed := make([]string, 0, len(files))
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			path = rel
+		}
+		if abs, err := filepath.Abs(path); err == nil && configFiles[abs] {
+			slog.Info("Build configuration changed, running all builds", "file", file, "since", ref)
+			return groups, nil
+		}
+		changed = append(changed, path)
+	}
+
+	affected, skipped := autodiscovery.AffectedBuilds(groups, changed)
+	for _, b := range skipped {
+		slog.Info("Skipping build not affected by the changes", "app", b.App, "folder", b.Folder, "since", ref)
+		report.Default().SkipBuild(b.App, fmt.Sprintf("not affected by the changes since %s", ref))
+	}
+	count := 0
+	for _, group := range affected {
+		count += len(group.Builds)
+	}
+	slog.Info("Selected affected builds", "since", ref, "changedFiles", len(files), "affected", count, "skipped", len(skipped))
+	return affected, nil
+}
+
+// configurationFiles returns the absolute paths of the build configuration
+// and of the module the plugin is compiled with
+func configurationFiles() map[string]bool {
+	src := config.DefaultSource()
+	files := map[string]bool{}
+	add := func(file string) {
+		if abs, err := filepath.Abs(file); err == nil {
+			files[abs] = true
+		}
+	}
+	add(src.Path())
+	if p, ok := src.(config.Plugin); ok {
+		if mod, err := config.FindModule(p.Dir); err == nil {
+			add(mod.Path)
+			add(filepath.Join(filepath.Dir(mod.Path), "go.sum"))
+		}
+	}
+	return files
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffectedBuilds(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("CONTAINIFYCI_FILE", ".containifyci/containifyci.go")
+	git := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(file string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(file), 0644))
+	}
+
+	write("api/main.go")
+	write("web/Dockerfile")
+	write(".containifyci/containifyci.go")
+	git("init", "-q", "-b", "main")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	api := &container.Build{App: "api", BuildType: container.GoLang, Folder: "api"}
+	web := &container.Build{App: "web", BuildType: container.Generic, Folder: "web"}
+	groups := container.BuildGroups{{Builds: []*container.Build{api, web}}}
+
+	require.NoError(t, os.WriteFile("web/Dockerfile", []byte("FROM scratch"), 0644))
+	affected, err := affectedBuilds(groups, "main")
+	require.NoError(t, err)
+	assert.Equal(t, container.BuildGroups{{Builds: []*container.Build{web}}}, affected)
+
+	require.NoError(t, os.WriteFile(".containifyci/containifyci.go", []byte("package main"), 0644))
+	affected, err = affectedBuilds(groups, "main")
+	require.NoError(t, err)
+	assert.Equal(t, groups, affected)
+
+	_, err = affectedBuilds(groups, "unknown")
+	assert.Error(t, err)
+}
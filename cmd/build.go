@@ -3,14 +3,17 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"os"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/containifyci/engine-ci/pkg/build"
 	"github.com/containifyci/engine-ci/pkg/container"
 	"github.com/containifyci/engine-ci/pkg/kv"
 	"github.com/containifyci/engine-ci/pkg/logger"
 	"github.com/containifyci/engine-ci/pkg/network"
+	"github.com/containifyci/engine-ci/pkg/report"
 	"github.com/containifyci/engine-ci/pkg/svc"
 
 	"github.com/spf13/cobra"
@@ -124,17 +127,60 @@ func (c *Command) AddTarget(name string, fnc func() build.BuildResult) {
 	}
 }
 
+var publishLogsOnce sync.Once
+
+// publishLogs streams the log lines to the subscribers of the /events endpoint.
+func publishLogs() {
+	publishLogsOnce.Do(func() {
+		logger.AddLineHook(func(l logger.Line) {
+			report.Default().Publish(report.Event{
+				Time:      l.Time,
+				Type:      report.EventLog,
+				App:       l.Fields.App,
+				Step:      stepRef(l.Fields.Step),
+				Container: l.Fields.Container,
+				Image:     l.Fields.Image,
+				Message:   l.Msg,
+			})
+		})
+	})
+}
+
+func stepRef(name string) *report.Step {
+	if name == "" {
+		return nil
+	}
+	return &report.Step{Name: name}
+}
+
 func Start() (func(), network.Address, error) {
-	srv, fnc, err := kv.StartHttpServer(kv.NewKeyValueStore())
+	publishLogs()
+	store := kv.NewKeyValueStore()
+	if RootArgs.PersistKV {
+		if err := store.Persist(kv.DefaultFile); err != nil {
+			return nil, network.Address{}, err
+		}
+		slog.Info("Using persistent key value store", "file", kv.DefaultFile)
+	}
+	srv, fnc, err := kv.StartHttpServer(store, kv.Route{Pattern: "GET /events", Handler: report.EventsHandler(report.Default())})
 	if err != nil {
 		return nil, network.Address{}, fmt.Errorf("failed to start http server: %w", err)
 	}
 	go fnc()
 	slog.Info("Started http server", "address", srv.Listener.Addr().String())
-	addr := network.Address{Host: "localhost", Port: srv.Port, Secret: srv.Secret}
+	if err := kv.WriteToken(kv.EventsTokenFile, srv.Token(kv.EventsScope(kv.EventsTokenTTL))); err != nil {
+		slog.Warn("Failed to write the events token", "error", err)
+	} else {
+		slog.Info("Stream the build events with the token of the file as bearer token",
+			"url", fmt.Sprintf("http://localhost:%d/events", srv.Port),
+			"file", kv.EventsTokenFile)
+	}
+	addr := network.Address{Host: "localhost", Port: srv.Port, Secret: srv.Secret, Token: srv.Token}
 	return func() {
 		slog.Info("Stopping http server")
 		_ = srv.Listener.Close()
+		_ = srv.Artifacts.Close()
+		_ = os.Remove(kv.EventsTokenFile)
 	}, addr, nil
 }
 
@@ -144,7 +190,16 @@ func (c *Command) Run(addr network.Address, target string, arg *container.Build)
 	}
 	arg.Custom["CONTAINIFYCI_EXTERNAL_HOST"] = []string{fmt.Sprintf("%s:%d", addr.Host, addr.Port)}
 	arg.Custom["CONTAINIFYCI_HOST"] = []string{fmt.Sprintf("%s:%d", addr.ForContainerDefault(arg), addr.Port)}
-	arg.Secret = map[string]string{"CONTAINIFYCI_AUTH": addr.Secret}
+	logger.RegisterSecret(addr.Secret)
+	if addr.Token != nil {
+		// the step containers only get tokens restricted to their keys, never the engine token
+		arg.Secret = map[string]string{}
+		arg.StepToken = func(step string) string {
+			return addr.Token(kv.StepScope(arg.App, step, arg.StepTokenTTL()))
+		}
+	} else {
+		arg.Secret = map[string]string{"CONTAINIFYCI_AUTH": addr.Secret}
+	}
 	_ = Pre(arg)
 	for _, b := range c.buildSteps.Steps {
 		if b.Build().BuildType() == nil || *b.Build().BuildType() == arg.BuildType {
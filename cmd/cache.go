@@ -29,7 +29,7 @@ var cacheSaveCmd = &cobra.Command{
 		err := SaveCache()
 		if err != nil {
 			slog.Error("Error saving cache", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 	},
 }
@@ -43,7 +43,7 @@ var cacheLoadCmd = &cobra.Command{
 		err := LoadCache()
 		if err != nil {
 			slog.Error("Error loading cache", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 	},
 }
@@ -74,7 +74,7 @@ func SaveCache() error {
 		info, err := utils.ParseDockerImage(image)
 		if err != nil {
 			slog.Error("Error parsing image", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 		cmd := fmt.Sprintf(`
 	set -x
@@ -121,7 +121,7 @@ func LoadCache() error {
 		info, err := utils.ParseDockerImage(image)
 		if err != nil {
 			slog.Error("Error parsing image", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 		// nolint:staticcheck
 		if arg.Runtime == utils.Docker {
@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/containifyci/engine-ci/pkg/config"
 	"github.com/containifyci/engine-ci/pkg/doctor"
+	"github.com/containifyci/engine-ci/pkg/utils"
+	"github.com/containifyci/engine-ci/protos2"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -20,6 +25,8 @@ type doctorArgs struct {
 	NoColor            bool
 	Parallel           bool
 	KeepTestContainers bool
+	Fix                bool
+	Yes                bool
 }
 
 var doctorCmdArgs = &doctorArgs{}
@@ -34,12 +41,16 @@ It checks:
   - Container runtime detection and connectivity
   - Build tool availability (BuildKit, etc.)
   - Network connectivity to registries
-  - System resources (memory, disk)
+  - Registry credentials of the build configuration
+  - SSH agent for private Go modules
+  - System resources (memory, disk, GOMODCACHE permissions)
   - User permissions and group memberships
   - GitHub Actions configuration (when applicable)
+  - Build configuration: Go toolchain, go.mod and the containifyci.go plugin
 
 Use --verbose for detailed diagnostic output.
-Use --json for machine-readable output.`,
+Use --json for machine-readable output.
+Use --fix to apply the known fixes of failed checks, --yes skips the confirmation.`,
 	Example: `  # Run all checks
   engine-ci doctor
 
@@ -49,6 +60,9 @@ Use --json for machine-readable output.`,
   # Output as JSON
   engine-ci doctor --json
 
+  # Apply the known fixes without confirmation
+  engine-ci doctor --fix --yes
+
   # Run only specific categories
   engine-ci doctor --category "Container Runtime" --category "Network Access"`,
 	Annotations: map[string]string{
@@ -72,6 +86,10 @@ func init() {
 		"Run only specific categories")
 	doctorCmd.Flags().DurationVar(&doctorCmdArgs.Timeout, "timeout", 30*time.Second,
 		"Timeout for individual checks")
+	doctorCmd.Flags().BoolVar(&doctorCmdArgs.Fix, "fix", false,
+		"Apply the known fixes of failed checks and re-run them")
+	doctorCmd.Flags().BoolVarP(&doctorCmdArgs.Yes, "yes", "y", false,
+		"Apply fixes without confirmation")
 }
 
 func runDoctor(_ *cobra.Command, _ []string) error {
@@ -95,6 +113,7 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 		JSONOutput: doctorCmdArgs.JSONOutput,
 		Parallel:   doctorCmdArgs.Parallel,
 		Categories: categories,
+		Registries: doctorRegistries,
 	}
 	d := doctor.NewDoctor(opts)
 
@@ -107,6 +126,11 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 	slog.Debug("Running diagnostic checks")
 	results := d.RunChecks(ctx)
 
+	var fixes []doctor.FixResult
+	if doctorCmdArgs.Fix {
+		results, fixes = d.Fix(ctx, results, confirmFix)
+	}
+
 	// Format and output results
 	useColor := !doctorCmdArgs.NoColor && isTerminal(os.Stdout)
 	formatter := doctor.NewResultFormatter(os.Stdout,
@@ -114,7 +138,7 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 		doctorCmdArgs.JSONOutput,
 		useColor)
 
-	if err := formatter.FormatResults(results); err != nil {
+	if err := formatter.FormatReport(results, fixes); err != nil {
 		return fmt.Errorf("failed to format results: %w", err)
 	}
 
@@ -129,7 +153,49 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+var fixInput = bufio.NewReader(os.Stdin)
+
+// confirmFix asks on the terminal whether to apply the fix, without a
+// terminal fixes are only applied with --yes.
+func confirmFix(result doctor.CheckResult) bool {
+	if doctorCmdArgs.Yes {
+		return true
+	}
+	if !isTerminal(os.Stdin) {
+		slog.Warn("Skip fix without confirmation use --yes", "check", result.CheckName)
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s\n  Fix: %s? [y/N] ", result.CheckName, result.Message, result.Remediation.Description)
+	answer, _ := fixInput.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 // isTerminal checks if output is a terminal
 func isTerminal(f *os.File) bool {
 	return term.IsTerminal(int(f.Fd()))
 }
+
+// doctorRegistries returns the resolved credentials of the registries
+// configured in the build configuration.
+func doctorRegistries(groups []*protos2.BuildArgsGroup) []doctor.RegistryCredentials {
+	seen := map[string]bool{}
+	registries := []doctor.RegistryCredentials{}
+	for _, group := range config.BuildGroups(groups) {
+		for _, b := range group.Builds {
+			for host, reg := range b.Registries {
+				if seen[host] || reg == nil {
+					continue
+				}
+				seen[host] = true
+				registries = append(registries, doctor.RegistryCredentials{
+					Host:     host,
+					Username: utils.GetBuildValue(b.App, reg.Username, b.Env.String()),
+					Password: utils.GetSecretValue(b.App, reg.Password, b.Env.String()),
+					Ref:      reg.Password,
+				})
+			}
+		}
+	}
+	return registries
+}
@@ -1,47 +1,48 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"log/slog"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/containifyci/engine-ci/pkg/autodiscovery"
 	"github.com/containifyci/engine-ci/pkg/build"
+	"github.com/containifyci/engine-ci/pkg/config"
 	"github.com/containifyci/engine-ci/pkg/container"
 	"github.com/containifyci/engine-ci/pkg/copier"
-	"github.com/containifyci/engine-ci/pkg/cri"
-	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/pkg/dockerfile"
 	"github.com/containifyci/engine-ci/pkg/dummy"
 	"github.com/containifyci/engine-ci/pkg/gcloud"
 	"github.com/containifyci/engine-ci/pkg/github"
 	"github.com/containifyci/engine-ci/pkg/golang"
 	"github.com/containifyci/engine-ci/pkg/goreleaser"
+	"github.com/containifyci/engine-ci/pkg/logger"
 	"github.com/containifyci/engine-ci/pkg/maven"
 	"github.com/containifyci/engine-ci/pkg/network"
 	"github.com/containifyci/engine-ci/pkg/packer"
 	"github.com/containifyci/engine-ci/pkg/protobuf"
+	"github.com/containifyci/engine-ci/pkg/provenance"
 	"github.com/containifyci/engine-ci/pkg/pulumi"
 	"github.com/containifyci/engine-ci/pkg/python"
+	"github.com/containifyci/engine-ci/pkg/report"
 	"github.com/containifyci/engine-ci/pkg/sonarcloud"
 	"github.com/containifyci/engine-ci/pkg/trivy"
 	"github.com/containifyci/engine-ci/pkg/utils"
 	"github.com/containifyci/engine-ci/pkg/zig"
-	"github.com/containifyci/engine-ci/protos2"
 	"github.com/spf13/cobra"
 
 	"github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/go-plugin"
 )
 
 // TODO (tight coupling buildsteps and build paramater) we have to uncouple the BuildSteps initialization from the build parameters.
 // Otherwise we have to initialize the BuildSteps for every build new which is not optimal.
 var buildSteps *build.BuildSteps
 
+const reportFile = ".containifyci/report.json"
+
 // buildCmd represents the build command
 var engineCmd = &cobra.Command{
 	Use:   "engine",
@@ -92,14 +93,46 @@ func Engine(cmd *cobra.Command, _ []string) error {
 	InitBuildSteps()
 
 	groups := GetBuild(false)
+	if RootArgs.AffectedSince != "" {
+		groups, err = affectedBuilds(groups, RootArgs.AffectedSince)
+		if err != nil {
+			return err
+		}
+	}
 	idStore := utils.IDStore{}
 	for _, group := range groups {
 		executeBuildGroup(group, &leader, &idStore, addr)
 	}
 	slog.Info("Finish waiting for all builds to complete")
+	writeReport()
 	return nil
 }
 
+// writeReport writes the run report with the step results and artifacts
+// and the job summary when running in GitHub Actions.
+func writeReport() {
+	if err := report.Default().WriteFile(reportFile); err != nil {
+		slog.Warn("Failed to write run report", "error", err)
+	}
+	if err := writeStepSummary(os.Getenv("GITHUB_STEP_SUMMARY")); err != nil {
+		slog.Warn("Failed to write GitHub step summary", "error", err)
+	}
+}
+
+// writeStepSummary appends the Markdown report to the GitHub Actions job summary.
+func writeStepSummary(file string) error {
+	if file == "" || !logger.GitHubActions().Enabled() {
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(report.Default().Markdown())
+	return err
+}
+
 // executeBuild executes a single build with proper context and error handling.
 // It handles leader assignment, command execution, and result tracking.
 // This function is designed to be called from a goroutine.
@@ -108,18 +141,22 @@ func executeBuild(b *container.Build, leader *LeaderElection, idStore *utils.IDS
 	b.Leader = leader
 	slog.Info("Starting build", "build", b, "steps", buildSteps.String())
 
+	done := report.Default().StartBuild(b.App)
 	c := NewCommand(*b, buildSteps)
 	result := c.Run(addr, RootArgs.Target, b)
+	done(result.Error)
 	slog.Info("Build completed", "app", b.App, "ids", result.IDs, "loop", result.Loop)
 
 	if result.Error != nil {
 		slog.Error("Executing command", "error", result.Error, "command", c)
-		os.Exit(1)
+		writeReport()
+		exit(1)
 	}
 
 	if result.Loop == container.BuildStop {
 		slog.Info("Build requested to stop further builds", "app", b.App)
-		os.Exit(0)
+		writeReport()
+		exit(0)
 	}
 
 	idStore.Add(result.IDs...)
@@ -144,14 +181,19 @@ func executeBuildGroup(group *container.BuildGroup, leader *LeaderElection, idSt
 
 func GetBuild(auto bool) container.BuildGroups {
 	if auto {
-		// Use auto-discovery to detect Go projects
-		projects, err := autodiscovery.DiscoverProjects(".")
+		// Use auto-discovery with the same languages as init
+		projects, err := autodiscovery.DiscoverAllProjects(discoveryOptions(autodiscovery.AllLanguages(), false))
 		if err != nil {
 			slog.Error("Auto-discovery failed", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 		slog.Info("Auto-discovered projects", "count", len(projects.AllProjects()))
-		return autodiscovery.GenerateBuildGroups(projects.AllProjects())
+		groups, err := autodiscovery.GenerateBuildGroupsFromCollection(projects)
+		if err != nil {
+			slog.Error("Auto-discovery failed", "error", err)
+			exit(1)
+		}
+		return groups
 	}
 
 	logger := hclog.New(&hclog.LoggerOptions{
@@ -163,147 +205,20 @@ func GetBuild(auto bool) container.BuildGroups {
 	hclog.SetDefault(logger)
 	hclog.Default().SetLevel(hclog.Error)
 
-	//TODO - make it configurable to support different languages
-	file := os.Getenv("CONTAINIFYCI_FILE")
-
-	path := filepath.Dir(file)
-	if file == "" {
-		file = "containifyci.go"
-	} else {
-		file = filepath.Base(file)
-	}
-
 	// We don't want to see the plugin logs.
 	log.SetOutput(os.Stderr)
 
-	fmt.Printf("go run -C %s %s\n", path, file)
-
-	// We're a host. Start by launching the plugin process.
-	client := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: protos2.Handshake,
-		// Plugins:          protos2.PluginMap,
-		VersionedPlugins: protos2.PluginMap,
-		Stderr:           os.Stderr,
-		Cmd:              exec.Command("go", "run", "-C", path, file),
-		AllowedProtocols: []plugin.Protocol{
-			plugin.ProtocolNetRPC,
-			plugin.ProtocolGRPC,
-		},
-		Logger: logger,
-	})
-	defer client.Kill()
-
-	// Connect via RPC
-	rpcClient, err := client.Client()
-	if err != nil {
-		logger.Error("Error:", "error", err.Error())
-		os.Exit(1)
+	src := config.DefaultSource()
+	if content, err := os.ReadFile(src.Path()); err == nil {
+		report.Default().SetConfig(src.Path(), utils.ShortChecksum(content))
 	}
 
-	// Request the plugin
-	raw, err := rpcClient.Dispense("containifyci")
+	opts, err := src.Load(context.Background())
 	if err != nil {
 		logger.Error("Error:", "error", err.Error())
-		os.Exit(1)
-	}
-
-	opts := CallPlugin(logger, raw)
-
-	groups := container.BuildGroups{}
-
-	for _, group := range opts {
-		g := &container.BuildGroup{}
-
-		for _, opt := range group.Args {
-			arg := container.Build{
-				App:            opt.Application,
-				BuildType:      container.BuildType(opt.BuildType.String()),
-				ContainerFiles: opt.ContainerFiles,
-				Env:            container.EnvType(opt.Environment.String()),
-				Image:          opt.Image,
-				ImageTag:       opt.ImageTag,
-				Registry:       opt.Registry,
-				Registries:     opt.Registries,
-				Repository:     opt.Repository,
-				Runtime:        cri.DetectContainerRuntime(),
-				File:           opt.File,
-				Folder:         opt.Folder,
-				SourcePackages: opt.SourcePackages,
-				SourceFiles:    opt.SourceFiles,
-				Organization:   opt.Organization,
-				Verbose:        opt.Verbose,
-			}
-
-			if opt.Properties != nil {
-				arg.Custom = make(map[string][]string)
-				for k, v := range opt.Properties {
-					arg.Custom[k] = make([]string, len(v.Values))
-					for i, l := range v.Values {
-						arg.Custom[k][i] = l.GetStringValue()
-					}
-				}
-			}
-
-			if opt.Platform != "" {
-				platform := types.Platform{
-					Host: types.ParsePlatform(opt.Platform),
-				}
-
-				platform.Container = types.GetContainerPlatform(platform.Host)
-				arg.Platform = platform
-			}
-
-			if len(opt.Secrets) > 0 {
-				m := make(map[string]*container.BuildSecret, len(opt.Secrets))
-				for _, s := range opt.Secrets {
-					m[s.Key] = container.NewBuildSecret(s)
-				}
-				arg.Secrets = m
-			}
-			arg.Defaults()
-			// args = append(args, &arg)
-			g.Builds = append(g.Builds, &arg)
-			// slog.Info("Build Arg", "arg", arg)
-		}
-		groups = append(groups, g)
-	}
-	return groups
-}
-
-func CallPlugin(logger hclog.Logger, plugin interface{}) []*protos2.BuildArgsGroup {
-	// We should have a Counter store now! This feels like a normal interface
-	// implementation but is in fact over an RPC connection.
-	{
-		containifyci, ok := plugin.(protos2.ContainifyCIv2)
-		if ok {
-			resp, err := containifyci.GetBuilds()
-			if err != nil {
-				logger.Error("Failed to get builds from plugin", "error", err)
-				os.Exit(1)
-			}
-			return resp.Args
-		}
-	}
-	{
-		containifyci, ok := plugin.(protos2.ContainifyCIv1)
-		if !ok {
-			logger.Error("Can't use v1 plugin version.")
-			os.Exit(1)
-		}
-		resp, err := containifyci.GetBuild()
-		if err != nil {
-			logger.Error("Failed to get build from plugin", "error", err)
-			os.Exit(1)
-		}
-
-		groups := []*protos2.BuildArgsGroup{}
-		for _, a := range resp.Args {
-			groups = append(groups, &protos2.BuildArgsGroup{
-				Args: []*protos2.BuildArgs{a},
-			})
-		}
-		return groups
+		exit(1)
 	}
+	return config.BuildGroups(opts)
 }
 
 // GetDefaultBuildSteps returns the current BuildSteps instance with all default build steps.
@@ -356,6 +271,7 @@ func InitBuildSteps() {
 		addStep(build.PostBuild, maven.NewProd())        // Maven prod
 		addStep(build.PostBuild, python.NewProd())       // Python prod
 		addStep(build.PostBuild, zig.NewProd())          // Zig prod
+		addStep(build.PostBuild, dockerfile.New())       // Dockerfile images
 
 		// Quality: Linting, testing, security scanning
 		addStep(build.Quality, golang.NewLinter()) // Golang linter (async)
@@ -369,6 +285,7 @@ func InitBuildSteps() {
 		// Publish: Publishing, releases, notifications
 		addStep(build.Publish, goreleaser.New()) // Goreleaser
 		addStep(build.Publish, github.New())     // GitHub (async)
+		addStep(build.Publish, provenance.New()) // SLSA provenance
 
 		addStep(build.Publish, dummy.New()) // Goreleaser
 		buildSteps.Init()
@@ -10,8 +10,11 @@ import (
 	"text/template"
 
 	"github.com/containifyci/engine-ci/pkg/autodiscovery"
+	"github.com/containifyci/engine-ci/pkg/config"
 	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // TemplateData holds the data passed to the containifyci.go template
@@ -26,21 +29,29 @@ var mage []byte
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Command to generate containifyci.go file for containifyci usage",
-	Long:  `Command to generate containifyci.go file for containifyci usage. Use --auto to generate based on auto-discovered projects in Go, Python, and Java.`,
-	RunE:  RunInit,
+	Long: `Command to generate containifyci.go file for containifyci usage. Use --auto to generate based on auto-discovered projects in Go, Python, Java and Zig, plus standalone Dockerfiles.
+Node.js and Rust projects are only discovered with --languages node or rust, the engine has no build step for them yet and their generated builds are not run.
+Use --format yaml to generate the declarative containifyci.yaml instead, it is loaded without compiling a Go plugin.`,
+	RunE: RunInit,
 }
 
 func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().BoolP("auto", "a", false, "Auto-discover projects and generate configuration")
-	initCmd.Flags().StringSliceP("languages", "l", []string{"go", "python", "java"}, "Languages to discover (go, python, java)")
+	// node and rust are opt-in, the engine has no NodeJS, Typescript and Rust build steps yet
+	initCmd.Flags().StringSliceP("languages", "l", []string{"go", "python", "java", "zig", "docker"}, "Languages to discover (go, python, java, zig, docker for standalone Dockerfiles). node and rust are opt-in, their builds are generated but the engine has no build step for them yet")
 	initCmd.Flags().BoolP("verbose", "v", false, "Enable verbose logging during discovery")
+	initCmd.Flags().StringP("format", "f", "go", "Format of the build configuration (go for containifyci.go, yaml for containifyci.yaml)")
 }
 
 // createContainifyCIFileWithProjectCollection creates containifyci.go file using template with build groups from project collection
 func createContainifyCIFileWithProjectCollection(collection *autodiscovery.ProjectCollection) error {
 	// Generate build groups from discovered projects
-	buildGroups := autodiscovery.GenerateBuildGroupsFromCollection(collection)
+	buildGroups, err := autodiscovery.GenerateBuildGroupsFromCollection(collection)
+	if err != nil {
+		slog.Error("Failed to generate build groups", "error", err)
+		return err
+	}
 
 	if len(buildGroups) == 0 {
 		slog.Warn("No valid build groups generated. Falling back to static template.")
@@ -61,7 +72,7 @@ func createContainifyCIFileWithProjectCollection(collection *autodiscovery.Proje
 	var buf bytes.Buffer
 	templateData := TemplateData{Groups: buildGroups}
 
-	err := template.Must(template.New("containifyci-go").Parse(string(mage))).
+	err = template.Must(template.New("containifyci-go").Parse(string(mage))).
 		Execute(&buf, templateData)
 	if err != nil {
 		slog.Error("Failed to render containifyci go file with build groups", "error", err)
@@ -92,7 +103,11 @@ func createContainifyCIFileWithProjectCollection(collection *autodiscovery.Proje
 // createContainifyCIFileWithProjects creates containifyci.go file using template with build groups (legacy Go-only function)
 func createContainifyCIFileWithProjects(projects []autodiscovery.Project) error {
 	// Generate build groups from discovered projects
-	buildGroups := autodiscovery.GenerateBuildGroups(projects)
+	buildGroups, err := autodiscovery.GenerateBuildGroups(projects)
+	if err != nil {
+		slog.Error("Failed to generate build groups", "error", err)
+		return err
+	}
 
 	if len(buildGroups) == 0 {
 		slog.Warn("No valid build groups generated. Falling back to static template.")
@@ -113,7 +128,7 @@ func createContainifyCIFileWithProjects(projects []autodiscovery.Project) error
 	var buf bytes.Buffer
 	templateData := TemplateData{Groups: buildGroups}
 
-	err := template.Must(template.New("containifyci-go").Parse(string(mage))).
+	err = template.Must(template.New("containifyci-go").Parse(string(mage))).
 		Execute(&buf, templateData)
 	if err != nil {
 		slog.Error("Failed to render containifyci go file with build groups", "error", err)
@@ -157,6 +172,15 @@ func RunInit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		slog.Error("Failed to get format flag", "error", err)
+		return err
+	}
+	if format != "go" && format != "yaml" {
+		return fmt.Errorf("unknown format %q, expected go or yaml", format)
+	}
+
 	if auto {
 		// Get language filter and verbose flags
 		languages, err := cmd.Flags().GetStringSlice("languages")
@@ -181,6 +205,14 @@ func RunInit(cmd *cobra.Command, args []string) error {
 				filter.Python = true
 			case "java":
 				filter.Java = true
+			case "node":
+				filter.Node = true
+			case "rust":
+				filter.Rust = true
+			case "zig":
+				filter.Zig = true
+			case "docker":
+				filter.Docker = true
 			default:
 				slog.Warn("Unknown language", "language", lang)
 			}
@@ -188,13 +220,7 @@ func RunInit(cmd *cobra.Command, args []string) error {
 
 		// Use multi-language autodiscovery
 		slog.Info("Auto-discovering projects...", "languages", languages)
-		options := autodiscovery.DiscoveryOptions{
-			RootDir:   ".",
-			Languages: filter,
-			Verbose:   verbose,
-		}
-
-		collection, err := autodiscovery.DiscoverAllProjects(options)
+		collection, err := autodiscovery.DiscoverAllProjects(discoveryOptions(filter, verbose))
 		if err != nil {
 			slog.Error("Failed to discover projects", "error", err)
 			return fmt.Errorf("autodiscovery failed: %w", err)
@@ -202,6 +228,9 @@ func RunInit(cmd *cobra.Command, args []string) error {
 
 		if collection.IsEmpty() {
 			slog.Warn("No projects discovered. Falling back to static template.")
+			if format == "yaml" {
+				return createContainifyCIYAML(nil)
+			}
 			return createContainifyCIFile()
 		}
 
@@ -210,16 +239,136 @@ func RunInit(cmd *cobra.Command, args []string) error {
 			"totalProjects", len(collection.AllProjects()),
 			"go", counts[autodiscovery.ProjectTypeGo],
 			"python", counts[autodiscovery.ProjectTypePython],
-			"java", counts[autodiscovery.ProjectTypeJava])
+			"java", counts[autodiscovery.ProjectTypeJava],
+			"node", counts[autodiscovery.ProjectTypeNode],
+			"rust", counts[autodiscovery.ProjectTypeRust],
+			"zig", counts[autodiscovery.ProjectTypeZig],
+			"docker", counts[autodiscovery.ProjectTypeDocker])
+		if n := counts[autodiscovery.ProjectTypeNode] + counts[autodiscovery.ProjectTypeRust]; n > 0 {
+			slog.Warn("The engine has no build step for the discovered Node.js and Rust projects yet, their builds are generated but not run", "count", n)
+		}
+
+		if format == "yaml" {
+			groups, err := autodiscovery.GenerateBuildGroupsFromCollection(collection)
+			if err != nil {
+				slog.Error("Failed to generate build groups", "error", err)
+				return err
+			}
+			return createContainifyCIYAML(groups)
+		}
 
 		// Create file with discovered projects using template
 		return createContainifyCIFileWithProjectCollection(collection)
+	} else if format == "yaml" {
+		return createContainifyCIYAML(nil)
 	} else {
 		// Use static template (existing behavior)
 		return createContainifyCIFile()
 	}
 }
 
+// createContainifyCIYAML creates the declarative containifyci.yaml file from the
+// build groups, without build groups the static example is written
+func createContainifyCIYAML(groups container.BuildGroups) error {
+	fileName := ".containifyci/containifyci.yaml"
+
+	// Check if the file exists
+	if _, err := os.Stat(fileName); err == nil {
+		slog.Debug("File already exists", "file", fileName)
+		return nil
+	} else if !os.IsNotExist(err) {
+		slog.Error("Error checking file", "error", err, "file", fileName)
+		return err
+	}
+	if _, err := os.Stat(".containifyci/containifyci.go"); err == nil {
+		slog.Warn("The containifyci.go plugin takes precedence over the containifyci.yaml, remove it to use the yaml", "file", ".containifyci/containifyci.go")
+	}
+
+	content, err := config.MarshalDeclarative(declarativeGroups(groups))
+	if err != nil {
+		slog.Error("Failed to render containifyci yaml file", "error", err)
+		return err
+	}
+	header := "# engine-ci build configuration, every list entry is a build group.\n" +
+		"# The builds of a group run concurrently, the groups run one after another.\n"
+
+	err = os.WriteFile(fileName, append([]byte(header), content...), 0644)
+	if err != nil {
+		slog.Error("Failed to write containifyci yaml file", "error", err)
+		return err
+	}
+
+	slog.Info("Created .containifyci/containifyci.yaml file", "file", fileName, "groupCount", len(groups))
+	return nil
+}
+
+// discoveryOptions discovers the projects of the working directory, Dockerfiles
+// of the intermediate images of the build steps are skipped.
+func discoveryOptions(filter autodiscovery.LanguageFilter, verbose bool) autodiscovery.DiscoveryOptions {
+	options := autodiscovery.DiscoveryOptions{
+		RootDir:   ".",
+		Languages: filter,
+		Verbose:   verbose,
+	}
+	if filter.Docker {
+		options.Images = knownImages()
+	}
+	return options
+}
+
+// knownImages returns the containifyci intermediate images, the Dockerfiles
+// building them keep the image name and checksum tag of their build step.
+func knownImages() []autodiscovery.KnownImage {
+	// CollectImages silences the logs
+	defer slog.SetDefault(slog.Default())
+	var images []autodiscovery.KnownImage
+	for _, image := range CollectImages() {
+		images = append(images, autodiscovery.KnownImage{Dockerfile: image.Dockerfile, Name: image.Name})
+	}
+	return images
+}
+
+// declarativeGroups converts the builds to the build arguments the generated
+// containifyci.go would return
+func declarativeGroups(groups container.BuildGroups) []*protos2.BuildArgsGroup {
+	if len(groups) == 0 {
+		//TODO: adjust the registry to your own container registry
+		return []*protos2.BuildArgsGroup{{Args: []*protos2.BuildArgs{{
+			Application: "containifyci-example",
+			BuildType:   protos2.BuildType_GoLang,
+			File:        "main.go",
+			Image:       "containifyci-example",
+			Registry:    "containifyci",
+		}}}}
+	}
+
+	args := make([]*protos2.BuildArgsGroup, 0, len(groups))
+	for _, group := range groups {
+		g := &protos2.BuildArgsGroup{}
+		for _, b := range group.Builds {
+			arg := &protos2.BuildArgs{
+				Application: b.App,
+				BuildType:   protos2.BuildType(protos2.BuildType_value[string(b.BuildType)]),
+				Image:       b.Image,
+				Folder:      b.Folder,
+			}
+			if len(b.Custom) > 0 {
+				arg.Properties = map[string]*structpb.ListValue{}
+				for key, values := range b.Custom {
+					list := &structpb.ListValue{}
+					for _, v := range values {
+						list.Values = append(list.Values, structpb.NewStringValue(v))
+					}
+					arg.Properties[key] = list
+				}
+			}
+			g.Args = append(g.Args, arg)
+		}
+		args = append(args, g)
+	}
+	return args
+}
+
 func RunMage(cmd *cobra.Command, args []string) {
 	err := createContainifyCIDir()
 	if err != nil {
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"testing"
+	"text/template"
 
 	"github.com/containifyci/engine-ci/pkg/autodiscovery"
+	"github.com/containifyci/engine-ci/pkg/config"
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -213,3 +219,61 @@ func TestRunInitWithoutAutoFlag(t *testing.T) {
 	assert.Contains(t, contentStr, "build.NewGoServiceBuild(\"containifyci-example\")")
 	assert.Contains(t, contentStr, "build.Build(opts)")
 }
+
+func TestTemplateRendersProperties(t *testing.T) {
+	groups := container.BuildGroups{{Builds: []*container.Build{{
+		App:             "api",
+		BuilderFunction: "NewTypescriptServiceBuild",
+		Folder:          "web/packages/api",
+		Custom:          container.Custom{"package_manager": {"pnpm"}, "workspace": {"web"}},
+	}}}}
+
+	var buf bytes.Buffer
+	err := template.Must(template.New("containifyci-go").Parse(string(mage))).
+		Execute(&buf, TemplateData{Groups: groups})
+	require.NoError(t, err)
+
+	content := buf.String()
+	assert.Contains(t, content, `api := build.NewTypescriptServiceBuild("api")`)
+	assert.Contains(t, content, `api.Properties = map[string]*build.ListValue{`)
+	assert.Contains(t, content, `"package_manager": build.NewList("pnpm"),`)
+	assert.Contains(t, content, `"workspace": build.NewList("web"),`)
+}
+
+func TestRunInitYAMLFormat(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cmd := initCmd
+	require.NoError(t, cmd.Flags().Set("format", "yaml"))
+	defer func() {
+		require.NoError(t, cmd.Flags().Set("format", "go"))
+	}()
+
+	err := RunInit(cmd, []string{})
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, ".containifyci/containifyci.go")
+	groups, err := config.Declarative{Dir: ".containifyci", File: "containifyci.yaml"}.Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "containifyci-example", groups[0].Args[0].Application)
+	assert.Equal(t, "containifyci", groups[0].Args[0].Registry)
+}
+
+func TestDeclarativeGroups(t *testing.T) {
+	groups := container.BuildGroups{{Builds: []*container.Build{{
+		App:       "api",
+		BuildType: container.GoLang,
+		Image:     "api",
+		Folder:    "services/api",
+		Custom:    map[string][]string{"tags": {"a", "b"}},
+	}}}}
+
+	args := declarativeGroups(groups)
+	require.Len(t, args, 1)
+	arg := args[0].Args[0]
+	assert.Equal(t, "api", arg.Application)
+	assert.Equal(t, protos2.BuildType_GoLang, arg.BuildType)
+	assert.Equal(t, "services/api", arg.Folder)
+	assert.Equal(t, []any{"a", "b"}, arg.Properties["tags"].AsSlice())
+}
@@ -9,9 +9,12 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"sync"
 	"time"
 
+	"github.com/containifyci/engine-ci/pkg/kv"
 	"github.com/containifyci/engine-ci/pkg/logger"
+	"github.com/containifyci/engine-ci/pkg/svc"
 
 	"github.com/spf13/cobra"
 )
@@ -24,9 +27,11 @@ type rootCmdArgs struct {
 	MemProfile     string
 	Progress       string
 	Target         string
+	AffectedSince  string
 	PProfPort      int
 	Auto           bool
 	PProfHTTP      bool
+	PersistKV      bool
 	Verbose        bool
 }
 
@@ -41,6 +46,21 @@ const skipRootHooks = "skipRootHooks"
 
 var RootArgs = &rootCmdArgs{}
 
+var flushOnce sync.Once
+
+// flushLogs flushes the log output once, the tui restores the terminal and
+// shows the failures.
+func flushLogs() {
+	flushOnce.Do(logger.Shutdown)
+}
+
+// exit flushes the log output before the process exits, the post run hook
+// that flushes it otherwise is skipped by os.Exit.
+func exit(code int) {
+	flushLogs()
+	os.Exit(code)
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "engine-ci",
@@ -103,7 +123,7 @@ to quickly create a Cobra application.`,
 			return nil
 		}
 		slog.Info("Flushing logs")
-		logger.GetLogAggregator().Flush()
+		flushLogs()
 
 		// Stop CPU profiling if it was started
 		if RootArgs.CPUProfile != "" {
@@ -147,7 +167,12 @@ to quickly create a Cobra application.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil {
+		// the post run hook is skipped on errors
+		flushLogs()
+	}
+	return err
 }
 
 func init() {
@@ -162,7 +187,9 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&RootArgs.Verbose, "verbose", "v", false, "Enable verbose logging")
 	rootCmd.PersistentFlags().BoolVarP(&RootArgs.Auto, "auto", "a", false, "The build target to run")
 	rootCmd.PersistentFlags().StringVarP(&RootArgs.Target, "target", "t", "all", "The build target to run")
-	rootCmd.PersistentFlags().StringVar(&RootArgs.Progress, "progress", "plain", "The progress logging format to use. Options are: progress, plain")
+	rootCmd.PersistentFlags().BoolVar(&RootArgs.PersistKV, "persist-kv", false, "Persist the key value store in "+kv.DefaultFile+" to share values across runs, the file is only readable by the owner")
+	rootCmd.PersistentFlags().StringVar(&RootArgs.Progress, "progress", "plain", "The progress logging format to use. Options are: progress, plain, json, tui")
+	rootCmd.PersistentFlags().StringVar(&RootArgs.AffectedSince, "affected-since", "", "Only run the builds affected by the changes since the git ref and the builds depending on them")
 
 	// Profiling flags
 	rootCmd.PersistentFlags().StringVar(&RootArgs.CPUProfile, "cpuprofile", "", "write cpu profile to file")
@@ -186,6 +213,7 @@ func SetVersionInfo(version, commit, date, repo string) string {
 		Date:    date,
 		Repo:    repo,
 	}
+	svc.SetVersion(version)
 	return rootCmd.Version
 }
 
@@ -0,0 +1,104 @@
+package autodiscovery
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+// AffectedBuilds splits the build groups into the builds affected by the changed
+// files and the skipped builds. A changed file affects the builds of the innermost
+// build folder containing it and all builds depending on them, directly or
+// transitively. Groups without affected builds are dropped.
+func AffectedBuilds(groups container.BuildGroups, changed []string) (container.BuildGroups, []*container.Build) {
+	var builds []*container.Build
+	for _, group := range groups {
+		builds = append(builds, group.Builds...)
+	}
+
+	projects := make([]Project, len(builds))
+	folders := make([]string, len(builds))
+	for i, b := range builds {
+		projects[i] = buildProject(b)
+		folders[i] = absDir(projects[i].ModulePath)
+	}
+
+	affected := map[*container.Build]bool{}
+	for _, file := range changed {
+		path := absDir(file)
+		owner := ""
+		for _, folder := range folders {
+			if inFolder(folder, path) && len(folder) > len(owner) {
+				owner = folder
+			}
+		}
+		for i, folder := range folders {
+			if owner != "" && folder == owner {
+				affected[builds[i]] = true
+			}
+		}
+	}
+
+	// mark the dependents until no further build is affected
+	graph := dependencyGraph(projects)
+	for grew := true; grew; {
+		grew = false
+		for i, dependencies := range graph {
+			if affected[builds[i]] {
+				continue
+			}
+			for _, dep := range dependencies {
+				if affected[builds[dep]] {
+					affected[builds[i]] = true
+					grew = true
+					break
+				}
+			}
+		}
+	}
+
+	var result container.BuildGroups
+	var skipped []*container.Build
+	for _, group := range groups {
+		kept := &container.BuildGroup{}
+		for _, b := range group.Builds {
+			if affected[b] {
+				kept.Builds = append(kept.Builds, b)
+			} else {
+				skipped = append(skipped, b)
+			}
+		}
+		if len(kept.Builds) > 0 {
+			result = append(result, kept)
+		}
+	}
+	return result, skipped
+}
+
+// buildProject describes the build as project to resolve its local dependencies
+func buildProject(b *container.Build) Project {
+	folder := b.Folder
+	if folder == "" {
+		folder = "."
+	}
+	buildType, ok := protos2.BuildType_value[string(b.BuildType)]
+	if !ok {
+		buildType = int32(protos2.BuildType_Generic)
+	}
+	return Project{
+		AppName:    b.App,
+		ModulePath: folder,
+		BuildType:  protos2.BuildType(buildType),
+	}
+}
+
+// inFolder reports whether the path is the folder or inside of it
+func inFolder(folder, path string) bool {
+	rel, err := filepath.Rel(folder, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,73 @@
+package autodiscovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffectedBuilds(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"lib/go.mod":       "module example.com/lib\n\ngo 1.22\n",
+		"lib/lib.go":       "package lib",
+		"api/go.mod":       "module example.com/api\n\ngo 1.22\n\nrequire example.com/lib v0.0.0\n",
+		"api/main.go":      "package main",
+		"cli/go.mod":       "module example.com/cli\n\ngo 1.22\n\nrequire example.com/api v0.0.0\n",
+		"web/Dockerfile":   "FROM scratch",
+		"docs/README.md":   "# docs",
+		"ops/Dockerfile":   "FROM scratch",
+		"ops/deploy.yaml":  "",
+		"ops/web/run.yaml": "",
+	})
+
+	lib := &container.Build{App: "lib", BuildType: container.GoLang, Folder: filepath.Join(root, "lib")}
+	api := &container.Build{App: "api", BuildType: container.GoLang, Folder: filepath.Join(root, "api")}
+	cli := &container.Build{App: "cli", BuildType: container.GoLang, Folder: filepath.Join(root, "cli")}
+	web := &container.Build{App: "web", BuildType: container.Generic, Folder: filepath.Join(root, "web")}
+	ops := &container.Build{App: "ops", BuildType: container.Generic, Folder: root}
+	groups := container.BuildGroups{
+		{Builds: []*container.Build{lib, web}},
+		{Builds: []*container.Build{api}},
+		{Builds: []*container.Build{cli, ops}},
+	}
+
+	tests := []struct {
+		name     string
+		changed  []string
+		affected container.BuildGroups
+		skipped  []*container.Build
+	}{
+		{
+			name:    "dependency changed",
+			changed: []string{filepath.Join(root, "lib/lib.go")},
+			affected: container.BuildGroups{
+				{Builds: []*container.Build{lib}},
+				{Builds: []*container.Build{api}},
+				{Builds: []*container.Build{cli}},
+			},
+			skipped: []*container.Build{web, ops},
+		},
+		{
+			name:     "innermost folder owns the file",
+			changed:  []string{filepath.Join(root, "web/Dockerfile"), filepath.Join(root, "docs/README.md")},
+			affected: container.BuildGroups{{Builds: []*container.Build{web}}, {Builds: []*container.Build{ops}}},
+			skipped:  []*container.Build{lib, api, cli},
+		},
+		{
+			name:    "files outside of the builds",
+			changed: []string{filepath.Join(filepath.Dir(root), "other.txt")},
+			skipped: []*container.Build{lib, web, api, cli, ops},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			affected, skipped := AffectedBuilds(groups, tt.changed)
+			assert.Equal(t, tt.affected, affected)
+			assert.Equal(t, tt.skipped, skipped)
+		})
+	}
+}
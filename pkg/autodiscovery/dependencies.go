@@ -0,0 +1,394 @@
+package autodiscovery
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containifyci/engine-ci/pkg/filesystem"
+	"github.com/containifyci/engine-ci/protos2"
+	"golang.org/x/mod/modfile"
+)
+
+// ErrDependencyCycle is returned when the discovered projects depend on each other
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// projectReferences are the names a project is known by and the local
+// projects it depends on, either by path or by name
+type projectReferences struct {
+	provides []string
+	paths    []string
+	names    []string
+}
+
+// pomModel holds the pom.xml fields used to resolve Maven dependencies
+type pomModel struct {
+	ArtifactID string `xml:"artifactId"`
+	Parent     struct {
+		ArtifactID string `xml:"artifactId"`
+	} `xml:"parent"`
+	Modules      []string `xml:"modules>module"`
+	Dependencies []struct {
+		ArtifactID string `xml:"artifactId"`
+	} `xml:"dependencies>dependency"`
+}
+
+// pyprojectDependencies holds the pyproject.toml fields that can point to local packages
+type pyprojectDependencies struct {
+	Project struct {
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies map[string]any `toml:"dependencies"`
+			Group        map[string]struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+		Uv struct {
+			Sources map[string]struct {
+				Path string `toml:"path"`
+			} `toml:"sources"`
+		} `toml:"uv"`
+	} `toml:"tool"`
+}
+
+// OrderProjects sorts the projects topologically by their local dependencies.
+// The projects of a stage only depend on projects of the previous stages and
+// keep their discovery order.
+func OrderProjects(projects []Project) ([][]Project, error) {
+	graph := dependencyGraph(projects)
+
+	remaining := make(map[int]bool, len(projects))
+	for i := range projects {
+		remaining[i] = true
+	}
+
+	var stages [][]Project
+	for len(remaining) > 0 {
+		var ready []int
+		for i := range projects {
+			if remaining[i] && dependenciesBuilt(graph[i], remaining) {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			return nil, fmt.Errorf("%w between projects: %s", ErrDependencyCycle, describeCycle(projects, graph, remaining))
+		}
+
+		stage := make([]Project, 0, len(ready))
+		for _, i := range ready {
+			delete(remaining, i)
+			stage = append(stage, projects[i])
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
+
+func dependenciesBuilt(dependencies []int, remaining map[int]bool) bool {
+	for _, dep := range dependencies {
+		if remaining[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// describeCycle follows the dependencies of the unbuilt projects until one repeats
+func describeCycle(projects []Project, graph [][]int, remaining map[int]bool) string {
+	start := -1
+	for i := range projects {
+		if remaining[i] {
+			start = i
+			break
+		}
+	}
+
+	visited := map[int]int{}
+	var path []int
+	for current := start; ; {
+		if pos, ok := visited[current]; ok {
+			path = append(path[pos:], current)
+			break
+		}
+		visited[current] = len(path)
+		path = append(path, current)
+		for _, dep := range graph[current] {
+			if remaining[dep] {
+				current = dep
+				break
+			}
+		}
+	}
+
+	names := make([]string, 0, len(path))
+	for _, i := range path {
+		names = append(names, fmt.Sprintf("%s (%s)", projects[i].AppName, projects[i].ModulePath))
+	}
+	return strings.Join(names, " -> ")
+}
+
+// dependencyGraph returns for each project the indexes of the projects it depends on
+func dependencyGraph(projects []Project) [][]int {
+	// several projects can share a folder or a module, e.g. the commands of a Go module
+	refs := make([]projectReferences, len(projects))
+	byPath := map[string][]int{}
+	byName := map[string][]int{}
+	for i, p := range projects {
+		refs[i] = resolveReferences(p)
+		byPath[absDir(p.ModulePath)] = append(byPath[absDir(p.ModulePath)], i)
+		for _, name := range refs[i].provides {
+			byName[name] = append(byName[name], i)
+		}
+	}
+
+	graph := make([][]int, len(projects))
+	for i, p := range projects {
+		seen := map[int]bool{i: true}
+		add := func(dependencies []int) {
+			for _, j := range dependencies {
+				if !seen[j] {
+					seen[j] = true
+					graph[i] = append(graph[i], j)
+				}
+			}
+		}
+		for _, path := range refs[i].paths {
+			add(byPath[absDir(path)])
+		}
+		for _, name := range refs[i].names {
+			add(byName[name])
+		}
+		for _, j := range graph[i] {
+			slog.Debug("Discovered project dependency", "project", p.AppName, "dependsOn", projects[j].AppName)
+		}
+	}
+	return graph
+}
+
+func absDir(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// resolveReferences reads the local dependencies from the project manifests
+func resolveReferences(p Project) projectReferences {
+	var (
+		refs projectReferences
+		err  error
+	)
+	switch p.BuildType {
+	case protos2.BuildType_GoLang:
+		refs, err = goReferences(p)
+	case protos2.BuildType_Maven:
+		refs, err = mavenReferences(p)
+	case protos2.BuildType_Python:
+		refs, err = pythonReferences(p)
+	}
+	if err != nil {
+		slog.Warn("Failed to resolve project dependencies", "project", p.AppName, "path", p.ModulePath, "error", err)
+	}
+	return refs
+}
+
+// goReferences uses the requires of other local modules and the replace directives to local folders
+func goReferences(p Project) (projectReferences, error) {
+	refs := projectReferences{}
+	if p.ModuleName != "" {
+		refs.provides = []string{"go:" + p.ModuleName}
+	}
+
+	file := filepath.Join(p.ModulePath, "go.mod")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return refs, nil
+		}
+		return refs, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	f, err := modfile.Parse(file, data, nil)
+	if err != nil {
+		return refs, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	if p.ModuleName == "" && f.Module != nil {
+		refs.provides = []string{"go:" + f.Module.Mod.Path}
+	}
+	for _, req := range f.Require {
+		refs.names = append(refs.names, "go:"+req.Mod.Path)
+	}
+	for _, rep := range f.Replace {
+		if modfile.IsDirectoryPath(rep.New.Path) {
+			refs.paths = append(refs.paths, resolvePath(p.ModulePath, rep.New.Path))
+		}
+	}
+	return refs, nil
+}
+
+// mavenReferences uses the parent and the dependencies of the pom and of its modules,
+// artifacts built by the modules of the same reactor are no dependencies
+func mavenReferences(p Project) (projectReferences, error) {
+	refs := projectReferences{}
+	file := filepath.Join(p.ModulePath, "pom.xml")
+	if !filesystem.FileExists(file) {
+		// Gradle projects are not resolved
+		return refs, nil
+	}
+
+	artifacts := map[string]bool{}
+	visited := map[string]bool{}
+	var dependencies []string
+	var collect func(dir string) error
+	collect = func(dir string) error {
+		if visited[absDir(dir)] {
+			return nil
+		}
+		visited[absDir(dir)] = true
+		pom, err := parsePomModel(filepath.Join(dir, "pom.xml"))
+		if err != nil {
+			return err
+		}
+		artifacts[pom.ArtifactID] = true
+		if pom.Parent.ArtifactID != "" {
+			dependencies = append(dependencies, pom.Parent.ArtifactID)
+		}
+		for _, dep := range pom.Dependencies {
+			dependencies = append(dependencies, dep.ArtifactID)
+		}
+		for _, module := range pom.Modules {
+			if err := collect(filepath.Join(dir, module)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(p.ModulePath); err != nil {
+		return refs, err
+	}
+
+	for artifact := range artifacts {
+		refs.provides = append(refs.provides, "maven:"+artifact)
+	}
+	for _, dep := range dependencies {
+		if !artifacts[dep] {
+			refs.names = append(refs.names, "maven:"+dep)
+		}
+	}
+	return refs, nil
+}
+
+func parsePomModel(file string) (*pomModel, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pom.xml: %w", err)
+	}
+	var pom pomModel
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return &pom, nil
+}
+
+// pythonReferences uses the path dependencies of pyproject.toml (PEP 508 file URLs,
+// Poetry and uv) and requirements.txt
+func pythonReferences(p Project) (projectReferences, error) {
+	refs := projectReferences{}
+
+	pyproject := filepath.Join(p.ModulePath, "pyproject.toml")
+	if filesystem.FileExists(pyproject) {
+		var deps pyprojectDependencies
+		if _, err := toml.DecodeFile(pyproject, &deps); err != nil {
+			return refs, fmt.Errorf("failed to parse pyproject.toml: %w", err)
+		}
+		for _, dep := range deps.Project.Dependencies {
+			if _, url, ok := strings.Cut(dep, "@"); ok {
+				if path, ok := fileURLPath(strings.TrimSpace(url)); ok {
+					refs.paths = append(refs.paths, resolvePath(p.ModulePath, path))
+				}
+			}
+		}
+		poetry := []map[string]any{deps.Tool.Poetry.Dependencies}
+		for _, group := range deps.Tool.Poetry.Group {
+			poetry = append(poetry, group.Dependencies)
+		}
+		for _, dependencies := range poetry {
+			for _, dep := range dependencies {
+				if table, ok := dep.(map[string]any); ok {
+					if path, ok := table["path"].(string); ok {
+						refs.paths = append(refs.paths, resolvePath(p.ModulePath, path))
+					}
+				}
+			}
+		}
+		for _, source := range deps.Tool.Uv.Sources {
+			if source.Path != "" {
+				refs.paths = append(refs.paths, resolvePath(p.ModulePath, source.Path))
+			}
+		}
+	}
+
+	requirements := filepath.Join(p.ModulePath, "requirements.txt")
+	if filesystem.FileExists(requirements) {
+		paths, err := requirementPaths(requirements)
+		if err != nil {
+			return refs, err
+		}
+		for _, path := range paths {
+			refs.paths = append(refs.paths, resolvePath(p.ModulePath, path))
+		}
+	}
+	return refs, nil
+}
+
+// requirementPaths returns the local folders installed by requirements.txt
+func requirementPaths(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open requirements.txt: %w", err)
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "--editable"), "-e"))
+		if _, url, ok := strings.Cut(line, "@"); ok {
+			line = strings.TrimSpace(url)
+		}
+		if path, ok := fileURLPath(line); ok {
+			paths = append(paths, path)
+		} else if strings.HasPrefix(line, "./") || strings.HasPrefix(line, "../") || strings.HasPrefix(line, "/") {
+			paths = append(paths, line)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// fileURLPath returns the path of file:../lib and file:///abs/lib URLs
+func fileURLPath(url string) (string, bool) {
+	path, ok := strings.CutPrefix(url, "file:")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimPrefix(path, "//"), true
+}
+
+// resolvePath resolves a dependency path relative to the project folder
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
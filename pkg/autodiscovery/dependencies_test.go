@@ -0,0 +1,159 @@
+package autodiscovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appNames(stages [][]Project) [][]string {
+	names := [][]string{}
+	for _, stage := range stages {
+		stageNames := []string{}
+		for _, p := range stage {
+			stageNames = append(stageNames, p.AppName)
+		}
+		names = append(names, stageNames)
+	}
+	return names
+}
+
+func TestOrderProjectsGo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":         "module github.com/acme/engine\n\ngo 1.24\n\nrequire (\n\tgithub.com/acme/engine/client v0.1.0\n\tgithub.com/acme/engine/protos2 v0.1.0\n\tgithub.com/stretchr/testify v1.10.0\n)\n",
+		"client/go.mod":  "module github.com/acme/engine/client\n\ngo 1.24\n\nrequire example.com/protos v0.1.0\n\nreplace example.com/protos => ../protos2\n",
+		"protos2/go.mod": "module github.com/acme/engine/protos2\n\ngo 1.24\n",
+		"tools/go.mod":   "module github.com/acme/tools\n\ngo 1.24\n",
+	})
+
+	projects := []Project{
+		{AppName: "engine", ModulePath: root, ModuleName: "github.com/acme/engine", BuildType: protos2.BuildType_GoLang},
+		{AppName: "client", ModulePath: filepath.Join(root, "client"), ModuleName: "github.com/acme/engine/client", BuildType: protos2.BuildType_GoLang},
+		{AppName: "protos2", ModulePath: filepath.Join(root, "protos2"), ModuleName: "github.com/acme/engine/protos2", BuildType: protos2.BuildType_GoLang},
+		{AppName: "tools", ModulePath: filepath.Join(root, "tools"), ModuleName: "github.com/acme/tools", BuildType: protos2.BuildType_GoLang},
+	}
+
+	stages, err := OrderProjects(projects)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"protos2", "tools"}, {"client"}, {"engine"}}, appNames(stages))
+}
+
+func TestOrderProjectsMaven(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"platform/pom.xml":      "<project><artifactId>platform</artifactId><modules><module>core</module></modules></project>",
+		"platform/core/pom.xml": "<project><parent><artifactId>platform</artifactId></parent><artifactId>core</artifactId></project>",
+		"service/pom.xml": `<project>
+  <artifactId>service</artifactId>
+  <dependencies>
+    <dependency><groupId>com.acme</groupId><artifactId>core</artifactId></dependency>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId></dependency>
+  </dependencies>
+</project>`,
+	})
+
+	projects := []Project{
+		{AppName: "service", ModulePath: filepath.Join(root, "service"), BuildType: protos2.BuildType_Maven},
+		{AppName: "platform", ModulePath: filepath.Join(root, "platform"), BuildType: protos2.BuildType_Maven},
+	}
+
+	stages, err := OrderProjects(projects)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"platform"}, {"service"}}, appNames(stages))
+}
+
+func TestOrderProjectsPython(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"common/pyproject.toml":   "[project]\nname = \"common\"\n",
+		"models/pyproject.toml":   "[tool.uv.sources]\ncommon = { path = \"../common\", editable = true }\n",
+		"api/pyproject.toml":      "[tool.poetry.dependencies]\npython = \"^3.12\"\nmodels = { path = \"../models\", develop = true }\n",
+		"worker/requirements.txt": "requests>=2.31\n-e ../common\n",
+		"cli/pyproject.toml":      "[project]\ndependencies = [\"api @ file:../api\", \"click\"]\n",
+	})
+
+	projects := []Project{}
+	for _, name := range []string{"cli", "api", "worker", "models", "common"} {
+		projects = append(projects, Project{AppName: name, ModulePath: filepath.Join(root, name), BuildType: protos2.BuildType_Python})
+	}
+
+	stages, err := OrderProjects(projects)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"common"}, {"worker", "models"}, {"api"}, {"cli"}}, appNames(stages))
+}
+
+func TestOrderProjectsCycle(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/go.mod": "module example.com/a\n\nrequire example.com/b v0.0.0\n\nreplace example.com/b => ../b\n",
+		"b/go.mod": "module example.com/b\n\nrequire example.com/a v0.0.0\n",
+		"c/go.mod": "module example.com/c\n",
+	})
+
+	projects := []Project{
+		{AppName: "c", ModulePath: filepath.Join(root, "c"), ModuleName: "example.com/c", BuildType: protos2.BuildType_GoLang},
+		{AppName: "a", ModulePath: filepath.Join(root, "a"), ModuleName: "example.com/a", BuildType: protos2.BuildType_GoLang},
+		{AppName: "b", ModulePath: filepath.Join(root, "b"), ModuleName: "example.com/b", BuildType: protos2.BuildType_GoLang},
+	}
+
+	_, err := OrderProjects(projects)
+	require.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "a ("+filepath.Join(root, "a")+") -> b ("+filepath.Join(root, "b")+") -> a")
+}
+
+func TestGenerateBuildGroupsFromCollectionCycle(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/go.mod": "module example.com/a\n\nrequire example.com/a/sub v0.0.0\n",
+		"b/go.mod": "module example.com/a/sub\n\nrequire example.com/a v0.0.0\n",
+	})
+
+	collection := &ProjectCollection{GoProjects: []Project{
+		{AppName: "a", ModulePath: filepath.Join(root, "a"), ModuleName: "example.com/a", BuildType: protos2.BuildType_GoLang},
+		{AppName: "sub", ModulePath: filepath.Join(root, "b"), ModuleName: "example.com/a/sub", BuildType: protos2.BuildType_GoLang},
+	}}
+
+	_, err := GenerateBuildGroupsFromCollection(collection)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+func TestGenerateBuildGroupsFromCollectionIndependent(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"api/go.mod":         "module example.com/api\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n",
+		"lib/go.mod":         "module example.com/lib\n",
+		"worker/go.mod":      "module example.com/worker\n",
+		"web/pyproject.toml": "[project]\nname = \"web\"\n",
+		"app/pom.xml":        "<project><artifactId>app</artifactId></project>",
+	})
+
+	// the commands of the api module share its folder
+	collection := &ProjectCollection{
+		GoProjects: []Project{
+			{AppName: "api", ModulePath: filepath.Join(root, "api"), ModuleName: "example.com/api", BuildType: protos2.BuildType_GoLang},
+			{AppName: "api-admin", ModulePath: filepath.Join(root, "api"), ModuleName: "example.com/api", BuildType: protos2.BuildType_GoLang},
+			{AppName: "lib", ModulePath: filepath.Join(root, "lib"), ModuleName: "example.com/lib", BuildType: protos2.BuildType_GoLang},
+			{AppName: "worker", ModulePath: filepath.Join(root, "worker"), ModuleName: "example.com/worker", BuildType: protos2.BuildType_GoLang},
+		},
+		PythonProjects: []Project{{AppName: "web", ModulePath: filepath.Join(root, "web"), BuildType: protos2.BuildType_Python}},
+		JavaProjects:   []Project{{AppName: "app", ModulePath: filepath.Join(root, "app"), BuildType: protos2.BuildType_Maven}},
+	}
+
+	groups, err := GenerateBuildGroupsFromCollection(collection)
+	require.NoError(t, err)
+
+	names := [][]string{}
+	for _, group := range groups {
+		groupNames := []string{}
+		for _, build := range group.Builds {
+			groupNames = append(groupNames, build.App)
+		}
+		names = append(names, groupNames)
+	}
+	// only the api depends on another project, the others build in parallel
+	assert.Equal(t, [][]string{{"lib", "worker", "web", "app"}, {"api", "api-admin"}}, names)
+}
@@ -12,14 +12,23 @@ type LanguageFilter struct {
 	Go     bool
 	Python bool
 	Java   bool
+	Node   bool
+	Rust   bool
+	Zig    bool
+	Docker bool
 }
 
-// AllLanguages returns a filter that includes all supported languages
+// AllLanguages returns a filter that includes all languages the engine can build.
+// Node and Rust projects have no build step yet and are only discovered on request.
 func AllLanguages() LanguageFilter {
 	return LanguageFilter{
 		Go:     true,
 		Python: true,
 		Java:   true,
+		Node:   false,
+		Rust:   false,
+		Zig:    true,
+		Docker: true,
 	}
 }
 
@@ -29,6 +38,10 @@ func OnlyGo() LanguageFilter {
 		Go:     true,
 		Python: false,
 		Java:   false,
+		Node:   false,
+		Rust:   false,
+		Zig:    false,
+		Docker: false,
 	}
 }
 
@@ -37,6 +50,8 @@ type DiscoveryOptions struct {
 	RootDir   string
 	Languages LanguageFilter
 	Verbose   bool
+	// Images are the images built from Dockerfiles of the repository by the build steps
+	Images []KnownImage
 }
 
 // DiscoverAllProjects scans for projects in all supported languages
@@ -47,51 +62,39 @@ func DiscoverAllProjects(options DiscoveryOptions) (*ProjectCollection, error) {
 		slog.Info("Starting multi-language project discovery", "rootDir", options.RootDir)
 	}
 
-	// Discover Go projects
-	if options.Languages.Go {
-		if options.Verbose {
-			slog.Info("Discovering Go projects...")
-		}
-		goProjects, err := DiscoverGoProjects(options.RootDir)
-		if err != nil {
-			slog.Warn("Failed to discover Go projects", "error", err)
-		} else {
-			collection.GoProjects = goProjects
-			if options.Verbose {
-				slog.Info("Discovered Go projects", "count", len(goProjects))
-			}
+	discoverers := []struct {
+		enabled  bool
+		name     string
+		discover func() ([]Project, error)
+		projects *[]Project
+	}{
+		{options.Languages.Go, "Go projects", func() ([]Project, error) { return DiscoverGoProjects(options.RootDir) }, &collection.GoProjects},
+		{options.Languages.Python, "Python projects", func() ([]Project, error) { return DiscoverPythonProjects(options.RootDir) }, &collection.PythonProjects},
+		{options.Languages.Java, "Java projects", func() ([]Project, error) { return DiscoverJavaProjects(options.RootDir) }, &collection.JavaProjects},
+		{options.Languages.Node, "Node.js projects", func() ([]Project, error) { return DiscoverNodeProjects(options.RootDir) }, &collection.NodeProjects},
+		{options.Languages.Rust, "Rust projects", func() ([]Project, error) { return DiscoverRustProjects(options.RootDir) }, &collection.RustProjects},
+		{options.Languages.Zig, "Zig projects", func() ([]Project, error) { return DiscoverZigProjects(options.RootDir) }, &collection.ZigProjects},
+		// standalone Dockerfiles come last to skip the ones owned by the projects above
+		{options.Languages.Docker, "Dockerfiles", func() ([]Project, error) {
+			return DiscoverDockerfiles(options.RootDir, collection.AllProjects(), options.Images...)
+		}, &collection.DockerProjects},
+	}
+
+	for _, d := range discoverers {
+		if !d.enabled {
+			continue
 		}
-	}
-
-	// Discover Python projects
-	if options.Languages.Python {
 		if options.Verbose {
-			slog.Info("Discovering Python projects...")
+			slog.Info("Discovering " + d.name + "...")
 		}
-		pythonProjects, err := DiscoverPythonProjects(options.RootDir)
+		projects, err := d.discover()
 		if err != nil {
-			slog.Warn("Failed to discover Python projects", "error", err)
-		} else {
-			collection.PythonProjects = pythonProjects
-			if options.Verbose {
-				slog.Info("Discovered Python projects", "count", len(pythonProjects))
-			}
+			slog.Warn("Failed to discover "+d.name, "error", err)
+			continue
 		}
-	}
-
-	// Discover Java projects
-	if options.Languages.Java {
+		*d.projects = projects
 		if options.Verbose {
-			slog.Info("Discovering Java projects...")
-		}
-		javaProjects, err := DiscoverJavaProjects(options.RootDir)
-		if err != nil {
-			slog.Warn("Failed to discover Java projects", "error", err)
-		} else {
-			collection.JavaProjects = javaProjects
-			if options.Verbose {
-				slog.Info("Discovered Java projects", "count", len(javaProjects))
-			}
+			slog.Info("Discovered "+d.name, "count", len(projects))
 		}
 	}
 
@@ -131,7 +134,11 @@ func logDiscoverySummary(collection *ProjectCollection) {
 		"total", totalProjects,
 		"go", counts[ProjectTypeGo],
 		"python", counts[ProjectTypePython],
-		"java", counts[ProjectTypeJava])
+		"java", counts[ProjectTypeJava],
+		"node", counts[ProjectTypeNode],
+		"rust", counts[ProjectTypeRust],
+		"zig", counts[ProjectTypeZig],
+		"docker", counts[ProjectTypeDocker])
 
 	// Log details about discovered projects
 	for _, project := range collection.AllProjects() {
@@ -143,33 +150,34 @@ func logDiscoverySummary(collection *ProjectCollection) {
 	}
 }
 
-// GenerateBuildGroupsFromCollection creates container.BuildGroups from a project collection
-func GenerateBuildGroupsFromCollection(collection *ProjectCollection) container.BuildGroups {
+// GenerateBuildGroupsFromCollection creates container.BuildGroups from a project collection.
+// Projects are grouped by their local dependencies, every group only depends on
+// the groups before it and its builds run concurrently.
+func GenerateBuildGroupsFromCollection(collection *ProjectCollection) (container.BuildGroups, error) {
 	var groups container.BuildGroups
 
-	// Convert all projects to builds
-	allProjects := collection.AllProjects()
-
-	// TODO: Implement dependency-aware build ordering
-	// For now, create individual build groups for each project
-	for _, project := range allProjects {
-		slog.Info("Generating build for project",
-			"name", project.AppName,
-			"type", project.BuildType,
-			"isService", project.IsService)
+	stages, err := OrderProjects(collection.AllProjects())
+	if err != nil {
+		return nil, fmt.Errorf("failed to order projects: %w", err)
+	}
 
-		build := project.ToBuild()
-		build.Defaults()
+	for _, stage := range stages {
+		group := &container.BuildGroup{}
+		for _, project := range stage {
+			slog.Info("Generating build for project",
+				"name", project.AppName,
+				"type", project.BuildType,
+				"isService", project.IsService,
+				"group", len(groups))
 
-		// Create a build group with this single build
-		group := &container.BuildGroup{
-			Builds: []*container.Build{&build},
+			build := project.ToBuild()
+			build.Defaults()
+			group.Builds = append(group.Builds, &build)
 		}
-
 		groups = append(groups, group)
 	}
 
-	return groups
+	return groups, nil
 }
 
 // DiscoverAndGenerateBuildGroupsMultiLang is the multi-language equivalent of the Go-only function
@@ -183,7 +191,7 @@ func DiscoverAndGenerateBuildGroupsMultiLang(rootDir string) (container.BuildGro
 		return nil, fmt.Errorf("no projects found in %s", rootDir)
 	}
 
-	return GenerateBuildGroupsFromCollection(collection), nil
+	return GenerateBuildGroupsFromCollection(collection)
 }
 
 // DiscoverAndGenerateBuildGroupsWithFilter discovers projects with language filtering
@@ -197,7 +205,7 @@ func DiscoverAndGenerateBuildGroupsWithFilter(rootDir string, filter LanguageFil
 		return nil, fmt.Errorf("no projects found in %s", rootDir)
 	}
 
-	return GenerateBuildGroupsFromCollection(collection), nil
+	return GenerateBuildGroupsFromCollection(collection)
 }
 
 // Legacy Functions for Backward Compatibility
@@ -214,5 +222,9 @@ func ConvertGoProjectsToCollection(goProjects []Project) *ProjectCollection {
 		GoProjects:     goProjects,
 		PythonProjects: []Project{},
 		JavaProjects:   []Project{},
+		NodeProjects:   []Project{},
+		RustProjects:   []Project{},
+		ZigProjects:    []Project{},
+		DockerProjects: []Project{},
 	}
 }
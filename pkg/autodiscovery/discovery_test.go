@@ -56,6 +56,10 @@ func TestLanguageFilter(t *testing.T) {
 			assert.Equal(t, tt.expected[ProjectTypeJava], tt.filter.Java)
 		})
 	}
+
+	// node and rust have no build step and are opt-in
+	assert.False(t, AllLanguages().Node)
+	assert.False(t, AllLanguages().Rust)
 }
 
 func TestProjectCollection(t *testing.T) {
@@ -122,20 +126,16 @@ func TestGenerateBuildGroupsFromCollection(t *testing.T) {
 		JavaProjects:   []Project{},
 	}
 
-	buildGroups := GenerateBuildGroupsFromCollection(collection)
-
-	// Should have 2 build groups (one per project)
-	assert.Len(t, buildGroups, 2)
+	buildGroups, err := GenerateBuildGroupsFromCollection(collection)
+	require.NoError(t, err)
 
-	// Each build group should have 1 build
-	for i, group := range buildGroups {
-		assert.Len(t, group.Builds, 1, "Build group %d should have 1 build", i)
-	}
+	// Independent projects are built concurrently in a single group
+	require.Len(t, buildGroups, 1)
+	assert.Len(t, buildGroups[0].Builds, 2)
 
 	// Verify build types are correct
 	buildTypes := make(map[string]bool)
-	for _, group := range buildGroups {
-		build := group.Builds[0]
+	for _, build := range buildGroups[0].Builds {
 		switch build.BuildType {
 		case "GoLang":
 			buildTypes["go"] = true
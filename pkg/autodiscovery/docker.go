@@ -0,0 +1,156 @@
+package autodiscovery
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+// dockerSkipDirs are dependency, output and tool folders that never contain images of the repository
+var dockerSkipDirs = map[string]bool{
+	".git":          true,
+	".containifyci": true,
+	"node_modules":  true,
+	"vendor":        true,
+	"target":        true,
+	".zig-cache":    true,
+	"zig-out":       true,
+	".venv":         true,
+}
+
+// invalidImageChars are replaced in the derived image names
+var invalidImageChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// KnownImage is an image built from a Dockerfile of the repository by one of the
+// build steps, e.g. the containifyci/* intermediate images of engine-ci.
+type KnownImage struct {
+	// Dockerfile is the path relative to the discovery root, e.g. pkg/zig/Dockerfile.zig
+	Dockerfile string
+	// Name is the image name without registry and tag, e.g. zig-0.14
+	Name string
+}
+
+// DiscoverDockerfiles scans the given root directory recursively for Dockerfiles
+// (Dockerfile, Dockerfile.worker, Dockerfile_go, worker.Dockerfile). Dockerfiles
+// inside the folder of one of the owner projects belong to that project and are
+// skipped, a project in the root directory only owns the Dockerfiles next to it.
+// Dockerfiles of the known images keep the image name and use the checksum tag.
+func DiscoverDockerfiles(rootDir string, owners []Project, known ...KnownImage) ([]Project, error) {
+	root := absDir(rootDir)
+	var owned []string
+	for _, owner := range owners {
+		owned = append(owned, absDir(owner.ModulePath))
+	}
+	images := map[string]string{}
+	for _, image := range known {
+		images[filepath.ToSlash(filepath.Clean(image.Dockerfile))] = image.Name
+	}
+
+	var projects []Project
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != rootDir && dockerSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		variant, ok := dockerfileVariant(d.Name())
+		if !ok {
+			return nil
+		}
+		dir := filepath.Dir(path)
+		if ownedDir(root, owned, absDir(dir)) {
+			slog.Debug("Skipping Dockerfile owned by a project", "path", path)
+			return nil
+		}
+
+		project, err := analyzeDockerfile(dir, path, variant)
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(rootDir, path); err == nil {
+			if name, ok := images[filepath.ToSlash(rel)]; ok {
+				project.AppName = name
+				project.Properties["image_tag"] = []string{"checksum"}
+			}
+		}
+		slog.Info("Discovered Dockerfile", "name", project.AppName, "path", path)
+		projects = append(projects, project)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Dockerfiles: %w", err)
+	}
+
+	return projects, nil
+}
+
+// ownedDir reports whether the folder is inside one of the owner folders. The
+// root folder only owns itself, otherwise a module in the root directory would
+// own every Dockerfile of the repository.
+func ownedDir(root string, owners []string, dir string) bool {
+	for _, owner := range owners {
+		if owner == dir || (owner != root && inFolder(owner, dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerfileVariant returns the variant of the Dockerfile name,
+// e.g. worker for Dockerfile.worker and worker.Dockerfile
+func dockerfileVariant(name string) (string, bool) {
+	if strings.HasSuffix(name, ".dockerignore") {
+		return "", false
+	}
+	if variant, ok := strings.CutPrefix(name, "Dockerfile"); ok {
+		return strings.TrimLeft(variant, "._-"), true
+	}
+	if variant, ok := strings.CutSuffix(name, ".Dockerfile"); ok {
+		return variant, true
+	}
+	return "", false
+}
+
+// analyzeDockerfile derives the image name from the folder and the variant of the Dockerfile
+func analyzeDockerfile(dir, path, variant string) (Project, error) {
+	absPath, err := filepath.Abs(dir)
+	if err != nil {
+		return Project{}, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	return Project{
+		AppName:    dockerImageName(filepath.Base(absPath), variant),
+		ModulePath: dir,
+		MainFile:   path,
+		BuildType:  protos2.BuildType_Generic,
+		IsService:  true,
+		Properties: map[string][]string{
+			"dockerfile": {filepath.Base(path)},
+		},
+	}, nil
+}
+
+// dockerImageName combines the folder and the variant, pkg/zig/Dockerfile.zig is zig
+// and deploy/Dockerfile.worker is deploy-worker
+func dockerImageName(folder, variant string) string {
+	name := strings.ToLower(folder)
+	variant = strings.ToLower(variant)
+	if variant != "" {
+		if strings.HasPrefix(variant, name) {
+			name = variant
+		} else {
+			name = name + "-" + variant
+		}
+	}
+	return strings.Trim(invalidImageChars.ReplaceAllString(name, "-"), "._-")
+}
@@ -0,0 +1,107 @@
+package autodiscovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerImageName(t *testing.T) {
+	tests := []struct {
+		file     string
+		folder   string
+		expected string
+	}{
+		{file: "Dockerfile", folder: "gcloud", expected: "gcloud"},
+		{file: "Dockerfile.zig", folder: "zig", expected: "zig"},
+		{file: "Dockerfile.maven_17-jdk-jammy", folder: "maven", expected: "maven_17-jdk-jammy"},
+		{file: "Dockerfile.worker", folder: "deploy", expected: "deploy-worker"},
+		{file: "Dockerfilego", folder: "debian", expected: "debian-go"},
+		{file: "Dockerfile_chromium_go", folder: "alpine", expected: "alpine-chromium_go"},
+		{file: "api.Dockerfile", folder: "My Service", expected: "my-service-api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			variant, ok := dockerfileVariant(tt.file)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, dockerImageName(tt.folder, variant))
+		})
+	}
+
+	_, ok := dockerfileVariant("Dockerfile.dockerignore")
+	assert.False(t, ok)
+	_, ok = dockerfileVariant("docker-compose.yml")
+	assert.False(t, ok)
+}
+
+func TestDiscoverDockerfilesIntegration(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                          "module example.com/app\n",
+		"Dockerfile":                      "FROM scratch",
+		"pkg/zig/Dockerfile.zig":          "FROM alpine",
+		"pkg/zig/zig.go":                  "package zig",
+		"deploy/Dockerfile.worker":        "FROM alpine",
+		"deploy/Dockerfile.dockerignore":  "*",
+		"node_modules/dep/Dockerfile":     "FROM node",
+		"web/package.json":                `{"name": "web"}`,
+		"web/Dockerfile":                  "FROM node",
+		"svc/go.mod":                      "module example.com/svc\n",
+		"svc/deploy/Dockerfile":           "FROM scratch",
+		"tools/Dockerfile.lint":           "FROM alpine",
+		".containifyci/Dockerfile.custom": "FROM golang",
+	})
+
+	owners := []Project{
+		{AppName: "app", ModulePath: root},
+		{AppName: "web", ModulePath: filepath.Join(root, "web")},
+		{AppName: "svc", ModulePath: filepath.Join(root, "svc")},
+	}
+	known := []KnownImage{{Dockerfile: "pkg/zig/Dockerfile.zig", Name: "zig-0.14"}}
+	projects, err := DiscoverDockerfiles(root, owners, known...)
+	require.NoError(t, err)
+
+	byName := map[string]Project{}
+	for _, p := range projects {
+		byName[p.AppName] = p
+	}
+	assert.Len(t, projects, 3)
+
+	zig := byName["zig-0.14"]
+	assert.Equal(t, protos2.BuildType_Generic, zig.BuildType)
+	assert.Equal(t, filepath.Join(root, "pkg/zig"), zig.ModulePath)
+	assert.Equal(t, []string{"Dockerfile.zig"}, zig.Properties["dockerfile"])
+	assert.Equal(t, []string{"checksum"}, zig.Properties["image_tag"])
+	assert.Equal(t, "NewDockerfileBuild", zig.BuilderFunction())
+
+	worker := byName["deploy-worker"]
+	assert.Equal(t, filepath.Join(root, "deploy/Dockerfile.worker"), worker.MainFile)
+	assert.NotContains(t, worker.Properties, "image_tag")
+
+	// the Dockerfile inside the svc module belongs to it
+	assert.Contains(t, byName, "tools-lint")
+	assert.NotContains(t, byName, "deploy")
+}
+
+func TestDockerfileProjectToBuild(t *testing.T) {
+	project := Project{
+		AppName:    "deploy-worker",
+		ModulePath: "deploy",
+		MainFile:   "deploy/Dockerfile.worker",
+		BuildType:  protos2.BuildType_Generic,
+		IsService:  true,
+		Properties: map[string][]string{"dockerfile": {"Dockerfile.worker"}},
+	}
+
+	build := project.ToBuild()
+	assert.Equal(t, container.Generic, build.BuildType)
+	assert.Equal(t, "deploy-worker", build.Image)
+	assert.Equal(t, "deploy", build.Folder)
+	assert.Equal(t, "NewDockerfileBuild", build.BuilderFunction)
+	assert.Equal(t, "Dockerfile.worker", build.Custom.String("dockerfile"))
+}
@@ -3,6 +3,7 @@ package autodiscovery
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,8 +21,10 @@ type Project struct {
 	AppName     string
 	SourceFiles []string
 	ProtoFiles  []string
-	IsService   bool
-	BuildType   protos2.BuildType
+	// Properties are passed to the build as custom properties
+	Properties map[string][]string
+	IsService  bool
+	BuildType  protos2.BuildType
 }
 
 func (p Project) BuilderFunction() string {
@@ -41,6 +44,28 @@ func (p Project) BuilderFunction() string {
 			return "NewMavenServiceBuild"
 		}
 		return "NewMavenLibraryBuild"
+	case protos2.BuildType_NodeJS:
+		if p.IsService {
+			return "NewNodeServiceBuild"
+		}
+		return "NewNodeLibraryBuild"
+	case protos2.BuildType_Typescript:
+		if p.IsService {
+			return "NewTypescriptServiceBuild"
+		}
+		return "NewTypescriptLibraryBuild"
+	case protos2.BuildType_Rust:
+		if p.IsService {
+			return "NewRustServiceBuild"
+		}
+		return "NewRustLibraryBuild"
+	case protos2.BuildType_Zig:
+		if p.IsService {
+			return "NewZigServiceBuild"
+		}
+		return "NewZigLibraryBuild"
+	case protos2.BuildType_Generic:
+		return "NewDockerfileBuild"
 	default:
 		panic("unknown build type")
 	}
@@ -63,7 +88,7 @@ func DiscoverGoProjects(rootDir string) ([]Project, error) {
 		moduleFolder = filepath.Base(moduleFolder)
 		if moduleFolder == "containifyci" || moduleFolder == "dagger" ||
 			moduleFolder == ".containifyci" || moduleFolder == ".dagger" {
-			fmt.Println("Skipping .containifyci/.dagger directories")
+			slog.Debug("Skipping .containifyci/.dagger directories", "path", project.ModulePath)
 			continue
 		}
 		if err != nil {
@@ -253,24 +278,27 @@ func extractPackagesFromFiles(files []string) []string {
 	return packages
 }
 
-// GenerateBuildGroups creates container.BuildGroups from discovered Go projects
-func GenerateBuildGroups(projects []Project) container.BuildGroups {
-	var groups container.BuildGroups
-
-	//TODO support concurrent builds based on project dependencies
-	for _, project := range projects {
-		build := GoProjectToBuild(project)
-		build.Defaults()
+// GenerateBuildGroups creates container.BuildGroups from discovered Go projects.
+// Independent projects share a group, a project is only built in a later
+// group than the local modules it depends on.
+func GenerateBuildGroups(projects []Project) (container.BuildGroups, error) {
+	stages, err := OrderProjects(projects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to order projects: %w", err)
+	}
 
-		// Create a build group with this single build
-		group := &container.BuildGroup{
-			Builds: []*container.Build{&build},
+	var groups container.BuildGroups
+	for _, stage := range stages {
+		group := &container.BuildGroup{}
+		for _, project := range stage {
+			build := GoProjectToBuild(project)
+			build.Defaults()
+			group.Builds = append(group.Builds, &build)
 		}
-
 		groups = append(groups, group)
 	}
 
-	return groups
+	return groups, nil
 }
 
 // DiscoverAndGenerateBuildGroups is a convenience function that combines discovery and build generation
@@ -284,5 +312,5 @@ func DiscoverAndGenerateBuildGroups(rootDir string) (container.BuildGroups, erro
 		return nil, fmt.Errorf("no Go projects found in %s", rootDir)
 	}
 
-	return GenerateBuildGroups(projects), nil
+	return GenerateBuildGroups(projects)
 }
@@ -282,18 +282,17 @@ func TestGenerateBuildGroups(t *testing.T) {
 		},
 	}
 
-	result := GenerateBuildGroups(projects)
-
-	// Should have one build group per project
-	if len(result) != len(projects) {
-		t.Errorf("Expected %d build groups, got %d", len(projects), len(result))
+	result, err := GenerateBuildGroups(projects)
+	if err != nil {
+		t.Fatalf("Failed to generate build groups: %v", err)
 	}
 
-	// Each build group should have one build
-	for i, group := range result {
-		if len(group.Builds) != 1 {
-			t.Errorf("Build group %d should have 1 build, got %d", i, len(group.Builds))
-		}
+	// Independent projects are built in parallel in one group
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 build group, got %d", len(result))
+	}
+	if len(result[0].Builds) != len(projects) {
+		t.Errorf("Expected %d builds in the group, got %d", len(projects), len(result[0].Builds))
 	}
 }
 
@@ -0,0 +1,341 @@
+package autodiscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containifyci/engine-ci/pkg/filesystem"
+	"github.com/containifyci/engine-ci/protos2"
+	"gopkg.in/yaml.v3"
+)
+
+// packageJSON holds the package.json fields used by the discovery
+type packageJSON struct {
+	Workspaces      json.RawMessage   `json:"workspaces"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	Engines         map[string]string `json:"engines"`
+	Bin             json.RawMessage   `json:"bin"`
+	Name            string            `json:"name"`
+	Main            string            `json:"main"`
+	PackageManager  string            `json:"packageManager"`
+}
+
+// nodeLockfiles maps the lockfiles to their package manager, in detection order
+var nodeLockfiles = []struct {
+	file    string
+	manager string
+}{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"package-lock.json", "npm"},
+	{"npm-shrinkwrap.json", "npm"},
+}
+
+// nodeServiceDependencies indicate a deployable Node.js service
+var nodeServiceDependencies = []string{
+	"express", "fastify", "koa", "@hapi/hapi", "@nestjs/core", "next", "nuxt", "@remix-run/node",
+}
+
+// nodeWorkspace is a workspace root with its member folders
+type nodeWorkspace struct {
+	dir     string
+	manager string
+	members map[string]bool
+}
+
+// DiscoverNodeProjects scans the given root directory recursively for Node.js
+// and TypeScript projects. Workspace roots are replaced by their packages.
+func DiscoverNodeProjects(rootDir string) ([]Project, error) {
+	var projects []Project
+
+	files, err := findNodeFiles(rootDir, func(name string) bool { return name == "package.json" })
+	if err != nil {
+		return nil, fmt.Errorf("failed to find package.json files: %w", err)
+	}
+
+	packages := map[string]*packageJSON{}
+	dirs := []string{}
+	for _, file := range files {
+		pkg, err := parsePackageJSON(file)
+		if err != nil {
+			slog.Warn("Failed to parse package.json", "path", file, "error", err)
+			continue
+		}
+		dir := filepath.Dir(file)
+		packages[dir] = pkg
+		dirs = append(dirs, dir)
+	}
+	// parents first so that workspace roots are known before their members
+	sort.Strings(dirs)
+
+	workspaces := []*nodeWorkspace{}
+	for _, dir := range dirs {
+		if members := workspaceMembers(dir, packages[dir]); members != nil {
+			workspaces = append(workspaces, &nodeWorkspace{
+				dir:     dir,
+				manager: detectPackageManager(dir, packages[dir]),
+				members: members,
+			})
+		}
+	}
+
+	for _, dir := range dirs {
+		pkg := packages[dir]
+		workspace := findWorkspace(dir, workspaces)
+		if workspace != nil && workspace.dir == dir && len(workspace.members) > 0 {
+			// the workspace root only orchestrates its packages
+			continue
+		}
+		if workspace == nil && (isSubproject(dir, projects) || inWorkspace(dir, workspaces)) {
+			// nested packages outside of the workspace globs are fixtures or excluded
+			continue
+		}
+
+		project, err := analyzeNodeProject(dir, pkg, workspace)
+		if err != nil {
+			slog.Warn("Failed to analyze Node.js project", "path", dir, "error", err)
+			continue
+		}
+
+		slog.Info("Discovered Node.js project", "name", project.AppName, "path", project.ModulePath, "type", project.BuildType)
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// findNodeFiles walks the folder once and returns the matching files,
+// the dependencies in node_modules folders are skipped
+func findNodeFiles(root string, match func(name string) bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(d.Name()) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func parsePackageJSON(file string) (*packageJSON, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("invalid package.json: %w", err)
+	}
+	return &pkg, nil
+}
+
+// workspacePatterns returns the workspace globs of package.json or pnpm-workspace.yaml
+func workspacePatterns(dir string, pkg *packageJSON) []string {
+	if len(pkg.Workspaces) > 0 {
+		var patterns []string
+		if err := json.Unmarshal(pkg.Workspaces, &patterns); err == nil {
+			return patterns
+		}
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &yarn); err == nil {
+			return yarn.Packages
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml"))
+	if err != nil {
+		return nil
+	}
+	var pnpm struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &pnpm); err != nil {
+		slog.Warn("Failed to parse pnpm-workspace.yaml", "path", dir, "error", err)
+		return nil
+	}
+	return pnpm.Packages
+}
+
+// workspaceMembers resolves the workspace globs to the package folders,
+// nil when the folder is no workspace root
+func workspaceMembers(dir string, pkg *packageJSON) map[string]bool {
+	patterns := workspacePatterns(dir, pkg)
+	if patterns == nil {
+		return nil
+	}
+
+	members := map[string]bool{}
+	excluded := map[string]bool{}
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "!"), "/")
+		// ** is treated like * which covers the common packages/** layout
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+		matches, err := filepath.Glob(filepath.Join(dir, pattern, "package.json"))
+		if err != nil {
+			slog.Warn("Invalid workspace pattern", "path", dir, "pattern", pattern, "error", err)
+			continue
+		}
+		for _, match := range matches {
+			if exclude {
+				excluded[filepath.Dir(match)] = true
+			} else {
+				members[filepath.Dir(match)] = true
+			}
+		}
+	}
+	for member := range excluded {
+		delete(members, member)
+	}
+	return members
+}
+
+// findWorkspace returns the workspace the folder belongs to or is the root of
+func findWorkspace(dir string, workspaces []*nodeWorkspace) *nodeWorkspace {
+	for _, workspace := range workspaces {
+		if workspace.dir == dir || workspace.members[dir] {
+			return workspace
+		}
+	}
+	return nil
+}
+
+// inWorkspace reports whether the folder is below a workspace root
+func inWorkspace(dir string, workspaces []*nodeWorkspace) bool {
+	for _, workspace := range workspaces {
+		relPath, err := filepath.Rel(workspace.dir, dir)
+		if err == nil && !strings.HasPrefix(relPath, "..") && relPath != "." {
+			return true
+		}
+	}
+	return false
+}
+
+// detectPackageManager uses the packageManager field or the lockfile of the folder
+func detectPackageManager(dir string, pkg *packageJSON) string {
+	if pkg.PackageManager != "" {
+		name, _, _ := strings.Cut(pkg.PackageManager, "@")
+		return name
+	}
+	for _, lock := range nodeLockfiles {
+		if filesystem.FileExists(filepath.Join(dir, lock.file)) {
+			return lock.manager
+		}
+	}
+	return "npm"
+}
+
+// analyzeNodeProject analyzes a single package.json project
+func analyzeNodeProject(dir string, pkg *packageJSON, workspace *nodeWorkspace) (Project, error) {
+	project := Project{
+		ModulePath: dir,
+		ModuleName: pkg.Name,
+		BuildType:  protos2.BuildType_NodeJS,
+		Properties: map[string][]string{},
+	}
+
+	// @scope/name -> name
+	name := pkg.Name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		absPath, err := filepath.Abs(dir)
+		if err != nil {
+			return project, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		name = filepath.Base(absPath)
+	}
+	project.AppName = name
+
+	manager := detectPackageManager(dir, pkg)
+	if workspace != nil {
+		manager = workspace.manager
+		project.Properties["workspace"] = []string{workspace.dir}
+	}
+	project.Properties["package_manager"] = []string{manager}
+	if node := pkg.Engines["node"]; node != "" {
+		project.Properties["node_version"] = []string{node}
+	}
+
+	if isTypescriptProject(dir, pkg) {
+		project.BuildType = protos2.BuildType_Typescript
+	}
+
+	sourceFiles, err := nodeSourceFiles(dir)
+	if err != nil {
+		return project, err
+	}
+	project.SourceFiles = sourceFiles
+
+	project.IsService = isNodeService(dir, pkg)
+	if project.IsService && pkg.Main != "" {
+		project.MainFile = filepath.Join(dir, pkg.Main)
+	}
+
+	return project, nil
+}
+
+// isTypescriptProject checks for a tsconfig.json or a typescript dependency
+func isTypescriptProject(dir string, pkg *packageJSON) bool {
+	if filesystem.FileExists(filepath.Join(dir, "tsconfig.json")) {
+		return true
+	}
+	_, dev := pkg.DevDependencies["typescript"]
+	_, dep := pkg.Dependencies["typescript"]
+	return dev || dep
+}
+
+// isNodeService checks for a start script, a Dockerfile or a server framework.
+// Packages with a bin entry are command line tools published as libraries.
+func isNodeService(dir string, pkg *packageJSON) bool {
+	if len(pkg.Bin) > 0 {
+		return false
+	}
+	if _, ok := pkg.Scripts["start"]; ok {
+		return true
+	}
+	if filesystem.FileExists(filepath.Join(dir, "Dockerfile")) {
+		return true
+	}
+	for _, dep := range nodeServiceDependencies {
+		if _, ok := pkg.Dependencies[dep]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSourceFiles returns the JavaScript and TypeScript files outside of node_modules
+func nodeSourceFiles(dir string) ([]string, error) {
+	files, err := findNodeFiles(dir, func(name string) bool {
+		switch filepath.Ext(name) {
+		case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs":
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source files: %w", err)
+	}
+	return files, nil
+}
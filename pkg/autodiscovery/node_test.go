@@ -0,0 +1,144 @@
+package autodiscovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockfile string
+		pkg      packageJSON
+		expected string
+	}{
+		{name: "pnpm lockfile", lockfile: "pnpm-lock.yaml", expected: "pnpm"},
+		{name: "yarn lockfile", lockfile: "yarn.lock", expected: "yarn"},
+		{name: "npm lockfile", lockfile: "package-lock.json", expected: "npm"},
+		{name: "packageManager field", lockfile: "package-lock.json", pkg: packageJSON{PackageManager: "yarn@4.1.0"}, expected: "yarn"},
+		{name: "default npm", expected: "npm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.lockfile != "" {
+				writeFiles(t, dir, map[string]string{tt.lockfile: ""})
+			}
+			assert.Equal(t, tt.expected, detectPackageManager(dir, &tt.pkg))
+		})
+	}
+}
+
+func TestIsNodeService(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.True(t, isNodeService(dir, &packageJSON{Scripts: map[string]string{"start": "node index.js"}}))
+	assert.True(t, isNodeService(dir, &packageJSON{Dependencies: map[string]string{"express": "^4.19.0"}}))
+	assert.False(t, isNodeService(dir, &packageJSON{Main: "index.js"}))
+	assert.False(t, isNodeService(dir, &packageJSON{
+		Bin:     []byte(`"./cli.js"`),
+		Scripts: map[string]string{"start": "node cli.js"},
+	}))
+}
+
+func TestDiscoverNodeProjectsIntegration(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		// pnpm monorepo
+		"web/package.json":                  `{"name": "web-root", "private": true}`,
+		"web/pnpm-workspace.yaml":           "packages:\n  - 'packages/*'\n  - '!packages/ignored'\n",
+		"web/pnpm-lock.yaml":                "",
+		"web/packages/api/package.json":     `{"name": "@acme/api", "main": "dist/index.js", "dependencies": {"fastify": "^4.0.0"}, "engines": {"node": ">=20"}}`,
+		"web/packages/api/tsconfig.json":    `{}`,
+		"web/packages/api/src/index.ts":     "export {}",
+		"web/packages/ui/package.json":      `{"name": "@acme/ui", "main": "index.js"}`,
+		"web/packages/ui/index.js":          "module.exports = {}",
+		"web/packages/ignored/package.json": `{"name": "ignored"}`,
+		// yarn workspaces in package.json
+		"tools/package.json":             `{"private": true, "workspaces": {"packages": ["cli"]}}`,
+		"tools/yarn.lock":                "",
+		"tools/cli/package.json":         `{"name": "cli", "bin": {"cli": "index.js"}}`,
+		"tools/cli/fixture/package.json": `{"name": "fixture"}`,
+		// standalone npm service
+		"service/package.json":                  `{"name": "service", "scripts": {"start": "node server.js"}}`,
+		"service/package-lock.json":             "",
+		"service/server.js":                     "",
+		"service/node_modules/dep/package.json": `{"name": "dep"}`,
+		"service/node_modules/dep/index.js":     "",
+	})
+
+	projects, err := DiscoverNodeProjects(root)
+	require.NoError(t, err)
+
+	byName := map[string]Project{}
+	for _, p := range projects {
+		byName[p.AppName] = p
+	}
+	assert.Len(t, projects, 4)
+	assert.NotContains(t, byName, "web-root")
+	assert.NotContains(t, byName, "ignored")
+	assert.NotContains(t, byName, "fixture")
+	assert.NotContains(t, byName, "dep")
+
+	api := byName["api"]
+	assert.Equal(t, protos2.BuildType_Typescript, api.BuildType)
+	assert.True(t, api.IsService)
+	assert.Equal(t, filepath.Join(root, "web/packages/api/dist/index.js"), api.MainFile)
+	assert.Equal(t, []string{"pnpm"}, api.Properties["package_manager"])
+	assert.Equal(t, []string{filepath.Join(root, "web")}, api.Properties["workspace"])
+	assert.Equal(t, []string{">=20"}, api.Properties["node_version"])
+	assert.Equal(t, "NewTypescriptServiceBuild", api.BuilderFunction())
+
+	ui := byName["ui"]
+	assert.Equal(t, protos2.BuildType_NodeJS, ui.BuildType)
+	assert.False(t, ui.IsService)
+	assert.Equal(t, "NewNodeLibraryBuild", ui.BuilderFunction())
+
+	cli := byName["cli"]
+	assert.False(t, cli.IsService)
+	assert.Equal(t, []string{"yarn"}, cli.Properties["package_manager"])
+
+	service := byName["service"]
+	assert.True(t, service.IsService)
+	assert.Equal(t, []string{"npm"}, service.Properties["package_manager"])
+	assert.Equal(t, []string{filepath.Join(root, "service/server.js")}, service.SourceFiles)
+}
+
+func TestNodeProjectToBuild(t *testing.T) {
+	project := Project{
+		AppName:    "api",
+		ModulePath: "web/packages/api",
+		BuildType:  protos2.BuildType_Typescript,
+		IsService:  true,
+		Properties: map[string][]string{"package_manager": {"pnpm"}},
+	}
+
+	build := project.ToBuild()
+	assert.Equal(t, container.Typescript, build.BuildType)
+	assert.Equal(t, "api", build.Image)
+	assert.Equal(t, "web/packages/api", build.Folder)
+	assert.Equal(t, "NewTypescriptServiceBuild", build.BuilderFunction)
+	assert.Equal(t, "pnpm", build.Custom.String("package_manager"))
+
+	project.IsService = false
+	project.BuildType = protos2.BuildType_NodeJS
+	build = project.ToBuild()
+	assert.Equal(t, container.NodeJS, build.BuildType)
+	assert.Empty(t, build.Image)
+}
@@ -19,11 +19,46 @@ func (p Project) ToBuild() container.Build {
 		return PythonProjectToBuild(p)
 	case protos2.BuildType_Maven:
 		return JavaProjectToBuild(p)
+	case protos2.BuildType_NodeJS:
+		return projectToBuild(p, container.NodeJS)
+	case protos2.BuildType_Typescript:
+		return projectToBuild(p, container.Typescript)
+	case protos2.BuildType_Rust:
+		return projectToBuild(p, container.Rust)
+	case protos2.BuildType_Zig:
+		return projectToBuild(p, container.Zig)
+	case protos2.BuildType_Generic:
+		return projectToBuild(p, container.Generic)
 	default:
 		return container.Build{}
 	}
 }
 
+// projectToBuild converts a discovered project whose language specific
+// settings are kept in its properties to a container.Build configuration
+func projectToBuild(project Project, buildType container.BuildType) container.Build {
+	build := container.NewServiceBuild(project.AppName, buildType)
+	build.BuilderFunction = project.BuilderFunction()
+
+	if !project.IsService {
+		build.Image = ""
+	}
+
+	if project.MainFile != "" {
+		build.File = project.MainFile
+	}
+
+	if project.ModulePath != "" {
+		build.Folder = project.ModulePath
+	}
+
+	if len(project.Properties) > 0 {
+		build.Custom = project.Properties
+	}
+
+	return build
+}
+
 // DiscoverPythonProjects scans the given root directory recursively for Python projects
 func DiscoverPythonProjects(rootDir string) ([]Project, error) {
 	var projects []Project
@@ -240,8 +240,7 @@ if __name__ == "__main__":
 	err = os.WriteFile(filepath.Join(pyprojectDir, "main.py"), []byte(mainContent), 0644)
 	require.NoError(t, err)
 
-	err = os.Chdir(pyprojectDir)
-	require.NoError(t, err)
+	t.Chdir(pyprojectDir)
 
 	// Test discovery
 	projects, err := DiscoverPythonProjects(".")
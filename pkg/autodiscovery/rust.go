@@ -0,0 +1,236 @@
+package autodiscovery
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containifyci/engine-ci/pkg/filesystem"
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+// cargoManifest holds the Cargo.toml fields used by the discovery
+type cargoManifest struct {
+	Package *struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Workspace *struct {
+		Members []string `toml:"members"`
+		Exclude []string `toml:"exclude"`
+	} `toml:"workspace"`
+	Bin []struct {
+		Name string `toml:"name"`
+		Path string `toml:"path"`
+	} `toml:"bin"`
+}
+
+// cargoWorkspace is a workspace root with its member folders
+type cargoWorkspace struct {
+	dir     string
+	members map[string]bool
+}
+
+// DiscoverRustProjects scans the given root directory recursively for Cargo
+// projects. Virtual workspace manifests are replaced by their members.
+func DiscoverRustProjects(rootDir string) ([]Project, error) {
+	var projects []Project
+
+	fileCache := filesystem.NewFileCache("rust_cache.yaml")
+	files, err := fileCache.FindFilesBySuffix(rootDir, "Cargo.toml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Cargo.toml files: %w", err)
+	}
+
+	manifests := map[string]*cargoManifest{}
+	dirs := []string{}
+	for _, file := range files {
+		if filepath.Base(file) != "Cargo.toml" || inCargoTarget(file) {
+			continue
+		}
+		var manifest cargoManifest
+		if _, err := toml.DecodeFile(file, &manifest); err != nil {
+			slog.Warn("Failed to parse Cargo.toml", "path", file, "error", err)
+			continue
+		}
+		dir := filepath.Dir(file)
+		manifests[dir] = &manifest
+		dirs = append(dirs, dir)
+	}
+	// parents first so that workspace roots are known before their members
+	sort.Strings(dirs)
+
+	workspaces := []*cargoWorkspace{}
+	for _, dir := range dirs {
+		if manifests[dir].Workspace != nil {
+			workspaces = append(workspaces, &cargoWorkspace{
+				dir:     dir,
+				members: cargoWorkspaceMembers(dir, manifests[dir]),
+			})
+		}
+	}
+
+	for _, dir := range dirs {
+		manifest := manifests[dir]
+		if manifest.Package == nil {
+			// virtual manifest of a workspace
+			continue
+		}
+
+		workspace := findCargoWorkspace(dir, workspaces)
+		if workspace == nil && isSubproject(dir, projects) {
+			continue
+		}
+
+		project, err := analyzeRustProject(dir, manifest, workspace)
+		if err != nil {
+			slog.Warn("Failed to analyze Rust project", "path", dir, "error", err)
+			continue
+		}
+
+		slog.Info("Discovered Rust project", "name", project.AppName, "path", project.ModulePath, "isService", project.IsService)
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// inCargoTarget reports whether the path is inside a Cargo target folder
+func inCargoTarget(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == "target" {
+			return true
+		}
+	}
+	return false
+}
+
+// cargoWorkspaceMembers resolves the member globs without the excluded folders
+func cargoWorkspaceMembers(dir string, manifest *cargoManifest) map[string]bool {
+	members := map[string]bool{}
+	for _, pattern := range manifest.Workspace.Members {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern, "Cargo.toml"))
+		if err != nil {
+			slog.Warn("Invalid workspace member", "path", dir, "member", pattern, "error", err)
+			continue
+		}
+		for _, match := range matches {
+			members[filepath.Dir(match)] = true
+		}
+	}
+	for _, pattern := range manifest.Workspace.Exclude {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			delete(members, match)
+		}
+	}
+	return members
+}
+
+// findCargoWorkspace returns the workspace the crate is the root or a member of
+func findCargoWorkspace(dir string, workspaces []*cargoWorkspace) *cargoWorkspace {
+	for _, workspace := range workspaces {
+		if workspace.dir == dir || workspace.members[dir] {
+			return workspace
+		}
+	}
+	return nil
+}
+
+// analyzeRustProject analyzes a single crate
+func analyzeRustProject(dir string, manifest *cargoManifest, workspace *cargoWorkspace) (Project, error) {
+	project := Project{
+		ModulePath: dir,
+		ModuleName: manifest.Package.Name,
+		AppName:    manifest.Package.Name,
+		BuildType:  protos2.BuildType_Rust,
+		Properties: map[string][]string{},
+	}
+	if project.AppName == "" {
+		absPath, err := filepath.Abs(dir)
+		if err != nil {
+			return project, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		project.AppName = filepath.Base(absPath)
+	}
+
+	if workspace != nil {
+		project.Properties["workspace"] = []string{workspace.dir}
+	}
+
+	fileCache := filesystem.NewFileCache("rust_files_cache.yaml")
+	rsFiles, err := fileCache.FindFilesBySuffix(dir, ".rs")
+	if err != nil {
+		return project, fmt.Errorf("failed to find .rs files: %w", err)
+	}
+	for _, file := range rsFiles {
+		if !inCargoTarget(strings.TrimPrefix(file, dir)) {
+			project.SourceFiles = append(project.SourceFiles, file)
+		}
+	}
+
+	bins, mainFile := cargoBinaries(dir, manifest, project.AppName)
+	project.IsService = len(bins) > 0
+	project.MainFile = mainFile
+	if len(bins) > 0 {
+		project.Properties["bins"] = bins
+	}
+
+	return project, nil
+}
+
+// cargoBinaries returns the binary targets of the crate, the explicit [[bin]]
+// targets, src/main.rs and the auto-discovered src/bin/*.rs targets
+func cargoBinaries(dir string, manifest *cargoManifest, name string) ([]string, string) {
+	bins := []string{}
+	mainFile := ""
+	for _, bin := range manifest.Bin {
+		binName := bin.Name
+		if binName == "" {
+			binName = name
+		}
+		bins = append(bins, binName)
+		path := bin.Path
+		if path == "" && binName == name {
+			path = filepath.Join("src", "main.rs")
+		} else if path == "" {
+			path = filepath.Join("src", "bin", binName+".rs")
+		}
+		if mainFile == "" {
+			mainFile = filepath.Join(dir, path)
+		}
+	}
+
+	mainRs := filepath.Join(dir, "src", "main.rs")
+	if filesystem.FileExists(mainRs) && !slices.Contains(bins, name) && !containsPath(manifest, "src/main.rs") {
+		bins = append(bins, name)
+		if mainFile == "" {
+			mainFile = mainRs
+		}
+	}
+
+	extra, _ := filepath.Glob(filepath.Join(dir, "src", "bin", "*.rs"))
+	for _, file := range extra {
+		binName := strings.TrimSuffix(filepath.Base(file), ".rs")
+		if !slices.Contains(bins, binName) && !containsPath(manifest, filepath.ToSlash(filepath.Join("src", "bin", filepath.Base(file)))) {
+			bins = append(bins, binName)
+			if mainFile == "" {
+				mainFile = file
+			}
+		}
+	}
+	return bins, mainFile
+}
+
+// containsPath reports whether a [[bin]] target already points to the path
+func containsPath(manifest *cargoManifest, path string) bool {
+	for _, bin := range manifest.Bin {
+		if filepath.ToSlash(filepath.Clean(bin.Path)) == path {
+			return true
+		}
+	}
+	return false
+}
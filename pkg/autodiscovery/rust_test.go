@@ -0,0 +1,138 @@
+package autodiscovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCargoBinaries(t *testing.T) {
+	tests := []struct {
+		manifest     *cargoManifest
+		files        map[string]string
+		name         string
+		expectedMain string
+		expected     []string
+	}{
+		{
+			name:         "src/main.rs",
+			manifest:     &cargoManifest{},
+			files:        map[string]string{"src/main.rs": "fn main() {}"},
+			expected:     []string{"app"},
+			expectedMain: "src/main.rs",
+		},
+		{
+			name:     "library",
+			manifest: &cargoManifest{},
+			files:    map[string]string{"src/lib.rs": "pub fn lib() {}"},
+			expected: []string{},
+		},
+		{
+			name: "explicit bin targets",
+			manifest: &cargoManifest{Bin: []struct {
+				Name string `toml:"name"`
+				Path string `toml:"path"`
+			}{{Name: "server", Path: "src/server.rs"}}},
+			files: map[string]string{
+				"src/server.rs":  "fn main() {}",
+				"src/bin/cli.rs": "fn main() {}",
+				"src/lib.rs":     "pub fn lib() {}",
+			},
+			expected:     []string{"server", "cli"},
+			expectedMain: "src/server.rs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			bins, mainFile := cargoBinaries(dir, tt.manifest, "app")
+			assert.Equal(t, tt.expected, bins)
+			if tt.expectedMain == "" {
+				assert.Empty(t, mainFile)
+			} else {
+				assert.Equal(t, filepath.Join(dir, tt.expectedMain), mainFile)
+			}
+		})
+	}
+}
+
+func TestDiscoverRustProjectsIntegration(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		// virtual workspace
+		"Cargo.toml":                  "[workspace]\nmembers = [\"crates/*\"]\nexclude = [\"crates/experimental\"]\n",
+		"crates/api/Cargo.toml":       "[package]\nname = \"api\"\nversion = \"0.1.0\"\n",
+		"crates/api/src/main.rs":      "fn main() {}",
+		"crates/core/Cargo.toml":      "[package]\nname = \"core\"\nversion = \"0.1.0\"\n\n[lib]\npath = \"src/lib.rs\"\n",
+		"crates/core/src/lib.rs":      "pub fn core() {}",
+		"crates/core/target/debug.rs": "",
+		// excluded crates are built on their own
+		"crates/experimental/Cargo.toml":  "[package]\nname = \"experimental\"\n",
+		"crates/experimental/src/lib.rs":  "",
+		"target/package/Cargo.toml":       "[package]\nname = \"packaged\"\n",
+		"tools/cli/Cargo.toml":            "[package]\nname = \"cli\"\n\n[workspace]\n\n[[bin]]\nname = \"tool\"\npath = \"src/tool.rs\"\n",
+		"tools/cli/src/tool.rs":           "fn main() {}",
+		"tools/cli/fixtures/Cargo.toml":   "[package]\nname = \"fixture\"\n",
+		"tools/cli/fixtures/src/main.rs":  "fn main() {}",
+		"tools/cli/fixtures/src/other.rs": "",
+	})
+
+	projects, err := DiscoverRustProjects(root)
+	require.NoError(t, err)
+
+	byName := map[string]Project{}
+	for _, p := range projects {
+		byName[p.AppName] = p
+	}
+	assert.Len(t, projects, 4)
+	assert.NotContains(t, byName, "packaged")
+	assert.NotContains(t, byName, "fixture")
+
+	api := byName["api"]
+	assert.Equal(t, protos2.BuildType_Rust, api.BuildType)
+	assert.True(t, api.IsService)
+	assert.Equal(t, filepath.Join(root, "crates/api/src/main.rs"), api.MainFile)
+	assert.Equal(t, []string{root}, api.Properties["workspace"])
+	assert.Equal(t, "NewRustServiceBuild", api.BuilderFunction())
+
+	core := byName["core"]
+	assert.False(t, core.IsService)
+	assert.Equal(t, []string{filepath.Join(root, "crates/core/src/lib.rs")}, core.SourceFiles)
+	assert.Equal(t, "NewRustLibraryBuild", core.BuilderFunction())
+
+	experimental := byName["experimental"]
+	assert.NotContains(t, experimental.Properties, "workspace")
+
+	cli := byName["cli"]
+	assert.True(t, cli.IsService)
+	assert.Equal(t, []string{"tool"}, cli.Properties["bins"])
+	assert.Equal(t, []string{filepath.Join(root, "tools/cli")}, cli.Properties["workspace"])
+}
+
+func TestRustProjectToBuild(t *testing.T) {
+	project := Project{
+		AppName:    "api",
+		ModulePath: "crates/api",
+		MainFile:   "crates/api/src/main.rs",
+		BuildType:  protos2.BuildType_Rust,
+		IsService:  true,
+		Properties: map[string][]string{"bins": {"api"}},
+	}
+
+	build := project.ToBuild()
+	assert.Equal(t, container.Rust, build.BuildType)
+	assert.Equal(t, "api", build.Image)
+	assert.Equal(t, "crates/api", build.Folder)
+	assert.Equal(t, "crates/api/src/main.rs", build.File)
+	assert.Equal(t, []string{"api"}, build.Custom["bins"])
+
+	project.IsService = false
+	assert.Empty(t, project.ToBuild().Image)
+}
@@ -7,6 +7,10 @@ const (
 	ProjectTypeGo     ProjectType = "go"
 	ProjectTypePython ProjectType = "python"
 	ProjectTypeJava   ProjectType = "java"
+	ProjectTypeNode   ProjectType = "node"
+	ProjectTypeRust   ProjectType = "rust"
+	ProjectTypeZig    ProjectType = "zig"
+	ProjectTypeDocker ProjectType = "docker"
 )
 
 // // Project represents a discovered project that can be built
@@ -38,6 +42,10 @@ type ProjectCollection struct {
 	GoProjects     []Project
 	PythonProjects []Project
 	JavaProjects   []Project
+	NodeProjects   []Project
+	RustProjects   []Project
+	ZigProjects    []Project
+	DockerProjects []Project
 }
 
 // AllProjects returns all discovered projects as a slice of Project interfaces
@@ -50,6 +58,14 @@ func (pc *ProjectCollection) AllProjects() []Project {
 
 	all = append(all, pc.JavaProjects...)
 
+	all = append(all, pc.NodeProjects...)
+
+	all = append(all, pc.RustProjects...)
+
+	all = append(all, pc.ZigProjects...)
+
+	all = append(all, pc.DockerProjects...)
+
 	return all
 }
 
@@ -59,10 +75,16 @@ func (pc *ProjectCollection) CountByType() map[ProjectType]int {
 		ProjectTypeGo:     len(pc.GoProjects),
 		ProjectTypePython: len(pc.PythonProjects),
 		ProjectTypeJava:   len(pc.JavaProjects),
+		ProjectTypeNode:   len(pc.NodeProjects),
+		ProjectTypeRust:   len(pc.RustProjects),
+		ProjectTypeZig:    len(pc.ZigProjects),
+		ProjectTypeDocker: len(pc.DockerProjects),
 	}
 }
 
 // IsEmpty returns true if no projects were discovered
 func (pc *ProjectCollection) IsEmpty() bool {
-	return len(pc.GoProjects) == 0 && len(pc.PythonProjects) == 0 && len(pc.JavaProjects) == 0
+	return len(pc.GoProjects) == 0 && len(pc.PythonProjects) == 0 && len(pc.JavaProjects) == 0 && len(pc.NodeProjects) == 0 &&
+		len(pc.RustProjects) == 0 && len(pc.ZigProjects) == 0 &&
+		len(pc.DockerProjects) == 0
 }
@@ -0,0 +1,150 @@
+package autodiscovery
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/containifyci/engine-ci/pkg/filesystem"
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+const (
+	// zigOptimize keeps the safety checks in the released binaries
+	zigOptimize = "ReleaseSafe"
+	// zigExecutableTarget links the executables statically for the container image
+	zigExecutableTarget = "native-linux-musl"
+)
+
+var (
+	// .name = .app (Zig 0.14+) or .name = "app"
+	zigZonName = regexp.MustCompile(`\.name\s*=\s*(?:\.@?"?([A-Za-z0-9_\-]+)"?|"([^"]+)")`)
+	// the .name of the options passed to b.addExecutable
+	zigExecutable = regexp.MustCompile(`(?s)addExecutable\(\s*\.\{.*?\.name\s*=\s*"([^"]+)"`)
+)
+
+// DiscoverZigProjects scans the given root directory recursively for build.zig projects
+func DiscoverZigProjects(rootDir string) ([]Project, error) {
+	var projects []Project
+
+	fileCache := filesystem.NewFileCache("zig_cache.yaml")
+	buildFiles, err := fileCache.FindFilesBySuffix(rootDir, "build.zig")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find build.zig files: %w", err)
+	}
+
+	for _, buildFile := range buildFiles {
+		if filepath.Base(buildFile) != "build.zig" || inZigCache(buildFile) {
+			continue
+		}
+		projectDir := filepath.Dir(buildFile)
+
+		// Skip if this is a subdirectory of another discovered project
+		if isSubproject(projectDir, projects) {
+			continue
+		}
+
+		project, err := analyzeZigProject(projectDir, buildFile)
+		if err != nil {
+			slog.Warn("Failed to analyze Zig project", "path", projectDir, "error", err)
+			continue
+		}
+
+		slog.Info("Discovered Zig project", "name", project.AppName, "path", project.ModulePath, "isService", project.IsService)
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// inZigCache reports whether the path is inside the zig cache or output folders,
+// dependencies are fetched with their build.zig into the cache
+func inZigCache(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".zig-cache" || part == "zig-cache" || part == "zig-out" {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeZigProject analyzes a single build.zig project
+func analyzeZigProject(projectDir, buildFile string) (Project, error) {
+	project := Project{
+		ModulePath: projectDir,
+		BuildType:  protos2.BuildType_Zig,
+		Properties: map[string][]string{},
+	}
+
+	content, err := os.ReadFile(buildFile)
+	if err != nil {
+		return project, fmt.Errorf("failed to read build.zig: %w", err)
+	}
+
+	if zon, err := os.ReadFile(filepath.Join(projectDir, "build.zig.zon")); err == nil {
+		project.ModuleName = parseZigZonName(string(zon))
+	}
+
+	// zig build rejects -Doptimize and -Dtarget unless build.zig declares the options
+	if strings.Contains(string(content), "standardOptimizeOption(") {
+		project.Properties["optimize"] = []string{zigOptimize}
+	}
+
+	executables := []string{}
+	for _, match := range zigExecutable.FindAllStringSubmatch(string(content), -1) {
+		executables = append(executables, match[1])
+	}
+
+	// executables are deployable, build.zig files with only libraries or modules are libraries
+	project.IsService = len(executables) > 0
+	if project.IsService {
+		if strings.Contains(string(content), "standardTargetOptions(") {
+			project.Properties["target"] = []string{zigExecutableTarget}
+		}
+		project.Properties["executables"] = executables
+		mainFile := filepath.Join(projectDir, "src", "main.zig")
+		if filesystem.FileExists(mainFile) {
+			project.MainFile = mainFile
+		}
+	}
+
+	project.AppName = project.ModuleName
+	if project.AppName == "" && len(executables) > 0 {
+		project.AppName = executables[0]
+	}
+	if project.AppName == "" {
+		absPath, err := filepath.Abs(projectDir)
+		if err != nil {
+			return project, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		project.AppName = filepath.Base(absPath)
+	}
+
+	fileCache := filesystem.NewFileCache("zig_files_cache.yaml")
+	zigFiles, err := fileCache.FindFilesBySuffix(projectDir, ".zig")
+	if err != nil {
+		return project, fmt.Errorf("failed to find .zig files: %w", err)
+	}
+	for _, file := range zigFiles {
+		if !inZigCache(file) {
+			project.SourceFiles = append(project.SourceFiles, file)
+		}
+	}
+
+	return project, nil
+}
+
+// parseZigZonName returns the package name of build.zig.zon
+func parseZigZonName(zon string) string {
+	match := zigZonName.FindStringSubmatch(zon)
+	if match == nil {
+		return ""
+	}
+	if match[1] != "" {
+		return match[1]
+	}
+	return match[2]
+}
@@ -0,0 +1,139 @@
+package autodiscovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseZigZonName(t *testing.T) {
+	tests := []struct {
+		name     string
+		zon      string
+		expected string
+	}{
+		{name: "enum literal", zon: ".{\n    .name = .hello,\n    .version = \"0.1.0\",\n}", expected: "hello"},
+		{name: "quoted enum literal", zon: ".{ .name = .@\"hello-world\" }", expected: "hello-world"},
+		{name: "string", zon: ".{\n    .name = \"hello\",\n}", expected: "hello"},
+		{name: "missing", zon: ".{ .version = \"0.1.0\" }", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseZigZonName(tt.zon))
+		})
+	}
+}
+
+const zigExecutableBuild = `const std = @import("std");
+
+pub fn build(b: *std.Build) void {
+    const target = b.standardTargetOptions(.{});
+    const optimize = b.standardOptimizeOption(.{});
+    const exe = b.addExecutable(.{
+        .name = "server",
+        .root_source_file = b.path("src/main.zig"),
+        .target = target,
+        .optimize = optimize,
+    });
+    b.installArtifact(exe);
+}
+`
+
+// zigFixedBuild does not declare the target and optimize options
+const zigFixedBuild = `const std = @import("std");
+
+pub fn build(b: *std.Build) void {
+    const exe = b.addExecutable(.{
+        .name = "tool",
+        .root_source_file = b.path("src/main.zig"),
+        .target = b.graph.host,
+    });
+    b.installArtifact(exe);
+}
+`
+
+const zigLibraryBuild = `const std = @import("std");
+
+pub fn build(b: *std.Build) void {
+    const lib = b.addStaticLibrary(.{
+        .name = "parser",
+        .root_source_file = b.path("src/root.zig"),
+    });
+    b.installArtifact(lib);
+}
+`
+
+func TestDiscoverZigProjectsIntegration(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"server/build.zig":     zigExecutableBuild,
+		"server/build.zig.zon": ".{\n    .name = .api,\n    .version = \"0.1.0\",\n}",
+		"server/src/main.zig":  "pub fn main() void {}",
+		// fetched dependencies come with their own build.zig
+		"server/.zig-cache/p/dep/build.zig": zigLibraryBuild,
+		"server/zig-out/bin/api":            "",
+		"parser/build.zig":                  zigLibraryBuild,
+		"parser/src/root.zig":               "pub fn parse() void {}",
+		"parser/example/build.zig":          zigExecutableBuild,
+		"tool/build.zig":                    zigFixedBuild,
+	})
+
+	projects, err := DiscoverZigProjects(root)
+	require.NoError(t, err)
+
+	byName := map[string]Project{}
+	for _, p := range projects {
+		byName[p.AppName] = p
+	}
+	assert.Len(t, projects, 3)
+
+	api := byName["api"]
+	assert.Equal(t, protos2.BuildType_Zig, api.BuildType)
+	assert.True(t, api.IsService)
+	assert.Equal(t, filepath.Join(root, "server/src/main.zig"), api.MainFile)
+	assert.Equal(t, []string{"server"}, api.Properties["executables"])
+	assert.Equal(t, []string{zigExecutableTarget}, api.Properties["target"])
+	assert.Equal(t, []string{zigOptimize}, api.Properties["optimize"])
+	assert.NotContains(t, api.SourceFiles, filepath.Join(root, "server/.zig-cache/p/dep/build.zig"))
+	assert.Equal(t, "NewZigServiceBuild", api.BuilderFunction())
+
+	parser := byName["parser"]
+	assert.False(t, parser.IsService)
+	assert.Empty(t, parser.MainFile)
+	assert.NotContains(t, parser.Properties, "target")
+	assert.NotContains(t, parser.Properties, "optimize")
+
+	tool := byName["tool"]
+	assert.True(t, tool.IsService)
+	assert.Equal(t, []string{"tool"}, tool.Properties["executables"])
+	assert.NotContains(t, tool.Properties, "target")
+	assert.NotContains(t, tool.Properties, "optimize")
+	assert.Equal(t, "NewZigLibraryBuild", parser.BuilderFunction())
+}
+
+func TestZigProjectToBuild(t *testing.T) {
+	project := Project{
+		AppName:    "api",
+		ModulePath: "server",
+		MainFile:   "server/src/main.zig",
+		BuildType:  protos2.BuildType_Zig,
+		IsService:  true,
+		Properties: map[string][]string{"optimize": {"ReleaseSafe"}, "target": {"native-linux-musl"}},
+	}
+
+	build := project.ToBuild()
+	assert.Equal(t, container.Zig, build.BuildType)
+	assert.Equal(t, "api", build.Image)
+	assert.Equal(t, "server", build.Folder)
+	assert.Equal(t, "server/src/main.zig", build.File)
+	assert.Equal(t, "ReleaseSafe", build.Custom.String("optimize"))
+	assert.Equal(t, "native-linux-musl", build.Custom.String("target"))
+
+	project.IsService = false
+	assert.Empty(t, project.ToBuild().Image)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strings"
 	"sync"
 
 	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/logger"
+	"github.com/containifyci/engine-ci/pkg/report"
 	"github.com/containifyci/engine-ci/pkg/utils"
 )
 
@@ -254,6 +257,8 @@ func (bs *BuildSteps) Run(arg *container.Build, step ...string) BuildResult {
 	// The Run method should accept a context.Context parameter, and callers (e.g. Command.Run
 	// in cmd/build.go) should propagate one from the HTTP request or CLI signal handling.
 	ctx := context.Background()
+	// the step groups replace the group of the build while they run
+	defer logger.GitHubActions().StartGroup(arg.App)()
 	return bs.runAllMatchingBuilds(ctx, arg, step)
 }
 
@@ -271,7 +276,9 @@ func (bs *BuildSteps) runAllMatchingBuilds(ctx context.Context, arg *container.B
 			continue
 		}
 
+		// only the steps of the build are reported as skipped
 		if step != nil && buildCtx.build.Name() != step[0] {
+			report.Default().SkipStep(arg.App, buildCtx.build.Name())
 			continue
 		}
 
@@ -283,7 +290,11 @@ func (bs *BuildSteps) runAllMatchingBuilds(ctx context.Context, arg *container.B
 			go func(build BuildStep, arg container.Build) {
 				defer wg.Done()
 				slog.Debug("Starting async step", "step", build.Name())
+				arg.Step = build.Name()
+				scopeToken(&arg)
+				done := startStep(arg, build)
 				id, err := build.RunWithBuild(arg)
+				done(err)
 				ids.Add(id)
 				if err != nil {
 					slog.Error("Failed to run build step.", "error", err)
@@ -299,7 +310,12 @@ func (bs *BuildSteps) runAllMatchingBuilds(ctx context.Context, arg *container.B
 
 
 
-		id, err := buildCtx.build.RunWithBuild(*arg)
+		stepArg := *arg
+		stepArg.Step = buildCtx.build.Name()
+		scopeToken(&stepArg)
+		done := startStep(stepArg, buildCtx.build)
+		id, err := buildCtx.build.RunWithBuild(stepArg)
+		done(err)
 		ids.Add(id)
 
 		if err != nil {
@@ -338,6 +354,38 @@ func (bs *BuildSteps) runAllMatchingBuilds(ctx context.Context, arg *container.B
 	return BuildResult{IDs: ids.Get(), Loop: container.BuildContinue, Error: nil}
 }
 
+// scopeToken replaces the engine token of the step with a token
+// restricted to the keys the step is allowed to access.
+func scopeToken(arg *container.Build) {
+	if arg.StepToken == nil {
+		return
+	}
+	token := arg.StepToken(arg.Step)
+	logger.RegisterSecret(token)
+	secret := maps.Clone(arg.Secret)
+	if secret == nil {
+		secret = map[string]string{}
+	}
+	secret["CONTAINIFYCI_AUTH"] = token
+	arg.Secret = secret
+}
+
+// startStep records the step in the run report and groups its output in
+// GitHub Actions. The returned function has to be called with the step result.
+func startStep(arg container.Build, step BuildStep) func(err error) {
+	name := fmt.Sprintf("%s / %s", arg.App, step.Name())
+	gh := logger.GitHubActions()
+	endGroup := gh.StartGroup(name)
+	done := report.Default().StartStep(arg.App, step.Name(), step.Images(arg)...)
+	return func(err error) {
+		done(err)
+		endGroup()
+		if err != nil {
+			gh.Error(name+" failed", "", 0, 0, err.Error())
+		}
+	}
+}
+
 func (bs *BuildSteps) Images(groups container.BuildGroups) []string {
 	images := []string{}
 	for _, group := range groups {
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// BinDir is the folder next to the plugin that holds the compiled plugin binaries.
+const BinDir = ".bin"
+
+// binaryPrefix is the file name prefix of the compiled plugin binaries
+const binaryPrefix = "containifyci-"
+
+// Binary returns the path of the compiled plugin. The name contains a hash of
+// the plugin file, go.mod, go.sum, the local replacements and the Go version,
+// any change of them results in a new binary.
+func (p Plugin) Binary(ctx context.Context) (string, error) {
+	key, err := p.cacheKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	name := binaryPrefix + key[:16]
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(p.Dir, BinDir, name), nil
+}
+
+// Build compiles the plugin into BinDir unless the binary of the current
+// sources exists already and returns its path.
+func (p Plugin) Build(ctx context.Context) (string, error) {
+	bin, err := p.Binary(ctx)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(bin); err == nil {
+		slog.Debug("Using cached plugin binary", "plugin", p.Path(), "binary", bin)
+		return bin, nil
+	}
+
+	dir := filepath.Dir(bin)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create plugin binary folder: %w", err)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", ignore, err)
+		}
+	}
+
+	abs, err := filepath.Abs(bin)
+	if err != nil {
+		return "", err
+	}
+	slog.Info("Compiling plugin", "plugin", p.Path(), "binary", bin)
+
+	// build next to the binary and rename it, concurrent runs never start a partial binary
+	tmp := fmt.Sprintf("%s.%d.tmp", abs, os.Getpid())
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", "build", "-C", p.Dir, "-o", tmp, p.File)
+	cmd.Stdout = &stderr
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(tmp)
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return "", fmt.Errorf("failed to compile plugin %s: %w\n%s", p.Path(), err, output)
+		}
+		return "", fmt.Errorf("failed to compile plugin %s: %w", p.Path(), err)
+	}
+	if err := os.Rename(tmp, abs); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("failed to move plugin binary: %w", err)
+	}
+
+	removeStaleBinaries(dir, filepath.Base(bin))
+	return bin, nil
+}
+
+// removeStaleBinaries deletes the binaries of previous plugin sources
+func removeStaleBinaries(dir, current string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == current || !strings.HasPrefix(e.Name(), binaryPrefix) || strings.HasSuffix(e.Name(), ".tmp") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			slog.Debug("Failed to remove stale plugin binary", "file", e.Name(), "error", err)
+		}
+	}
+}
+
+// cacheKey hashes the inputs of the plugin compilation
+func (p Plugin) cacheKey(ctx context.Context) (string, error) {
+	h := sha256.New()
+
+	version, err := exec.CommandContext(ctx, "go", "env", "GOVERSION", "GOOS", "GOARCH").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the Go version: %w", err)
+	}
+	h.Write(version)
+
+	if err := hashFile(h, p.Path()); err != nil {
+		return "", fmt.Errorf("failed to read plugin %s: %w", p.Path(), err)
+	}
+
+	mod, err := FindModule(p.Dir)
+	if errors.Is(err, ErrNoModule) {
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Dir(mod.Path)
+	for _, file := range []string{mod.Path, filepath.Join(root, "go.sum")} {
+		if err := hashFile(h, file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	}
+
+	// the sources of local replacements are compiled into the plugin as well
+	for _, r := range mod.File.Replace {
+		if r.New.Version != "" {
+			continue
+		}
+		dir := r.New.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		if err := hashSources(h, dir); err != nil {
+			return "", fmt.Errorf("failed to read replacement %s: %w", r.Old.Path, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashSources hashes the Go sources and module files of the folder
+func hashSources(h io.Writer, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "testdata" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if (strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")) || name == "go.mod" || name == "go.sum" {
+			return hashFile(h, path)
+		}
+		return nil
+	})
+}
+
+// hashFile hashes the name and the content of the file
+func hashFile(h io.Writer, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "%s %d\n", filepath.ToSlash(file), len(data))
+	_, err = h.Write(data)
+	return err
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginBuild(t *testing.T) {
+	// the flags of the engine module do not apply to the plugin module
+	t.Setenv("GOFLAGS", "")
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	writeGoMod(t, dir, "module plugin\n\ngo 1.22\n")
+	plugin := Plugin{Dir: dir, File: "containifyci.go"}
+	require.NoError(t, os.WriteFile(plugin.Path(), []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	bin, err := plugin.Build(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, BinDir), filepath.Dir(bin))
+	assert.FileExists(t, bin)
+	assert.FileExists(t, filepath.Join(dir, BinDir, ".gitignore"))
+
+	// the cached binary is reused
+	info, err := os.Stat(bin)
+	require.NoError(t, err)
+	cached, err := plugin.Build(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, bin, cached)
+	again, err := os.Stat(cached)
+	require.NoError(t, err)
+	assert.Equal(t, info.ModTime(), again.ModTime())
+
+	// changed sources are compiled into a new binary
+	require.NoError(t, os.WriteFile(plugin.Path(), []byte("package main\n\nfunc main() { println() }\n"), 0644))
+	rebuilt, err := plugin.Build(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, bin, rebuilt)
+	assert.FileExists(t, rebuilt)
+	assert.NoFileExists(t, bin)
+
+	require.NoError(t, os.WriteFile(plugin.Path(), []byte("package main\n\nfunc main() { undefined() }\n"), 0644))
+	_, err = plugin.Build(ctx)
+	assert.ErrorContains(t, err, "undefined: undefined")
+}
+
+func TestPluginBinaryReplacements(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "plugin")
+	writeGoMod(t, dir, "module plugin\n\ngo 1.22\n\nreplace example.com/client => ../client\n")
+	writeGoMod(t, filepath.Join(root, "client"), "module example.com/client\n")
+	plugin := Plugin{Dir: dir, File: "containifyci.go"}
+	require.NoError(t, os.WriteFile(plugin.Path(), []byte("package main\n"), 0644))
+
+	bin, err := plugin.Binary(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "client", "build_test.go"), []byte("package client\n"), 0644))
+	unchanged, err := plugin.Binary(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, bin, unchanged)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "client", "build.go"), []byte("package client\n"), 0644))
+	changed, err := plugin.Binary(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, bin, changed)
+}
+
+func TestPluginCommandWorkingDir(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+
+	dir := t.TempDir()
+	writeGoMod(t, dir, "module plugin\n\ngo 1.22\n")
+	plugin := Plugin{Dir: dir, File: "containifyci.go"}
+	// the plugins change to the parent folder first, it has to be the repository root
+	source := `package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	wd, _ := os.Getwd()
+	if _, err := os.Stat(filepath.Join(wd, "containifyci.go")); err != nil {
+		fmt.Println("wrong working dir", wd)
+		os.Exit(1)
+	}
+	fmt.Print(wd)
+}
+`
+	require.NoError(t, os.WriteFile(plugin.Path(), []byte(source), 0644))
+
+	cmd, err := plugin.Command(context.Background())
+	require.NoError(t, err)
+	out, err := cmd.Output()
+	require.NoError(t, err, string(out))
+
+	expected, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	actual, err := filepath.EvalSymlinks(string(out))
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/cri"
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+// BuildGroups converts the build arguments of the plugin to the builds of the engine.
+func BuildGroups(opts []*protos2.BuildArgsGroup) container.BuildGroups {
+	groups := container.BuildGroups{}
+
+	for _, group := range opts {
+		g := &container.BuildGroup{}
+
+		for _, opt := range group.Args {
+			arg := container.Build{
+				App:            opt.Application,
+				BuildType:      container.BuildType(opt.BuildType.String()),
+				ContainerFiles: opt.ContainerFiles,
+				Env:            container.EnvType(opt.Environment.String()),
+				Image:          opt.Image,
+				ImageTag:       opt.ImageTag,
+				Registry:       opt.Registry,
+				Registries:     opt.Registries,
+				Repository:     opt.Repository,
+				Runtime:        cri.DetectContainerRuntime(),
+				File:           opt.File,
+				Folder:         opt.Folder,
+				SourcePackages: opt.SourcePackages,
+				SourceFiles:    opt.SourceFiles,
+				Organization:   opt.Organization,
+				Verbose:        opt.Verbose,
+			}
+
+			if opt.Properties != nil {
+				arg.Custom = make(map[string][]string)
+				for k, v := range opt.Properties {
+					arg.Custom[k] = make([]string, len(v.Values))
+					for i, l := range v.Values {
+						arg.Custom[k][i] = l.GetStringValue()
+					}
+				}
+			}
+
+			if opt.Platform != "" {
+				platform := types.Platform{
+					Host: types.ParsePlatform(opt.Platform),
+				}
+
+				platform.Container = types.GetContainerPlatform(platform.Host)
+				arg.Platform = platform
+			}
+
+			if len(opt.Secrets) > 0 {
+				m := make(map[string]*container.BuildSecret, len(opt.Secrets))
+				for _, s := range opt.Secrets {
+					m[s.Key] = container.NewBuildSecret(s)
+				}
+				arg.Secrets = m
+			}
+			arg.Defaults()
+			// args = append(args, &arg)
+			g.Builds = append(g.Builds, &arg)
+			// slog.Info("Build Arg", "arg", arg)
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
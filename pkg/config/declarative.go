@@ -0,0 +1,415 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containifyci/engine-ci/protos2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+)
+
+// DeclarativeFiles are the names of the declarative build configuration in lookup order.
+var DeclarativeFiles = []string{"containifyci.yaml", "containifyci.yml", "containifyci.json"}
+
+// Source provides the build groups of a build configuration.
+type Source interface {
+	Load(ctx context.Context) ([]*protos2.BuildArgsGroup, error)
+	Path() string
+	Exists() bool
+}
+
+// DefaultSource returns the build configuration configured with CONTAINIFYCI_FILE.
+// A missing containifyci.go is replaced by a containifyci.yaml, .yml or .json
+// in the same folder.
+func DefaultSource() Source {
+	plugin := DefaultPlugin()
+	if IsDeclarative(plugin.File) {
+		return Declarative{Dir: plugin.Dir, File: plugin.File}
+	}
+	if plugin.Exists() {
+		return plugin
+	}
+	for _, file := range DeclarativeFiles {
+		d := Declarative{Dir: plugin.Dir, File: file}
+		if d.Exists() {
+			return d
+		}
+	}
+	return plugin
+}
+
+// IsDeclarative reports whether the file is a YAML or JSON build configuration.
+func IsDeclarative(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// Declarative is the containifyci.yaml or containifyci.json build configuration.
+// The document is a list of BuildArgsGroup, the fields are named like in the
+// protos2 messages.
+type Declarative struct {
+	Dir  string
+	File string
+}
+
+// Path returns the path of the configuration file.
+func (d Declarative) Path() string {
+	return filepath.Join(d.Dir, d.File)
+}
+
+// Exists reports whether the configuration file exists.
+func (d Declarative) Exists() bool {
+	_, err := os.Stat(d.Path())
+	return err == nil
+}
+
+// Load reads and validates the configuration file.
+func (d Declarative) Load(_ context.Context) ([]*protos2.BuildArgsGroup, error) {
+	data, err := os.ReadFile(d.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build configuration: %w", err)
+	}
+	return ParseDeclarative(d.Path(), data)
+}
+
+// FileError is a problem at a location of the declarative build configuration.
+type FileError struct {
+	File    string
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// requiredFields are checked for every message of the type
+var requiredFields = map[protoreflect.FullName][]protoreflect.Name{
+	"protos2.BuildArgs":     {"Application"},
+	"protos2.Secret":        {"Key"},
+	"protos2.ContainerFile": {"Name", "Content"},
+}
+
+// ParseDeclarative decodes the YAML or JSON build configuration. All schema
+// problems are returned as FileError joined into one error.
+func ParseDeclarative(file string, data []byte) ([]*protos2.BuildArgsGroup, error) {
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		// tabs are only valid as whitespace in JSON but not as YAML indentation
+		data = bytes.ReplaceAll(data, []byte("\t"), []byte(" "))
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, &FileError{File: file, Line: 1, Column: 1, Message: "the build configuration is empty"}
+	}
+
+	d := &decoder{file: file}
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		d.errorf(root, "expected a list of build groups")
+		return nil, errors.Join(d.errs...)
+	}
+
+	groups := make([]*protos2.BuildArgsGroup, 0, len(root.Content))
+	for _, node := range root.Content {
+		group := &protos2.BuildArgsGroup{}
+		d.message(node, group.ProtoReflect())
+		groups = append(groups, group)
+	}
+	if len(d.errs) > 0 {
+		return nil, errors.Join(d.errs...)
+	}
+	return groups, nil
+}
+
+// decoder collects the problems while decoding the document into the messages
+type decoder struct {
+	file string
+	errs []error
+}
+
+func (d *decoder) errorf(n *yaml.Node, format string, args ...any) {
+	d.errs = append(d.errs, &FileError{
+		File:    d.file,
+		Line:    n.Line,
+		Column:  n.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *decoder) message(n *yaml.Node, m protoreflect.Message) {
+	desc := m.Descriptor()
+	if desc.FullName() == "google.protobuf.ListValue" {
+		d.listValue(n, m.Interface().(*structpb.ListValue))
+		return
+	}
+	if n.Kind != yaml.MappingNode {
+		d.errorf(n, "expected a mapping for %s", desc.Name())
+		return
+	}
+
+	fields := desc.Fields()
+	seen := map[protoreflect.Name]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		fd := findField(fields, key.Value)
+		if fd == nil {
+			d.errorf(key, "unknown field %q in %s, expected one of %s", key.Value, desc.Name(), fieldNames(fields))
+			continue
+		}
+		if seen[fd.Name()] {
+			d.errorf(key, "duplicate field %s", fd.Name())
+			continue
+		}
+		seen[fd.Name()] = true
+		d.field(value, m, fd)
+	}
+
+	for _, name := range requiredFields[desc.FullName()] {
+		if !seen[name] || !m.Has(fields.ByName(name)) {
+			d.errorf(n, "missing required field %s in %s", name, desc.Name())
+		}
+	}
+	if args, ok := m.Interface().(*protos2.BuildArgs); ok {
+		buildArgsDefaults(args, seen)
+	}
+}
+
+// buildArgsDefaults applies the defaults of the client NewServiceBuild to the unset fields
+func buildArgsDefaults(args *protos2.BuildArgs, seen map[protoreflect.Name]bool) {
+	if !seen["Environment"] {
+		args.Environment = protos2.EnvType_build
+		if os.Getenv("ENV") == "local" {
+			args.Environment = protos2.EnvType_local
+		}
+	}
+	if !seen["ImageTag"] {
+		args.ImageTag = os.Getenv("COMMIT_SHA")
+		if args.ImageTag == "" {
+			args.ImageTag = "local"
+		}
+	}
+}
+
+func (d *decoder) field(n *yaml.Node, m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsMap():
+		if n.Kind != yaml.MappingNode {
+			d.errorf(n, "expected a mapping for %s", fd.Name())
+			return
+		}
+		mp := m.Mutable(fd).Map()
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			mk := protoreflect.ValueOfString(key.Value).MapKey()
+			if mp.Has(mk) {
+				d.errorf(key, "duplicate key %q in %s", key.Value, fd.Name())
+				continue
+			}
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				v := mp.NewValue()
+				d.message(value, v.Message())
+				mp.Set(mk, v)
+			} else if v, ok := d.scalar(value, fd.MapValue()); ok {
+				mp.Set(mk, v)
+			}
+		}
+	case fd.IsList():
+		if n.Kind != yaml.SequenceNode {
+			d.errorf(n, "expected a list for %s", fd.Name())
+			return
+		}
+		list := m.Mutable(fd).List()
+		for _, item := range n.Content {
+			if fd.Kind() == protoreflect.MessageKind {
+				v := list.NewElement()
+				d.message(item, v.Message())
+				list.Append(v)
+			} else if v, ok := d.scalar(item, fd); ok {
+				list.Append(v)
+			}
+		}
+	case fd.Kind() == protoreflect.MessageKind:
+		d.message(n, m.Mutable(fd).Message())
+	default:
+		if v, ok := d.scalar(n, fd); ok {
+			m.Set(fd, v)
+		}
+	}
+}
+
+func (d *decoder) scalar(n *yaml.Node, fd protoreflect.FieldDescriptor) (protoreflect.Value, bool) {
+	if n.Kind != yaml.ScalarNode {
+		d.errorf(n, "expected a %s for %s", fd.Kind(), fd.Name())
+		return protoreflect.Value{}, false
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if n.Tag == "!!null" {
+			return protoreflect.ValueOfString(""), true
+		}
+		return protoreflect.ValueOfString(n.Value), true
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(n.Value)
+		if err != nil {
+			d.errorf(n, "expected true or false for %s but got %q", fd.Name(), n.Value)
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfBool(b), true
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := range values.Len() {
+			v := values.Get(i)
+			if strings.EqualFold(string(v.Name()), n.Value) {
+				return protoreflect.ValueOfEnum(v.Number()), true
+			}
+			names = append(names, string(v.Name()))
+		}
+		d.errorf(n, "invalid %s %q, expected one of %s", fd.Name(), n.Value, strings.Join(names, ", "))
+		return protoreflect.Value{}, false
+	default:
+		d.errorf(n, "unsupported field type %s for %s", fd.Kind(), fd.Name())
+		return protoreflect.Value{}, false
+	}
+}
+
+// listValue accepts a single string or a list of strings for the Properties values
+func (d *decoder) listValue(n *yaml.Node, list *structpb.ListValue) {
+	items := []*yaml.Node{n}
+	if n.Kind == yaml.SequenceNode {
+		items = n.Content
+	}
+	for _, item := range items {
+		if item.Kind != yaml.ScalarNode {
+			d.errorf(item, "expected a string or a list of strings")
+			continue
+		}
+		list.Values = append(list.Values, structpb.NewStringValue(item.Value))
+	}
+}
+
+// findField matches the proto field name case-insensitively
+func findField(fields protoreflect.FieldDescriptors, name string) protoreflect.FieldDescriptor {
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if strings.EqualFold(string(fd.Name()), name) || strings.EqualFold(fd.JSONName(), name) {
+			return fd
+		}
+	}
+	return nil
+}
+
+func fieldNames(fields protoreflect.FieldDescriptors) string {
+	names := make([]string, 0, fields.Len())
+	for i := range fields.Len() {
+		names = append(names, string(fields.Get(i).Name()))
+	}
+	return strings.Join(names, ", ")
+}
+
+// MarshalDeclarative encodes the build groups as containifyci.yaml document,
+// unset fields are omitted. Enums are always written because the unset
+// Environment is not loaded as its zero value.
+func MarshalDeclarative(groups []*protos2.BuildArgsGroup) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, group := range groups {
+		root.Content = append(root.Content, encodeMessage(group.ProtoReflect()))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to encode build configuration: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode build configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeMessage(m protoreflect.Message) *yaml.Node {
+	if list, ok := m.Interface().(*structpb.ListValue); ok {
+		n := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, v := range list.Values {
+			n.Content = append(n.Content, stringNode(v.GetStringValue()))
+		}
+		return n
+	}
+
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	fields := m.Descriptor().Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if !m.Has(fd) && (fd.Kind() != protoreflect.EnumKind || fd.IsList() || fd.IsMap()) {
+			continue
+		}
+		n.Content = append(n.Content, stringNode(string(fd.Name())), encodeField(m.Get(fd), fd))
+	}
+	return n
+}
+
+func encodeField(v protoreflect.Value, fd protoreflect.FieldDescriptor) *yaml.Node {
+	switch {
+	case fd.IsMap():
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		keys := []string{}
+		v.Map().Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k.String())
+			return true
+		})
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := v.Map().Get(protoreflect.ValueOfString(key).MapKey())
+			n.Content = append(n.Content, stringNode(key), encodeValue(value, fd.MapValue()))
+		}
+		return n
+	case fd.IsList():
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := range v.List().Len() {
+			n.Content = append(n.Content, encodeValue(v.List().Get(i), fd))
+		}
+		return n
+	default:
+		return encodeValue(v, fd)
+	}
+}
+
+func encodeValue(v protoreflect.Value, fd protoreflect.FieldDescriptor) *yaml.Node {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		return encodeMessage(v.Message())
+	case protoreflect.EnumKind:
+		return stringNode(string(fd.Enum().Values().ByNumber(v.Enum()).Name()))
+	case protoreflect.BoolKind:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v.Bool())}
+	default:
+		return stringNode(v.String())
+	}
+}
+
+func stringNode(value string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if strings.Contains(value, "\n") {
+		n.Style = yaml.LiteralStyle
+	}
+	return n
+}
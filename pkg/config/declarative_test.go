@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+const declarativeYAML = `
+- Args:
+    - Application: protos2
+      BuildType: golang
+      Folder: protos2
+      Properties:
+        goreleaser: "true"
+        tags: [integration, e2e]
+- Args:
+    - Application: engine-ci
+      BuildType: GoLang
+      Environment: local
+      Image: engine-ci
+      ImageTag: v1
+      Registry: containifyci
+      Verbose: true
+      Registries:
+        docker.io:
+          Username: user
+          Password: env:DOCKER_TOKEN
+      ContainerFiles:
+        build:
+          Name: golang-alpine
+          Content: FROM golang:alpine
+      Secrets:
+        - Key: NPM_TOKEN
+          Value: env:NPM_TOKEN
+`
+
+func TestParseDeclarative(t *testing.T) {
+	t.Setenv("COMMIT_SHA", "abc123")
+
+	groups, err := ParseDeclarative("containifyci.yaml", []byte(declarativeYAML))
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	lib := groups[0].Args[0]
+	assert.Equal(t, "protos2", lib.Application)
+	assert.Equal(t, protos2.BuildType_GoLang, lib.BuildType)
+	assert.Equal(t, protos2.EnvType_build, lib.Environment)
+	assert.Equal(t, "abc123", lib.ImageTag)
+	assert.Equal(t, []any{"true"}, lib.Properties["goreleaser"].AsSlice())
+	assert.Equal(t, []any{"integration", "e2e"}, lib.Properties["tags"].AsSlice())
+
+	engine := groups[1].Args[0]
+	assert.Equal(t, protos2.EnvType_local, engine.Environment)
+	assert.Equal(t, "v1", engine.ImageTag)
+	assert.True(t, engine.Verbose)
+	assert.Equal(t, "env:DOCKER_TOKEN", engine.Registries["docker.io"].Password)
+	assert.Equal(t, "FROM golang:alpine", engine.ContainerFiles["build"].Content)
+	require.Len(t, engine.Secrets, 1)
+	assert.Equal(t, "NPM_TOKEN", engine.Secrets[0].Key)
+	assert.Equal(t, "env:NPM_TOKEN", engine.Secrets[0].Value)
+}
+
+func TestParseDeclarativeJSON(t *testing.T) {
+	data := "[\n\t{\n\t\t\"Args\": [\n\t\t\t{\"Application\": \"app\", \"BuildType\": \"Python\", \"Properties\": {\"tags\": [\"a\"]}}\n\t\t]\n\t}\n]\n"
+
+	groups, err := ParseDeclarative("containifyci.json", []byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "app", groups[0].Args[0].Application)
+	assert.Equal(t, protos2.BuildType_Python, groups[0].Args[0].BuildType)
+}
+
+func TestParseDeclarativeErrors(t *testing.T) {
+	data := `- Args:
+    - Application: app
+      BuildType: Cobol
+      Imag: app
+    - Folder: lib
+      Verbose: maybe
+`
+
+	_, err := ParseDeclarative("containifyci.yaml", []byte(data))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `containifyci.yaml:3:18: invalid BuildType "Cobol", expected one of GoLang,`)
+	assert.Contains(t, err.Error(), `containifyci.yaml:4:7: unknown field "Imag" in BuildArgs, expected one of`)
+	assert.Contains(t, err.Error(), `containifyci.yaml:6:16: expected true or false for Verbose but got "maybe"`)
+	assert.Contains(t, err.Error(), "containifyci.yaml:5:7: missing required field Application in BuildArgs")
+
+	var fileErr *FileError
+	require.ErrorAs(t, err, &fileErr)
+	assert.Equal(t, "containifyci.yaml", fileErr.File)
+
+	_, err = ParseDeclarative("containifyci.yaml", []byte("Args: []\n"))
+	assert.EqualError(t, err, "containifyci.yaml:1:1: expected a list of build groups")
+}
+
+func TestMarshalDeclarative(t *testing.T) {
+	groups, err := ParseDeclarative("containifyci.yaml", []byte(declarativeYAML))
+	require.NoError(t, err)
+
+	data, err := MarshalDeclarative(groups)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "tags: [integration, e2e]")
+	assert.Contains(t, string(data), "BuildType: GoLang")
+
+	parsed, err := ParseDeclarative("containifyci.yaml", data)
+	require.NoError(t, err)
+	require.Len(t, parsed, len(groups))
+	for i := range groups {
+		assert.True(t, proto.Equal(groups[i], parsed[i]), "group %d differs after the round trip", i)
+	}
+}
+
+func TestDefaultSource(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CONTAINIFYCI_FILE", filepath.Join(dir, "containifyci.go"))
+
+	assert.IsType(t, Plugin{}, DefaultSource())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "containifyci.yaml"), []byte(declarativeYAML), 0644))
+	assert.Equal(t, Declarative{Dir: dir, File: "containifyci.yaml"}, DefaultSource())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "containifyci.go"), []byte("package main"), 0644))
+	assert.IsType(t, Plugin{}, DefaultSource())
+
+	t.Setenv("CONTAINIFYCI_FILE", filepath.Join(dir, "build.yml"))
+	assert.Equal(t, Declarative{Dir: dir, File: "build.yml"}, DefaultSource())
+}
@@ -0,0 +1,170 @@
+// Package config loads the build configuration from the containifyci.go plugin
+// or the declarative containifyci.yaml.
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+)
+
+// DefaultStartTimeout bounds the start and the handshake of the plugin.
+const DefaultStartTimeout = 5 * time.Minute
+
+// Plugin is the containifyci.go build configuration. It is compiled once into
+// BinDir and the binary is reused until its sources change.
+type Plugin struct {
+	// Stderr receives the compiler and plugin output, defaults to os.Stderr.
+	Stderr io.Writer
+	Dir    string
+	File   string
+}
+
+// DefaultPlugin returns the plugin configured with CONTAINIFYCI_FILE, by
+// default containifyci.go in the current folder.
+func DefaultPlugin() Plugin {
+	file := os.Getenv("CONTAINIFYCI_FILE")
+	if file == "" {
+		return Plugin{Dir: ".", File: "containifyci.go"}
+	}
+	return Plugin{Dir: filepath.Dir(file), File: filepath.Base(file)}
+}
+
+// Path returns the path of the plugin file.
+func (p Plugin) Path() string {
+	return filepath.Join(p.Dir, p.File)
+}
+
+// Exists reports whether the plugin file exists.
+func (p Plugin) Exists() bool {
+	_, err := os.Stat(p.Path())
+	return err == nil
+}
+
+// Command returns the command that launches the compiled plugin. The plugin
+// runs in its folder like with go run -C, plugins resolve the build folders
+// relative to it.
+func (p Plugin) Command(ctx context.Context) (*exec.Cmd, error) {
+	bin, err := p.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(bin)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(abs)
+	cmd.Dir = p.Dir
+	return cmd, nil
+}
+
+// Load launches the plugin, performs the go-plugin handshake and returns the
+// build groups. The context deadline bounds the compilation, the start and
+// the GetBuilds call.
+func (p Plugin) Load(ctx context.Context) ([]*protos2.BuildArgsGroup, error) {
+	cmd, err := p.Command(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := p.Stderr
+	if out == nil {
+		out = os.Stderr
+	}
+	logger := hclog.New(&hclog.LoggerOptions{
+		Level:           hclog.Error,
+		Output:          out,
+		IncludeLocation: true,
+	})
+
+	// keep the plugin output to report start errors
+	var stderr bytes.Buffer
+
+	startTimeout := DefaultStartTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		startTimeout = time.Until(deadline)
+	}
+
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  protos2.Handshake,
+		VersionedPlugins: protos2.PluginMap,
+		Stderr:           io.MultiWriter(out, &stderr),
+		Cmd:              cmd,
+		StartTimeout:     startTimeout,
+		AllowedProtocols: []plugin.Protocol{
+			plugin.ProtocolNetRPC,
+			plugin.ProtocolGRPC,
+		},
+		Logger: logger,
+	})
+	defer client.Kill()
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return nil, fmt.Errorf("failed to start plugin %s: %w\n%s", p.Path(), err, output)
+		}
+		return nil, fmt.Errorf("failed to start plugin %s: %w", p.Path(), err)
+	}
+
+	raw, err := rpcClient.Dispense("containifyci")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dispense plugin %s: %w", p.Path(), err)
+	}
+
+	type response struct {
+		groups []*protos2.BuildArgsGroup
+		err    error
+	}
+	ch := make(chan response, 1)
+	go func() {
+		groups, err := GetBuilds(raw)
+		ch <- response{groups, err}
+	}()
+
+	select {
+	case resp := <-ch:
+		return resp.groups, resp.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("plugin %s did not return the builds: %w", p.Path(), ctx.Err())
+	}
+}
+
+// GetBuilds calls the plugin, v1 plugins return one group per build.
+func GetBuilds(raw any) ([]*protos2.BuildArgsGroup, error) {
+	if containifyci, ok := raw.(protos2.ContainifyCIv2); ok {
+		resp, err := containifyci.GetBuilds()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get builds from plugin: %w", err)
+		}
+		return resp.Args, nil
+	}
+
+	containifyci, ok := raw.(protos2.ContainifyCIv1)
+	if !ok {
+		return nil, errors.New("plugin implements neither the v1 nor the v2 interface")
+	}
+	resp, err := containifyci.GetBuild()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get build from plugin: %w", err)
+	}
+
+	groups := []*protos2.BuildArgsGroup{}
+	for _, a := range resp.Args {
+		groups = append(groups, &protos2.BuildArgsGroup{
+			Args: []*protos2.BuildArgs{a},
+		})
+	}
+	return groups, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+// Validate returns the problems of the build arguments of the plugin.
+// The relative paths are resolved from the current folder like during a build.
+func Validate(groups []*protos2.BuildArgsGroup) map[*protos2.BuildArgs][]string {
+	problems := map[*protos2.BuildArgs][]string{}
+	apps := map[string]int{}
+	for _, group := range groups {
+		for _, arg := range group.Args {
+			apps[arg.Application]++
+		}
+	}
+
+	for _, group := range groups {
+		for _, arg := range group.Args {
+			p := ValidateArgs(arg)
+			if arg.Application != "" && apps[arg.Application] > 1 {
+				p = append(p, fmt.Sprintf("Application %s is defined %d times", arg.Application, apps[arg.Application]))
+			}
+			if len(p) > 0 {
+				problems[arg] = p
+			}
+		}
+	}
+	return problems
+}
+
+// ValidateArgs returns the problems of a single build.
+func ValidateArgs(arg *protos2.BuildArgs) []string {
+	problems := []string{}
+	if arg.Application == "" {
+		problems = append(problems, "Application is empty")
+	}
+
+	folder := arg.Folder
+	if folder == "" {
+		folder = "."
+	}
+	if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+		problems = append(problems, fmt.Sprintf("Folder %s does not exist", folder))
+	}
+
+	if arg.File != "" && !exists(arg.File) && !exists(filepath.Join(folder, arg.File)) {
+		problems = append(problems, fmt.Sprintf("File %s does not exist", arg.File))
+	}
+
+	if arg.Platform != "" && types.ParsePlatform(arg.Platform) == nil {
+		problems = append(problems, fmt.Sprintf("Platform %s is not in the os/arch format", arg.Platform))
+	}
+
+	for _, s := range arg.Secrets {
+		if s.Key == "" {
+			problems = append(problems, "Secret without key")
+		}
+	}
+	for host, reg := range arg.Registries {
+		if reg == nil || reg.Password == "" {
+			problems = append(problems, fmt.Sprintf("Registry %s has no password", host))
+		}
+	}
+	return problems
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch"), 0644))
+
+	valid := &protos2.BuildArgs{Application: "app", Folder: dir, File: "Dockerfile", Platform: "linux/amd64"}
+	broken := &protos2.BuildArgs{
+		Folder:   filepath.Join(dir, "missing"),
+		File:     "main.go",
+		Platform: "amd64",
+		Secrets:  []*protos2.Secret{{}},
+		Registries: map[string]*protos2.ContainerRegistry{
+			"docker.io": {Username: "user"},
+		},
+	}
+	first := &protos2.BuildArgs{Application: "dup", Folder: dir}
+	second := &protos2.BuildArgs{Application: "dup", Folder: dir}
+
+	problems := Validate([]*protos2.BuildArgsGroup{
+		{Args: []*protos2.BuildArgs{valid, broken}},
+		{Args: []*protos2.BuildArgs{first, second}},
+	})
+
+	assert.NotContains(t, problems, valid)
+	assert.ElementsMatch(t, []string{
+		"Application is empty",
+		"Folder " + filepath.Join(dir, "missing") + " does not exist",
+		"File main.go does not exist",
+		"Platform amd64 is not in the os/arch format",
+		"Secret without key",
+		"Registry docker.io has no password",
+	}, problems[broken])
+	assert.Equal(t, []string{"Application dup is defined 2 times"}, problems[first])
+	assert.Equal(t, []string{"Application dup is defined 2 times"}, problems[second])
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	Protos2Module = "github.com/containifyci/engine-ci/protos2"
+	ClientModule  = "github.com/containifyci/engine-ci/client"
+)
+
+// ErrNoModule is returned when no go.mod is found for the plugin.
+var ErrNoModule = errors.New("go.mod not found")
+
+// Module is the go.mod the plugin is compiled with.
+type Module struct {
+	File *modfile.File
+	Path string
+}
+
+// FindModule looks up the go.mod of the plugin folder like the go command,
+// walking up the parent folders.
+func FindModule(dir string) (*Module, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, "go.mod")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			f, err := modfile.Parse(path, data, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			return &Module{Path: path, File: f}, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNoModule
+		}
+		dir = parent
+	}
+}
+
+// GoVersion returns the go directive of the module.
+func (m *Module) GoVersion() string {
+	if m.File.Go == nil {
+		return ""
+	}
+	return m.File.Go.Version
+}
+
+// Require returns the required version of the module path, a local
+// replacement returns an empty version.
+func (m *Module) Require(path string) (string, bool) {
+	for _, r := range m.File.Replace {
+		if r.Old.Path == path {
+			return r.New.Version, true
+		}
+	}
+	for _, r := range m.File.Require {
+		if r.Mod.Path == path {
+			return r.Mod.Version, true
+		}
+	}
+	return "", false
+}
+
+// EngineProtos2Version returns the protos2 version the running engine is
+// built with, empty when unknown.
+func EngineProtos2Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != Protos2Module {
+			continue
+		}
+		if dep.Replace != nil {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return ""
+}
+
+// VersionProblem is a plugin dependency that does not match the running engine.
+type VersionProblem struct {
+	Module   string
+	Version  string
+	Expected string
+	Message  string
+	// Incompatible problems break the handshake or the build arguments,
+	// the others are outdated versions.
+	Incompatible bool
+}
+
+// CheckVersions compares the protos2 version of the plugin module and the
+// protos2 version required by its client with the protos2 version of the
+// engine. The go.mod of the client is read from the module cache.
+func CheckVersions(m *Module, protos2, modCache string) []VersionProblem {
+	problems := []VersionProblem{}
+
+	p2, hasProtos2 := m.Require(Protos2Module)
+	client, hasClient := m.Require(ClientModule)
+	if !hasProtos2 && !hasClient {
+		return append(problems, VersionProblem{
+			Module:       Protos2Module,
+			Message:      fmt.Sprintf("%s requires neither %s nor %s", m.Path, ClientModule, Protos2Module),
+			Incompatible: true,
+		})
+	}
+
+	if hasProtos2 {
+		if p := compareVersion(Protos2Module, p2, protos2); p != nil {
+			problems = append(problems, *p)
+		}
+	}
+	if hasClient {
+		if p2, err := clientProtos2(modCache, client); err == nil {
+			if p := compareVersion(Protos2Module, p2, protos2); p != nil {
+				p.Module = ClientModule
+				p.Version = client
+				p.Message = fmt.Sprintf("%s %s requires %s %s, the engine uses %s", ClientModule, client, Protos2Module, p2, protos2)
+				problems = append(problems, *p)
+			}
+		}
+	}
+	return problems
+}
+
+// clientProtos2 returns the protos2 version required by the client version.
+func clientProtos2(modCache, version string) (string, error) {
+	if modCache == "" || !semver.IsValid(version) {
+		return "", fmt.Errorf("client %s not in the module cache", version)
+	}
+	path, err := module.EscapePath(ClientModule)
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(modCache, "cache", "download", path, "@v", version+".mod")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseLax(file, data, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path == Protos2Module {
+			return r.Mod.Version, nil
+		}
+	}
+	return "", fmt.Errorf("client %s does not require %s", version, Protos2Module)
+}
+
+// compareVersion reports a different major version or a version newer than
+// the engine as incompatible and an older minor version as outdated.
+func compareVersion(path, version, expected string) *VersionProblem {
+	if !semver.IsValid(version) || !semver.IsValid(expected) {
+		return nil
+	}
+	problem := &VersionProblem{Module: path, Version: version, Expected: expected}
+	switch {
+	case semver.Major(version) != semver.Major(expected):
+		problem.Incompatible = true
+		problem.Message = fmt.Sprintf("%s %s has a different major version than the engine %s", path, version, expected)
+	case semver.Compare(semver.MajorMinor(version), semver.MajorMinor(expected)) > 0:
+		problem.Incompatible = true
+		problem.Message = fmt.Sprintf("%s %s is newer than the engine %s", path, version, expected)
+	case semver.MajorMinor(version) != semver.MajorMinor(expected):
+		problem.Message = fmt.Sprintf("%s %s is older than the engine %s", path, version, expected)
+	default:
+		return nil
+	}
+	return problem
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGoMod(t *testing.T, dir, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0644))
+}
+
+func TestFindModule(t *testing.T) {
+	root := t.TempDir()
+	writeGoMod(t, root, "module example.com/app\n\ngo 1.25.5\n")
+	plugin := filepath.Join(root, ".containifyci")
+	require.NoError(t, os.MkdirAll(plugin, 0755))
+
+	mod, err := FindModule(plugin)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "go.mod"), mod.Path)
+	assert.Equal(t, "1.25.5", mod.GoVersion())
+}
+
+func TestCheckVersions(t *testing.T) {
+	tests := []struct {
+		name         string
+		protos2      string
+		problem      string
+		incompatible bool
+	}{
+		{name: "same minor", protos2: "v0.27.3"},
+		{name: "older minor", protos2: "v0.26.6", problem: Protos2Module + " v0.26.6 is older than the engine v0.27.0"},
+		{name: "newer minor", protos2: "v0.28.0", problem: Protos2Module + " v0.28.0 is newer than the engine v0.27.0", incompatible: true},
+		{name: "major", protos2: "v1.0.0", problem: Protos2Module + " v1.0.0 has a different major version than the engine v0.27.0", incompatible: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeGoMod(t, dir, "module containifyci\n\ngo 1.25.5\n\nrequire "+Protos2Module+" "+tt.protos2+"\n")
+			mod, err := FindModule(dir)
+			require.NoError(t, err)
+
+			problems := CheckVersions(mod, "v0.27.0", "")
+			if tt.problem == "" {
+				assert.Empty(t, problems)
+				return
+			}
+			require.Len(t, problems, 1)
+			assert.Equal(t, tt.problem, problems[0].Message)
+			assert.Equal(t, tt.incompatible, problems[0].Incompatible)
+		})
+	}
+}
+
+func TestCheckVersions_Client(t *testing.T) {
+	cache := t.TempDir()
+	versions := filepath.Join(cache, "cache", "download", "github.com", "containifyci", "engine-ci", "client", "@v")
+	require.NoError(t, os.MkdirAll(versions, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(versions, "v0.40.0.mod"),
+		[]byte("module "+ClientModule+"\n\nrequire "+Protos2Module+" v0.29.0\n"), 0644))
+
+	dir := t.TempDir()
+	writeGoMod(t, dir, "module containifyci\n\ngo 1.25.5\n\nrequire "+ClientModule+" v0.40.0\n")
+	mod, err := FindModule(dir)
+	require.NoError(t, err)
+
+	problems := CheckVersions(mod, "v0.27.0", cache)
+	require.Len(t, problems, 1)
+	assert.Equal(t, ClientModule, problems[0].Module)
+	assert.True(t, problems[0].Incompatible)
+	assert.Contains(t, problems[0].Message, "requires "+Protos2Module+" v0.29.0")
+
+	writeGoMod(t, dir, "module containifyci\n\ngo 1.25.5\n")
+	mod, err = FindModule(dir)
+	require.NoError(t, err)
+	problems = CheckVersions(mod, "v0.27.0", cache)
+	require.Len(t, problems, 1)
+	assert.True(t, problems[0].Incompatible)
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/containifyci/engine-ci/pkg/cri"
 	"github.com/containifyci/engine-ci/pkg/cri/types"
@@ -74,6 +75,19 @@ func (c Custom) Strings(key string) []string {
 	return nil
 }
 
+// List returns the values of the key, comma separated values are split.
+func (c Custom) List(key string) []string {
+	var list []string
+	for _, v := range c.Strings(key) {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
 func (c Custom) Bool(key string, value bool) bool {
 	if v, ok := c[key]; ok {
 		return v[0] == "true"
@@ -121,6 +135,7 @@ type Build struct {
 	ContainifyRegistry string
 	Runtime            utils.RuntimeType
 	RuntimeClient      func() cri.ContainerManager `json:"-"`
+	StepToken          func(step string) string    `json:"-"`
 	Image              string                      `json:"image"`
 	ImageTag           string                      `json:"image_tag"`
 	File               string
@@ -129,6 +144,7 @@ type Build struct {
 	Repository         string
 	Organization       string
 	App                string    `json:"app"`
+	Step               string    `json:"step,omitempty"`
 	BuildType          BuildType `json:"build_type"`
 	BuilderFunction    string
 	Registry           string
@@ -175,6 +191,27 @@ func (b *Build) ImageURI() string {
 	return result
 }
 
+// ProdPlatforms returns the platforms of the multi-arch prod image
+// configured with the prod_platforms property.
+func (b *Build) ProdPlatforms() []string {
+	return b.Custom.List("prod_platforms")
+}
+
+// DefaultStepTokenTTL bounds the lifetime of the step tokens, they are
+// minted when the step starts.
+const DefaultStepTokenTTL = time.Hour
+
+// StepTokenTTL returns the step_token_ttl property or the DefaultStepTokenTTL.
+func (b *Build) StepTokenTTL() time.Duration {
+	if v := b.Custom.String("step_token_ttl"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		slog.Warn("Invalid step_token_ttl property", "value", v)
+	}
+	return DefaultStepTokenTTL
+}
+
 // TODO move to containifyci
 func getEnv() EnvType {
 	env := os.Getenv("ENV")
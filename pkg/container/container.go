@@ -18,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/containifyci/engine-ci/pkg/cri"
 	"github.com/containifyci/engine-ci/pkg/logger"
 	"github.com/containifyci/engine-ci/pkg/memory"
+	"github.com/containifyci/engine-ci/pkg/report"
 
 	"github.com/containifyci/engine-ci/pkg/cri/types"
 	"github.com/containifyci/engine-ci/pkg/cri/utils"
@@ -95,6 +97,11 @@ func GetEnv(key string) string {
 	return u.GetEnv(key, string(BuildEnv))
 }
 
+// GetSecret returns the environment variable and redacts its value from the log output.
+func GetSecret(key string) string {
+	return u.GetSecret(key, string(BuildEnv))
+}
+
 func GetEnvs(key ...string) string {
 	return u.GetEnvs(key, string(BuildEnv))
 }
@@ -120,6 +127,10 @@ func New(build Build) *Container {
 	}
 
 	// Use background context with reasonable timeout instead of TODO
+	for _, secret := range build.Secret {
+		logger.RegisterSecret(secret)
+	}
+
 	ctx := context.Background()
 	container := &Container{t: t{client: _client, ctx: ctx}, Env: build.Env, Build: &build, Secret: build.Secret, Verbose: build.Verbose, StreamLogs: true}
 
@@ -166,6 +177,7 @@ func (c *Container) Create(opts types.ContainerConfig) error {
 
 				short := fmt.Sprintf("%s:%s", img, safeShort(tag, 8))
 				c.Prefix = fmt.Sprintf("[%s (%s)]", c.ID[:6], short)
+				c.setLogFields()
 				return nil
 			}
 		}
@@ -195,6 +207,7 @@ func (c *Container) Create(opts types.ContainerConfig) error {
 		os.Exit(1)
 	}
 	c.ID = id
+	c.publish(report.Event{Type: report.EventContainerCreated, Container: id, Image: opts.Image})
 
 	info, err := c.client().InspectContainer(c.ctx, c.ID)
 	if err != nil {
@@ -207,6 +220,7 @@ func (c *Container) Create(opts types.ContainerConfig) error {
 
 	short := fmt.Sprintf("%s:%s", img, safeShort(tag, 8))
 	c.Prefix = fmt.Sprintf("[%s (%s)]", c.ID[:6], short)
+	c.setLogFields()
 	return err
 }
 
@@ -233,6 +247,27 @@ func (c *Container) Start() error {
 	return err
 }
 
+// publish sends the event with the app and step of the build.
+func (c *Container) publish(e report.Event) {
+	if b := c.GetBuild(); b != nil {
+		e.App = b.App
+		if b.Step != "" {
+			e.Step = &report.Step{Name: b.Step}
+		}
+	}
+	report.Default().Publish(e)
+}
+
+// setLogFields correlates the container output with the build step.
+func (c *Container) setLogFields() {
+	fields := logger.Fields{Container: c.ID, Image: c.Image}
+	if b := c.GetBuild(); b != nil {
+		fields.App = b.App
+		fields.Step = b.Step
+	}
+	logger.GetLogAggregator().SetFields(c.Prefix, fields)
+}
+
 func safeShort(str string, end int) string {
 	if end > len(str) {
 		end = len(str)
@@ -294,6 +329,14 @@ func (c *Container) Commit(imageTag string, comment string, changes ...string) (
 	return id, err
 }
 
+// CommitProd commits the prod image with the OCI labels of the build.
+func (c *Container) CommitProd(imageTag string, comment string, changes ...string) (string, error) {
+	if b := c.GetBuild(); b != nil {
+		changes = append(changes, LabelChanges(b.Labels())...)
+	}
+	return c.Commit(imageTag, comment, changes...)
+}
+
 // TODO: ignore hidden folder and files maybe support .dockerignore file or more .dockerinclude file to
 // include folder and files that are ignored by default
 func (c *Container) CopyDirectoryTo(srcPath, dstPath string) error {
@@ -317,7 +360,7 @@ func (c *Container) Exec(cmd ...string) error {
 		return err
 	}
 	// Demultiplex stdout/stderr and write to os.Stdout
-	_, err = io.Copy(os.Stdout, reader)
+	err = copyOutput(reader)
 	if err != nil {
 		slog.Error("Failed to copy output", "error", err)
 	}
@@ -404,6 +447,7 @@ func (c *Container) ensureImagesExists(ctx context.Context, cli cri.ContainerMan
 			if err != nil {
 				return err
 			}
+			c.publish(report.Event{Type: report.EventImagePulled, Image: imageName})
 		} else {
 			info, err := cli.InspectImage(ctx, imageName)
 			if err != nil {
@@ -424,6 +468,7 @@ func (c *Container) ensureImagesExists(ctx context.Context, cli cri.ContainerMan
 					slog.Error("Failed to copy stdout", "error", err)
 					return err
 				}
+				c.publish(report.Event{Type: report.EventImagePulled, Image: imageName})
 				return nil
 			}
 			slog.Debug("Image found locally.\n", "image", imageName, "platform", info.Platform.String())
@@ -442,11 +487,11 @@ func (c *Container) registryAuthBase64(imageName string) string {
 	}
 
 	if reg, ok := c.GetBuild().Registries[imgInfo.Server]; ok {
-		username := u.GetValue(reg.Username, c.GetBuild().Env.String())
+		username := u.GetBuildValue(c.GetBuild().App, reg.Username, c.GetBuild().Env.String())
 		slog.Debug("Registry auth found for image", "image", imageName, "server", imgInfo.Server, "username", username)
 		authConfig := registry.AuthConfig{
 			Username:      username,
-			Password:      u.GetValue(reg.Password, c.GetBuild().Env.String()),
+			Password:      u.GetSecretValue(c.GetBuild().App, reg.Password, c.GetBuild().Env.String()),
 			ServerAddress: imgInfo.Server, // Server address for GCR
 		}
 		return c.encodeAuthToBase64(authConfig)
@@ -493,6 +538,7 @@ func (c *Container) Push(source, target string, opts ...PushOption) error {
 		slog.Error("Failed to copy output", "error", err)
 		return err
 	}
+	report.Default().AddPushed(c.GetBuild().App, target)
 	if opts[0].Remove {
 		return c.client().RemoveImage(c.ctx, target)
 	}
@@ -544,7 +590,7 @@ func waitForApplication(ctx context.Context, readiness *types.ReadinessProbe) er
 				}
 			}
 
-			fmt.Printf("Waiting for application to start: HTTP status code %d\n", resp.StatusCode)
+			slog.Info("Waiting for application to start", "status", resp.StatusCode)
 		}
 	}
 }
@@ -562,31 +608,38 @@ func (c *Container) BuildImageByPlatforms(dockerfile []byte, dockerCtx *bytes.Bu
 	defer reader.Close()
 
 	// Read the build output
-	_, err = io.Copy(os.Stdout, reader)
+	err = copyOutput(reader)
 	if err != nil {
 		return nil, err
 	}
 	return imageIds, err
 }
 
-func (c *Container) BuildImageByPlatform(dockerfile []byte, imageName string, platform string) error {
-	// return nil
-	reader, err := c.client().BuildImage(c.ctx, dockerfile, imageName, platform)
+// BuildImageByPlatform builds the image locally, dockerCtx is the optional build context as tar.
+func (c *Container) BuildImageByPlatform(dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) error {
+	reader, err := c.client().BuildImage(c.ctx, dockerfile, dockerCtx, imageName, platform)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
 
 	// Read the build output
-	_, err = io.Copy(os.Stdout, reader)
+	err = copyOutput(reader)
 	if err != nil {
 		return err
 	}
 	return err
 }
 
+// copyOutput logs the output line by line through the log aggregator, it
+// redacts the secrets and keeps the json and tui output intact.
+func copyOutput(r io.Reader) error {
+	_, err := logger.GetLogAggregator().Copy(io.NopCloser(r))
+	return err
+}
+
 func (c *Container) BuildImage(dockerfile []byte, imageName string) error {
-	return c.BuildImageByPlatform(dockerfile, imageName, c.GetBuild().Platform.Container.String())
+	return c.BuildImageByPlatform(dockerfile, nil, imageName, c.GetBuild().Platform.Container.String())
 }
 
 // imageExists checks if the image with the specified tag exists.
@@ -687,7 +740,7 @@ func (c *Container) BuildingContainer(opts types.ContainerConfig) error {
 		var buf bytes.Buffer
 		buf.WriteString("#!/bin/sh\nset +xe\n")
 		for _, secret := range secrets {
-			v := u.GetEnv(secret, "build")
+			v := u.GetSecret(secret, "build")
 			fmt.Fprintf(&buf, "export %s=%s\n", secret, v)
 		}
 		err = c.CopyContentTo(buf.String(), "/tmp/secrets.sh")
@@ -795,9 +848,10 @@ func (c *Container) BuildIntermidiateContainer(image string, dockerFile []byte,
 	return err
 }
 
-// BuildCustomProdImage builds a custom prod Dockerfile using BuildIntermidiateContainer and pushes it.
+// BuildCustomProdImage builds a custom prod Dockerfile using BuildIntermidiateContainer and exports or pushes it.
 // Returns the image tag (name:tag) and nil on success, or empty string and the error on failure.
 // If no custom prod Dockerfile is set, returns ("", nil) — this is not an error.
+// With prod_platforms set the image is built for all platforms and pushed as manifest list.
 func (c *Container) BuildCustomProdImage() (string, error) {
 	b := c.GetBuild()
 	v, ok := b.ContainerFiles["prod"]
@@ -807,22 +861,127 @@ func (c *Container) BuildCustomProdImage() (string, error) {
 
 	slog.Info("Using custom prod Dockerfile", "name", v.Name)
 	image := fmt.Sprintf("%s:%s", b.Image, b.ImageTag)
+	dockerfile := WithLabels(v.Content, b.Labels())
+
+	if platforms := b.PushPlatforms(); len(platforms) > 0 {
+		src := c.Source
+		if src == nil {
+			src = os.DirFS(b.Folder).(fs.ReadDirFS)
+		}
+		dockerCtx, err := TarDir(src)
+		if err != nil {
+			return "", fmt.Errorf("tar prod image context: %w", err)
+		}
+		imageUri := utils.ImageURI(b.Registry, b.Image, b.ImageTag)
+		slog.Info("Building multi-arch prod image", "image", imageUri, "platforms", platforms)
+		if _, err := c.BuildImageByPlatforms([]byte(dockerfile), dockerCtx, imageUri, platforms); err != nil {
+			return "", fmt.Errorf("build multi-arch prod image from custom Dockerfile: %w", err)
+		}
+		return imageUri, nil
+	}
 
 	platforms := types.GetPlatforms(b.Platform)
-	err := c.BuildIntermidiateContainer(image, []byte(v.Content), platforms...)
+	err := c.BuildIntermidiateContainer(image, []byte(dockerfile), platforms...)
 	if err != nil {
 		return "", fmt.Errorf("build prod image from custom Dockerfile: %w", err)
 	}
 
-	imageUri := b.ImageURI()
-	err = c.Push(image, imageUri, PushOption{Remove: false})
-	if err != nil {
+	if err := c.ExportOrPush(image, PushOption{Remove: false}); err != nil {
 		return "", fmt.Errorf("push prod image: %w", err)
 	}
 
 	return image, nil
 }
 
+// BuildProdImageByPlatforms builds the prod image for each platform from the Dockerfile
+// and pushes it as manifest list to the registry. The files map the build context
+// names to the local files, e.g. the per platform binaries.
+func (c *Container) BuildProdImageByPlatforms(dockerfile string, files map[string]string, platforms []string) (string, error) {
+	b := c.GetBuild()
+	if !b.ShouldPush() {
+		slog.Info("Skip multi-arch prod image it can only be built when pushing", "platforms", platforms)
+		return "", nil
+	}
+
+	dockerCtx, err := TarFiles(files)
+	if err != nil {
+		return "", fmt.Errorf("tar prod image context: %w", err)
+	}
+
+	imageUri := utils.ImageURI(b.Registry, b.Image, b.ImageTag)
+	slog.Info("Building multi-arch prod image", "image", imageUri, "platforms", platforms)
+	_, err = c.BuildImageByPlatforms([]byte(WithLabels(dockerfile, b.Labels())), dockerCtx, imageUri, platforms)
+	if err != nil {
+		return "", fmt.Errorf("build multi-arch prod image: %w", err)
+	}
+	report.Default().AddPushed(b.App, imageUri)
+	return imageUri, nil
+}
+
+// BuildProdImage builds the prod image for the container platform from the
+// Dockerfile and exports or pushes it. The files map the build context names
+// to the local files.
+func (c *Container) BuildProdImage(dockerfile string, files map[string]string) (string, error) {
+	b := c.GetBuild()
+	dockerCtx, err := TarFiles(files)
+	if err != nil {
+		return "", fmt.Errorf("tar prod image context: %w", err)
+	}
+
+	image := fmt.Sprintf("%s:%s", b.Image, b.ImageTag)
+	slog.Info("Building prod image", "image", image, "platform", b.Platform.Container.String())
+	err = c.BuildImageByPlatform([]byte(WithLabels(dockerfile, b.Labels())), dockerCtx, image, b.Platform.Container.String())
+	if err != nil {
+		return "", fmt.Errorf("build prod image: %w", err)
+	}
+
+	if err := c.ExportOrPush(image, PushOption{Remove: false}); err != nil {
+		return "", fmt.Errorf("push prod image: %w", err)
+	}
+	return image, nil
+}
+
+// WithLabels appends the labels as LABEL instructions to the Dockerfile.
+func WithLabels(dockerfile string, labels map[string]string) string {
+	return strings.TrimRight(dockerfile, "\n") + "\n" + strings.Join(LabelChanges(labels), "\n") + "\n"
+}
+
+// TarFiles creates a tar archive from the files. The keys are the names
+// in the archive and the values the paths of the local files.
+func TarFiles(files map[string]string) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content, err := os.ReadFile(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", files[name], err)
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0755,
+			Size: int64(len(content)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("write tar header for %s: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, fmt.Errorf("write %s to tar: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("close tar: %w", err)
+	}
+	return &buf, nil
+}
+
 // TarDir creates a tar archive from a filesystem with memory optimizations and concurrent processing
 func TarDir(src fs.ReadDirFS) (*bytes.Buffer, error) {
 	// Count files first to determine if concurrent processing is beneficial
@@ -0,0 +1,147 @@
+package container
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/pkg/cri/utils"
+	"github.com/containifyci/engine-ci/pkg/report"
+)
+
+const (
+	ExportOCI           = "oci"
+	ExportDockerArchive = "docker-archive"
+	DefaultExportDir    = ".containifyci/images"
+)
+
+// ExportFormat returns the image export format set with the export property.
+func (b *Build) ExportFormat() string {
+	return b.Custom.String("export")
+}
+
+// ShouldPush reports whether the prod image has to be pushed. Prod images are
+// pushed unless push=false is set, whether or not they are exported.
+func (b *Build) ShouldPush() bool {
+	return b.Custom.Bool("push", true)
+}
+
+// PushPlatforms returns the prod_platforms of the multi-arch prod image that is
+// pushed as manifest list. Exported images are built for the container platform
+// only, a manifest list can not be exported from the local image store.
+func (b *Build) PushPlatforms() []string {
+	platforms := b.ProdPlatforms()
+	if len(platforms) > 0 && b.ExportFormat() != "" {
+		slog.Warn("Ignore prod_platforms the exported image is built for the container platform only", "platforms", platforms)
+		return nil
+	}
+	return platforms
+}
+
+// ExportImage writes the image as OCI image layout directory or docker-archive
+// tar into the export_dir folder and records it in the run report.
+func (c *Container) ExportImage(image string) (string, error) {
+	b := c.GetBuild()
+	format := b.ExportFormat()
+
+	dir := b.Custom.String("export_dir")
+	if dir == "" {
+		dir = DefaultExportDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create export folder: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s", filepath.Base(b.Image), b.ImageTag)
+
+	var path string
+	var err error
+	switch format {
+	case ExportDockerArchive:
+		path = filepath.Join(dir, name+".tar")
+		err = c.saveArchive(image, path)
+	case ExportOCI:
+		path = filepath.Join(dir, name)
+		err = c.saveLayout(image, path)
+	default:
+		return "", fmt.Errorf("unknown export format %q supported are %s and %s", format, ExportOCI, ExportDockerArchive)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	artifact := report.Artifact{Name: image, Path: path, Type: format}
+	if info, err := c.InspectImage(image); err == nil && info != nil {
+		artifact.Digest = info.ID
+	}
+	report.Default().AddArtifact(b.App, artifact)
+
+	slog.Info("Exported image", "image", image, "format", format, "path", path, "digest", artifact.Digest)
+	return path, nil
+}
+
+func (c *Container) saveArchive(image, path string) error {
+	reader, err := c.client().SaveImage(c.ctx, image, types.DockerArchive)
+	if err != nil {
+		return fmt.Errorf("save image %s: %w", image, err)
+	}
+	defer reader.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, reader); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Container) saveLayout(image, path string) error {
+	reader, err := c.client().SaveImage(c.ctx, image, types.OCIArchive)
+	if err != nil {
+		return fmt.Errorf("save image %s: %w", image, err)
+	}
+	defer reader.Close()
+
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("clean %s: %w", path, err)
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if err := utils.ExtractTar(reader, path); err != nil {
+		return fmt.Errorf("extract image %s: %w", image, err)
+	}
+
+	for _, file := range []string{"oci-layout", "index.json"} {
+		if _, err := os.Stat(filepath.Join(path, file)); err != nil {
+			return fmt.Errorf("%s is not an OCI image layout missing %s (docker 25+ or podman required)", path, file)
+		}
+	}
+	return nil
+}
+
+// ExportOrPush exports the committed prod image when the export property is set
+// and pushes it to the registry unless pushing is disabled.
+func (c *Container) ExportOrPush(imageId string, opts ...PushOption) error {
+	b := c.GetBuild()
+	if b.ExportFormat() != "" {
+		if _, err := c.ExportImage(fmt.Sprintf("%s:%s", b.Image, b.ImageTag)); err != nil {
+			return fmt.Errorf("export image: %w", err)
+		}
+	}
+
+	if !b.ShouldPush() {
+		slog.Info("Skip pushing image")
+		return nil
+	}
+
+	imageUri := utils.ImageURI(b.Registry, b.Image, b.ImageTag)
+	return c.Push(imageId, imageUri, opts...)
+}
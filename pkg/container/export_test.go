@@ -0,0 +1,91 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/cri/critest"
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportContainer(t *testing.T, custom Custom) (*Container, *critest.MockContainerManager) {
+	t.Helper()
+	m, err := critest.NewMockContainerManager()
+	require.NoError(t, err)
+	m.Images["app:v1"] = &critest.MockImageLifecycle{ID: "archive", Opts: &types.ImageInfo{ID: "sha256:1234"}}
+
+	c := NewWithManager(m)
+	c.Build = &Build{App: "export-app", Image: "app", ImageTag: "v1", Custom: custom}
+	return c, m
+}
+
+func TestShouldPush(t *testing.T) {
+	assert.True(t, (&Build{}).ShouldPush())
+	assert.False(t, (&Build{Custom: Custom{"push": {"false"}}}).ShouldPush())
+	// the push default does not depend on the export
+	assert.True(t, (&Build{Custom: Custom{"export": {"oci"}}}).ShouldPush())
+	assert.False(t, (&Build{Custom: Custom{"export": {"oci"}, "push": {"false"}}}).ShouldPush())
+}
+
+func TestPushPlatforms(t *testing.T) {
+	platforms := Custom{"prod_platforms": {"linux/amd64", "linux/arm64"}}
+	assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, (&Build{Custom: platforms}).PushPlatforms())
+
+	platforms["export"] = []string{ExportOCI}
+	assert.Nil(t, (&Build{Custom: platforms}).PushPlatforms())
+}
+
+func TestExportImageDockerArchive(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := exportContainer(t, Custom{"export": {ExportDockerArchive}, "export_dir": {dir}})
+
+	path, err := c.ExportImage("app:v1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "app-v1.tar"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(content))
+
+	artifacts := report.Default().Artifacts("export-app")
+	require.NotEmpty(t, artifacts)
+	assert.Equal(t, "sha256:1234", artifacts[len(artifacts)-1].Digest)
+	assert.Equal(t, ExportDockerArchive, artifacts[len(artifacts)-1].Type)
+}
+
+func TestExportImageUnknownFormat(t *testing.T) {
+	c, _ := exportContainer(t, Custom{"export": {"zip"}, "export_dir": {t.TempDir()}})
+	_, err := c.ExportImage("app:v1")
+	assert.ErrorContains(t, err, "unknown export format")
+}
+
+func TestExportOrPushSkipsPush(t *testing.T) {
+	dir := t.TempDir()
+	c, m := exportContainer(t, Custom{"export": {ExportDockerArchive}, "export_dir": {dir}, "push": {"false"}})
+
+	require.NoError(t, c.ExportOrPush("app:v1"))
+	assert.FileExists(t, filepath.Join(dir, "app-v1.tar"))
+	assert.Contains(t, m.ImagesLogEntries, "app:v1 saved as docker-archive")
+	// a push would tag the image with the registry URI
+	assert.Len(t, m.Images, 1)
+}
+
+func TestExportOrPushSkipsPushWithoutExport(t *testing.T) {
+	c, m := exportContainer(t, Custom{"push": {"false"}})
+	c.Build.Registry = "ghcr.io/owner"
+
+	require.NoError(t, c.ExportOrPush("app:v1"))
+	assert.NotContains(t, m.Images, "ghcr.io/owner/app:v1")
+}
+
+func TestExportOrPushPushes(t *testing.T) {
+	c, m := exportContainer(t, Custom{"export": {ExportDockerArchive}, "export_dir": {t.TempDir()}})
+	c.Build.Registry = "ghcr.io/owner"
+
+	require.NoError(t, c.ExportOrPush("app:v1", PushOption{Remove: false}))
+	assert.Contains(t, m.Images, "ghcr.io/owner/app:v1")
+}
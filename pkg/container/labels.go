@@ -0,0 +1,79 @@
+package container
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/svc"
+)
+
+const (
+	LabelSource          = "org.opencontainers.image.source"
+	LabelRevision        = "org.opencontainers.image.revision"
+	LabelCreated         = "org.opencontainers.image.created"
+	LabelVersion         = "org.opencontainers.image.version"
+	LabelTitle           = "org.opencontainers.image.title"
+	LabelLicenses        = "org.opencontainers.image.licenses"
+	LabelEngineCIVersion = "io.containifyci.engine-ci.version"
+)
+
+// Labels returns the OCI image labels for the prod image of the build.
+// Custom labels can be added with the labels property as key=value pairs
+// and the license with the license property.
+func (b *Build) Labels() map[string]string {
+	labels := map[string]string{
+		LabelCreated:         time.Now().UTC().Format(time.RFC3339),
+		LabelEngineCIVersion: svc.Version(),
+	}
+
+	if b.App != "" {
+		labels[LabelTitle] = b.App
+	}
+	if b.ImageTag != "" {
+		labels[LabelVersion] = b.ImageTag
+	}
+
+	git := svc.GitInfo()
+	if !git.IsUnknown() {
+		labels[LabelSource] = fmt.Sprintf("https://github.com/%s", git.FullRepo())
+		if git.IsTag() {
+			labels[LabelVersion] = git.Tag
+		}
+	}
+	if commit := svc.GitCommit(); commit != "" {
+		labels[LabelRevision] = commit
+	}
+
+	if license := b.Custom.String("license"); license != "" {
+		labels[LabelLicenses] = license
+	}
+
+	for _, label := range b.Custom.Strings("labels") {
+		key, value, ok := strings.Cut(label, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			slog.Warn("Ignore invalid label expected key=value", "label", label)
+			continue
+		}
+		labels[strings.TrimSpace(key)] = value
+	}
+	return labels
+}
+
+// LabelChanges converts the labels into sorted Dockerfile LABEL instructions.
+func LabelChanges(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	changes := make([]string, 0, len(keys))
+	for _, k := range keys {
+		changes = append(changes, fmt.Sprintf("LABEL %s=%s", k, strconv.Quote(labels[k])))
+	}
+	return changes
+}
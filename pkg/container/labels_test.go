@@ -0,0 +1,92 @@
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/cri/critest"
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/pkg/svc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	svc.SetGitInfoForTest("owner", "repo", "main", "v1.2.3")
+	t.Cleanup(svc.ResetGitInfo)
+	t.Setenv("GITHUB_SHA", "abc123")
+
+	b := Build{
+		App:      "app",
+		ImageTag: "abc",
+		Custom: Custom{
+			"license": {"Apache-2.0"},
+			"labels":  {"team=platform", "invalid", "io.example.tier = backend"},
+		},
+	}
+
+	labels := b.Labels()
+	assert.Equal(t, "https://github.com/owner/repo", labels[LabelSource])
+	assert.Equal(t, "abc123", labels[LabelRevision])
+	assert.Equal(t, "v1.2.3", labels[LabelVersion])
+	assert.Equal(t, "app", labels[LabelTitle])
+	assert.Equal(t, "Apache-2.0", labels[LabelLicenses])
+	assert.Equal(t, svc.Version(), labels[LabelEngineCIVersion])
+	assert.NotEmpty(t, labels[LabelCreated])
+	assert.Equal(t, "platform", labels["team"])
+	assert.Equal(t, " backend", labels["io.example.tier"])
+	assert.NotContains(t, labels, "invalid")
+}
+
+func TestLabelsWithoutTag(t *testing.T) {
+	svc.SetGitInfoForTest("owner", "repo", "main", "")
+	t.Cleanup(svc.ResetGitInfo)
+
+	b := Build{App: "app", ImageTag: "abc"}
+	assert.Equal(t, "abc", b.Labels()[LabelVersion])
+}
+
+func TestLabelChanges(t *testing.T) {
+	changes := LabelChanges(map[string]string{
+		"b": "two words",
+		"a": `quote"d`,
+	})
+	assert.Equal(t, []string{
+		`LABEL a="quote\"d"`,
+		`LABEL b="two words"`,
+	}, changes)
+}
+
+func TestWithLabels(t *testing.T) {
+	dockerfile := WithLabels("FROM alpine\n\n", map[string]string{"a": "b"})
+	assert.Equal(t, "FROM alpine\nLABEL a=\"b\"\n", dockerfile)
+}
+
+// commitRecorder records the changes of the committed images.
+type commitRecorder struct {
+	*critest.MockContainerManager
+	changes map[string][]string
+}
+
+func (m *commitRecorder) CommitContainer(ctx context.Context, containerID string, opts types.CommitOptions) (string, error) {
+	m.changes[opts.Reference] = opts.Changes
+	return m.MockContainerManager.CommitContainer(ctx, containerID, opts)
+}
+
+func TestCommitProdLabels(t *testing.T) {
+	mock, err := critest.NewMockContainerManager()
+	require.NoError(t, err)
+	m := &commitRecorder{MockContainerManager: mock, changes: map[string][]string{}}
+	c := NewWithManager(m)
+	c.Build = &Build{App: "app", ImageTag: "v1"}
+
+	_, err = c.Commit("app:build", "intermediate", "CMD [\"app\"]")
+	require.NoError(t, err)
+	_, err = c.CommitProd("app:v1", "prod", "CMD [\"app\"]")
+	require.NoError(t, err)
+
+	// only the prod image is labeled
+	assert.Equal(t, []string{`CMD ["app"]`}, m.changes["app:build"])
+	assert.Equal(t, `CMD ["app"]`, m.changes["app:v1"][0])
+	assert.Contains(t, m.changes["app:v1"], `LABEL org.opencontainers.image.title="app"`)
+}
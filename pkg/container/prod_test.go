@@ -0,0 +1,39 @@
+package container
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProdPlatforms(t *testing.T) {
+	b := Build{Custom: Custom{"prod_platforms": {"linux/amd64, linux/arm64", "linux/arm/v7"}}}
+	assert.Equal(t, []string{"linux/amd64", "linux/arm64", "linux/arm/v7"}, b.ProdPlatforms())
+	assert.Nil(t, (&Build{}).ProdPlatforms())
+}
+
+func TestTarFiles(t *testing.T) {
+	dir := t.TempDir()
+	amd64 := filepath.Join(dir, "app-linux-amd64")
+	require.NoError(t, os.WriteFile(amd64, []byte("amd64"), 0644))
+
+	buf, err := TarFiles(map[string]string{"app-linux-amd64": amd64})
+	require.NoError(t, err)
+
+	tr := tar.NewReader(buf)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "app-linux-amd64", hdr.Name)
+	assert.Equal(t, int64(0755), hdr.Mode)
+	content, err := io.ReadAll(tr)
+	require.NoError(t, err)
+	assert.Equal(t, "amd64", string(content))
+
+	_, err = TarFiles(map[string]string{"missing": filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/containifyci/engine-ci/pkg/logger"
 	"github.com/containifyci/engine-ci/pkg/utils"
 	"github.com/containifyci/engine-ci/protos2"
 )
@@ -61,7 +62,9 @@ var resolver = utils.GetValue
 
 func NewEnvValue(value string) *EnvValue {
 	valueFnc := sync.OnceValue(func() string {
-		return resolver(value, "build")
+		v := resolver(value, "build")
+		logger.RegisterSecret(v)
+		return v
 	})
 	return &EnvValue{
 		value:    value,
@@ -81,7 +81,7 @@ func (m *MockContainerManagerForErrorTesting) CopyFileFromContainer(ctx context.
 func (m *MockContainerManagerForErrorTesting) ExecContainer(ctx context.Context, id string, cmd []string, attachStdOut bool) (io.Reader, error) {
 	return nil, nil
 }
-func (m *MockContainerManagerForErrorTesting) BuildImage(ctx context.Context, dockerfile []byte, imageName string, platform string) (io.ReadCloser, error) {
+func (m *MockContainerManagerForErrorTesting) BuildImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) (io.ReadCloser, error) {
 	return nil, nil
 }
 func (m *MockContainerManagerForErrorTesting) BuildMultiArchImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platforms []string, authBase64 string) (io.ReadCloser, []string, error) {
@@ -105,6 +105,9 @@ func (m *MockContainerManagerForErrorTesting) RemoveImage(ctx context.Context, t
 func (m *MockContainerManagerForErrorTesting) InspectImage(ctx context.Context, image string) (*types.ImageInfo, error) {
 	return nil, nil
 }
+func (m *MockContainerManagerForErrorTesting) SaveImage(ctx context.Context, image string, format string) (io.ReadCloser, error) {
+	return nil, nil
+}
 func (m *MockContainerManagerForErrorTesting) Name() string { return "mock" }
 
 // TestContainer_Wait_ErrorHandling tests the specific error handling improvements from Issue #195
@@ -193,7 +193,7 @@ func (m *MockContainerManager) WaitContainer(ctx context.Context, id string, wai
 	return &exitCode, nil
 }
 
-func (m *MockContainerManager) BuildImage(ctx context.Context, dockerfile []byte, imageName string, platform string) (io.ReadCloser, error) {
+func (m *MockContainerManager) BuildImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) (io.ReadCloser, error) {
 	id := randString(6)
 	platformSpec := types.ParsePlatform(platform)
 	m.Images[imageName] = &MockImageLifecycle{ID: id, Opts: &types.ImageInfo{ID: id, Platform: platformSpec}, BuildInfo: MockImageBuildInfo{ID: id, Name: imageName, Dockerfile: dockerfile}}
@@ -202,7 +202,7 @@ func (m *MockContainerManager) BuildImage(ctx context.Context, dockerfile []byte
 
 func (m *MockContainerManager) BuildMultiArchImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platforms []string, authBase64 string) (io.ReadCloser, []string, error) {
 	for _, platform := range platforms {
-		_, err := m.BuildImage(ctx, dockerfile, imageName+"-"+platform, platform)
+		_, err := m.BuildImage(ctx, dockerfile, dockerCtx, imageName+"-"+platform, platform)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -255,6 +255,15 @@ func (m *MockContainerManager) InspectImage(ctx context.Context, image string) (
 	return nil, ErrImageNotFound
 }
 
+func (m *MockContainerManager) SaveImage(ctx context.Context, image string, format string) (io.ReadCloser, error) {
+	img, exists := m.Images[image]
+	if !exists {
+		return nil, ErrImageNotFound
+	}
+	m.ImagesLogEntries = append(m.ImagesLogEntries, fmt.Sprintf("%s saved as %s", image, format))
+	return io.NopCloser(strings.NewReader(img.ID)), nil
+}
+
 func (m *MockContainerManager) Name() string {
 	return "MockContainerManager"
 }
@@ -70,7 +70,7 @@ func TestMockContainer_ImageLifecycle(t *testing.T) {
 	// Create a new MockContainerManager
 	m, _ := NewMockContainerManager()
 
-	cnt, err := m.BuildImage(ctx, []byte("TestDockerFiles"), "test-image", "")
+	cnt, err := m.BuildImage(ctx, []byte("TestDockerFiles"), nil, "test-image", "")
 	assert.NoError(t, err)
 	assert.NotNil(t, cnt)
 	var b bytes.Buffer
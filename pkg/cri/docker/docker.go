@@ -548,11 +548,34 @@ func (d *DockerManager) CopyFileFromContainer(ctx context.Context, id string, sr
 }
 
 // createTarArchive creates a tar archive containing the Dockerfile.
-func createTarArchive(dockerfileContent []byte) (io.Reader, error) {
+// createTarArchive creates the build context from the optional dockerCtx tar and the Dockerfile.
+func createTarArchive(dockerfileContent []byte, dockerCtx *bytes.Buffer) (io.Reader, error) {
 	buf := new(bytes.Buffer)
 	tw := tar.NewWriter(buf)
 	defer tw.Close()
 
+	if dockerCtx != nil {
+		tr := tar.NewReader(bytes.NewReader(dockerCtx.Bytes()))
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if header.Name == "Dockerfile" {
+				continue
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return nil, err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Add the Dockerfile to the tar archive
 	header := &tar.Header{
 		Name: "Dockerfile",
@@ -648,23 +671,23 @@ func (d *DockerManager) BuildMultiArchImage(ctx context.Context, dockerfile []by
 	}
 
 	command := []string{"docker", "buildx", "build", "--progress", "plain", "--push", "--provenance=mode=max", "--sbom", "true", "--platform", platformStr, "-t", imageName, "-f", file.Name(), dir}
-	fmt.Printf("Running command: %v\n", command)
+	slog.Info("Running command", "command", command)
 	// Create the Docker buildx command
 	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
 
 	// Set the environment variable to enable Docker CLI experimental features
 	// cmd.Env = append(os.Environ(), "DOCKER_CLI_EXPERIMENTAL=enabled")
 
-	// Capture the output
-	var outBuf, errBuf bytes.Buffer
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stdout
+	// Log the output through the log aggregator
+	out := logger.GetLogAggregator().Writer("[buildx]")
+	cmd.Stdout = out
+	cmd.Stderr = out
 
 	// Run the build command
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		fmt.Printf("Output: %s\n", outBuf.String())
-		fmt.Printf("Error Output: %s\n", errBuf.String())
+	err = cmd.Run()
+	_ = out.Close()
+	if err != nil {
+		slog.Error("Failed to build multi platform image", "error", err, "image", imageName)
 		return nil, nil, err
 	}
 
@@ -683,11 +706,12 @@ func (d *DockerManager) BuildMultiArchImage(ctx context.Context, dockerfile []by
 		}
 	}
 
-	return utils.NewReadCloser(&outBuf), []string{}, nil
+	// the output is already logged
+	return utils.NewReadCloser(&bytes.Buffer{}), []string{}, nil
 }
 
-func (d *DockerManager) BuildImage(ctx context.Context, dockerfile []byte, imageName string, platform string) (io.ReadCloser, error) {
-	tarReader, err := createTarArchive(dockerfile)
+func (d *DockerManager) BuildImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) (io.ReadCloser, error) {
+	tarReader, err := createTarArchive(dockerfile, dockerCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -697,12 +721,11 @@ func (d *DockerManager) BuildImage(ctx context.Context, dockerfile []byte, image
 	resp, err := d.client.ImageBuild(ctx, tarReader, client.ImageBuildOptions{
 		Tags:       []string{imageName},
 		Dockerfile: "Dockerfile",
-		//TODO add docker context that contains the folders and files that are referenced in the Dockerfile
-		// Context: ,
 		BuildArgs: map[string]*string{
 			"TARGETPLATFORM": &platform,
 			"TARGETOS":       &platformSpec.OS,
 			"TARGETARCH":     &platformSpec.Architecture,
+			"TARGETVARIANT":  &platformSpec.Variant,
 		},
 	})
 	if err != nil {
@@ -720,6 +743,12 @@ func (d *DockerManager) ContainerLogs(ctx context.Context, id string, ShowStdout
 	})
 }
 
+// SaveImage returns the image as tar archive. Since Docker 25 the archive is
+// both a docker-archive and an OCI image layout so the format is ignored.
+func (d *DockerManager) SaveImage(ctx context.Context, image string, _ string) (io.ReadCloser, error) {
+	return d.client.ImageSave(ctx, []string{image})
+}
+
 func (d *DockerManager) InspectImage(ctx context.Context, image string) (*types.ImageInfo, error) {
 	imageInfo, err := d.client.ImageInspect(ctx, image)
 	if err != nil {
@@ -732,5 +761,6 @@ func (d *DockerManager) InspectImage(ctx context.Context, image string) (*types.
 			OS:           imageInfo.Os,
 			Architecture: imageInfo.Architecture,
 		},
+		RepoDigests: imageInfo.RepoDigests,
 	}, nil
 }
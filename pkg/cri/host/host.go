@@ -199,7 +199,7 @@ func (d *HostManager) InspectContainer(ctx context.Context, id string) (*types.C
 	return opts, nil
 }
 
-func (d *HostManager) BuildImage(ctx context.Context, dockerfile []byte, imageName string, platform string) (io.ReadCloser, error) {
+func (d *HostManager) BuildImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) (io.ReadCloser, error) {
 	return io.NopCloser(strings.NewReader("")), nil
 }
 
@@ -255,6 +255,10 @@ func (d *HostManager) InspectImage(ctx context.Context, image string) (*types.Im
 	return nil, nil
 }
 
+func (d *HostManager) SaveImage(ctx context.Context, image string, format string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("saving images is not supported by the host runtime")
+}
+
 func (d *HostManager) CopyDirectorToContainer(ctx context.Context, id, srcPath, dstPath string) error {
 	return nil
 }
@@ -34,7 +34,7 @@ type ContainerManager interface {
 	InspectContainer(ctx context.Context, id string) (*types.ContainerConfig, error)
 	WaitContainer(ctx context.Context, id string, waitCondition string) (*int64, error)
 
-	BuildImage(ctx context.Context, dockerfile []byte, imageName string, platform string) (io.ReadCloser, error)
+	BuildImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) (io.ReadCloser, error)
 	BuildMultiArchImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platforms []string, authBase64 string) (io.ReadCloser, []string, error)
 
 	ListImage(ctx context.Context, image string) ([]string, error)
@@ -43,6 +43,7 @@ type ContainerManager interface {
 	PushImage(ctx context.Context, target string, authBase64 string) (io.ReadCloser, error)
 	RemoveImage(ctx context.Context, target string) error
 	InspectImage(ctx context.Context, image string) (*types.ImageInfo, error)
+	SaveImage(ctx context.Context, image string, format string) (io.ReadCloser, error)
 
 	Name() string
 }
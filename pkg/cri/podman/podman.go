@@ -384,7 +384,7 @@ func (p *PodmanManager) CopyContentToContainer(ctx context.Context, id, content,
 		return fmt.Errorf("failed to copy to container: %v", err)
 	}
 
-	fmt.Printf("Copied string content to %s in container %s\n", dest, id)
+	slog.Debug("Copied string content to container", "dest", dest, "container", id)
 	return nil
 }
 
@@ -403,7 +403,7 @@ func (p *PodmanManager) CopyDirectorToContainer(ctx context.Context, id, srcPath
 		return fmt.Errorf("failed to copy to container: %v", err)
 	}
 
-	fmt.Printf("Copied string content to %s in container %s\n", dstPath, id)
+	slog.Debug("Copied content to container", "dest", dstPath, "container", id)
 	return nil
 }
 
@@ -482,7 +482,7 @@ func (p *PodmanManager) CopyToContainer(ctx context.Context, id, srcPath, dstPat
 		return fmt.Errorf("failed to copy to container: %v", err)
 	}
 
-	fmt.Printf("Copied string content to %s in container %s\n", dstPath, id)
+	slog.Debug("Copied content to container", "dest", dstPath, "container", id)
 	return nil
 }
 
@@ -681,7 +681,7 @@ func (p *PodmanManager) WaitContainer(ctx context.Context, id string, waitCondit
 }
 
 // BuildImage builds an image
-func (p *PodmanManager) BuildImage(ctx context.Context, dockerfile []byte, imageName string, platform string) (io.ReadCloser, error) {
+func (p *PodmanManager) BuildImage(ctx context.Context, dockerfile []byte, dockerCtx *bytes.Buffer, imageName string, platform string) (io.ReadCloser, error) {
 	// Create a temporary directory for the Dockerfile
 	dir, err := os.MkdirTemp("", "podman-build")
 	if err != nil {
@@ -690,6 +690,15 @@ func (p *PodmanManager) BuildImage(ctx context.Context, dockerfile []byte, image
 	}
 	defer os.RemoveAll(dir) // Clean up
 
+	if dockerCtx != nil {
+		// Extract the tar archive
+		err := utils.ExtractTar(dockerCtx, dir)
+		if err != nil {
+			slog.Error("Error extracting tar archive", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Write the Dockerfile
 	dockerfilePath := dir + "/Dockerfile"
 	file, err := os.Create(dockerfilePath)
@@ -710,7 +719,8 @@ func (p *PodmanManager) BuildImage(ctx context.Context, dockerfile []byte, image
 	var buf bytes.Buffer
 
 	opts := buildahDefine.BuildOptions{
-		Output: imageName,
+		Output:           imageName,
+		ContextDirectory: dir,
 		// TODO set platform
 		// Platforms: ,
 		Log: func(format string, args ...interface{}) {
@@ -857,7 +867,7 @@ func (p *PodmanManager) BuildMultiArchImage(ctx context.Context, dockerfile []by
 		slog.Error("Error creating manifest", "error", err)
 		os.Exit(1)
 	}
-	fmt.Println("Manifest ID: ", mfst)
+	slog.Debug("Created manifest", "manifest", mfst)
 	for _, img := range imageIDs {
 		opts := &manifests.AddOptions{
 			Images: []string{*img.ID},
@@ -867,7 +877,7 @@ func (p *PodmanManager) BuildMultiArchImage(ctx context.Context, dockerfile []by
 			slog.Error("Error adding manifest artifact", "error", err)
 			os.Exit(1)
 		}
-		fmt.Println("Manifest Artifact ID: ", id)
+		slog.Debug("Added manifest artifact", "manifest", mfst, "artifact", id)
 	}
 
 	var progressWriter io.Writer = &buf
@@ -1013,6 +1023,15 @@ func (p *PodmanManager) RemoveImage(ctx context.Context, target string) error {
 	return nil
 }
 
+func (p *PodmanManager) SaveImage(ctx context.Context, image string, format string) (io.ReadCloser, error) {
+	reader, writer := io.Pipe()
+	go func() {
+		err := images.Export(p.conn, []string{image}, writer, new(images.ExportOptions).WithFormat(format))
+		writer.CloseWithError(err)
+	}()
+	return reader, nil
+}
+
 func (p *PodmanManager) InspectImage(ctx context.Context, image string) (*types.ImageInfo, error) {
 	info, err := images.GetImage(p.conn, image, &images.GetOptions{})
 	if err != nil {
@@ -1024,5 +1043,6 @@ func (p *PodmanManager) InspectImage(ctx context.Context, image string) (*types.
 			OS:           info.Os,
 			Architecture: info.Architecture,
 		},
+		RepoDigests: info.RepoDigests,
 	}, nil
 }
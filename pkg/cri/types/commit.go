@@ -5,3 +5,9 @@ type CommitOptions struct {
 	Comment   string
 	Changes   []string
 }
+
+// Archive formats supported by SaveImage.
+const (
+	DockerArchive = "docker-archive"
+	OCIArchive    = "oci-archive"
+)
@@ -72,4 +72,6 @@ type ContainerConfig struct {
 type ImageInfo struct {
 	Platform *PlatformSpec
 	ID       string
+	// RepoDigests are the repository@digest references of the pushed image
+	RepoDigests []string
 }
@@ -19,6 +19,12 @@ func (p *PlatformSpec) String() string {
 	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
 }
 
+// FileName returns the platform as part of a file name, e.g. linux-amd64 or
+// linux-armv7. It matches ${TARGETOS}-${TARGETARCH}${TARGETVARIANT} in a Dockerfile.
+func (p *PlatformSpec) FileName() string {
+	return p.OS + "-" + p.Architecture + p.Variant
+}
+
 func (p *PlatformSpec) ToOrg() *ocispec.Platform {
 	spec := ocispec.Platform(*p)
 	return &spec
@@ -118,14 +124,18 @@ func ParsePlatform(platform string) *PlatformSpec {
 		return nil
 	}
 	parts := strings.Split(platform, "/")
-	if len(parts) != 2 {
+	if len(parts) != 2 && len(parts) != 3 {
 		return nil
 	}
 
-	return &PlatformSpec{
+	spec := &PlatformSpec{
 		OS:           parts[0],
 		Architecture: parts[1],
 	}
+	if len(parts) == 3 {
+		spec.Variant = parts[2]
+	}
+	return spec
 }
 
 func GetImagePlatform(host *PlatformSpec) *PlatformSpec {
@@ -174,3 +184,20 @@ func GetPlatforms(platform Platform) []string {
 	}
 	return platforms
 }
+
+// AppendPlatforms appends the parsed platforms that are not part of specs yet.
+func AppendPlatforms(specs []*PlatformSpec, platforms ...string) []*PlatformSpec {
+	for _, spec := range ParsePlatforms(platforms...) {
+		found := false
+		for _, s := range specs {
+			if s != nil && s.String() == spec.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
@@ -65,3 +65,20 @@ func TestGetPlatforms(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendPlatforms(t *testing.T) {
+	specs := []*PlatformSpec{ParsePlatform("linux/amd64")}
+	specs = AppendPlatforms(specs, "linux/amd64", "linux/arm64", "invalid")
+
+	var platforms []string
+	for _, s := range specs {
+		platforms = append(platforms, s.String())
+	}
+	assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, platforms)
+}
+
+func TestPlatformFileName(t *testing.T) {
+	assert.Equal(t, "linux-amd64", ParsePlatform("linux/amd64").FileName())
+	assert.Equal(t, "linux-armv7", ParsePlatform("linux/arm/v7").FileName())
+	assert.NotEqual(t, ParsePlatform("linux/arm/v6").FileName(), ParsePlatform("linux/arm/v7").FileName())
+}
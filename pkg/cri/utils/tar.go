@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -57,7 +58,7 @@ func ExtractTar(r io.Reader, targetDir string) error {
 			}
 		default:
 			// Handle other file types if necessary
-			fmt.Printf("Unknown file type in tar: %c in file %s\n", header.Typeflag, header.Name)
+			slog.Warn("Unknown file type in tar", "type", string(header.Typeflag), "file", header.Name)
 		}
 	}
 
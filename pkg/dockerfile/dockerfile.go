@@ -0,0 +1,108 @@
+package dockerfile
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/containifyci/engine-ci/pkg/build"
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/cri/utils"
+)
+
+// Property is the custom property with the Dockerfile path relative to the build folder
+const Property = "dockerfile"
+
+// TagProperty set to ChecksumTag tags the image with the checksum of the
+// Dockerfile like the containifyci intermediate images instead of the ImageTag.
+const (
+	TagProperty = "image_tag"
+	ChecksumTag = "checksum"
+)
+
+type DockerfileContainer struct {
+	*container.Container
+	Dockerfile string
+	Folder     string
+	Image      string
+	ImageTag   string
+}
+
+// Matches implements the Build interface - runs for Generic builds with a Dockerfile
+func Matches(build container.Build) bool {
+	return build.BuildType == container.Generic && build.Custom.String(Property) != ""
+}
+
+func New() build.BuildStep {
+	return build.Stepper{
+		BuildType_: container.Generic,
+		RunFn: func(build container.Build) (string, error) {
+			container := new(build)
+			return container.Prod()
+		},
+		Name_:     "dockerfile",
+		Alias_:    "push",
+		MatchedFn: Matches,
+		Async_:    false,
+	}
+}
+
+func new(build container.Build) *DockerfileContainer {
+	folder := build.Folder
+	if folder == "" {
+		folder = "."
+	}
+	return &DockerfileContainer{
+		Container:  container.New(build),
+		Dockerfile: build.Custom.String(Property),
+		Folder:     folder,
+		Image:      build.Image,
+		ImageTag:   build.ImageTag,
+	}
+}
+
+// Prod builds the Dockerfile with the build folder as context and exports or pushes the image
+func (c *DockerfileContainer) Prod() (string, error) {
+	if c.Image == "" {
+		slog.Info("Skip No image specified to push")
+		return "", nil
+	}
+
+	b := c.GetBuild()
+	content, err := os.ReadFile(filepath.Join(c.Folder, c.Dockerfile))
+	if err != nil {
+		return "", fmt.Errorf("read Dockerfile: %w", err)
+	}
+	if b.Custom.String(TagProperty) == ChecksumTag {
+		c.ImageTag = container.ComputeChecksum(content)
+		b.ImageTag = c.ImageTag
+	}
+	dockerfile := container.WithLabels(string(content), b.Labels())
+
+	dockerCtx, err := container.TarDir(os.DirFS(c.Folder).(fs.ReadDirFS))
+	if err != nil {
+		return "", fmt.Errorf("tar image context: %w", err)
+	}
+
+	if platforms := b.PushPlatforms(); len(platforms) > 0 && b.ShouldPush() {
+		imageUri := utils.ImageURI(b.Registry, c.Image, c.ImageTag)
+		slog.Info("Building multi-arch image", "image", imageUri, "dockerfile", c.Dockerfile, "platforms", platforms)
+		if _, err := c.BuildImageByPlatforms([]byte(dockerfile), dockerCtx, imageUri, platforms); err != nil {
+			return "", fmt.Errorf("build multi-arch image: %w", err)
+		}
+		return imageUri, nil
+	}
+
+	image := fmt.Sprintf("%s:%s", c.Image, c.ImageTag)
+	slog.Info("Building image", "image", image, "dockerfile", c.Dockerfile, "platform", b.Platform.Container.String())
+	if err := c.BuildImageByPlatform([]byte(dockerfile), dockerCtx, image, b.Platform.Container.String()); err != nil {
+		return "", fmt.Errorf("build image: %w", err)
+	}
+
+	if err := c.ExportOrPush(image, container.PushOption{Remove: false}); err != nil {
+		return "", fmt.Errorf("push image: %w", err)
+	}
+	return image, nil
+}
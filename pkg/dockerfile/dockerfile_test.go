@@ -0,0 +1,36 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatches(t *testing.T) {
+	assert.True(t, Matches(container.Build{
+		BuildType: container.Generic,
+		Custom:    container.Custom{Property: {"Dockerfile.worker"}},
+	}))
+	assert.False(t, Matches(container.Build{BuildType: container.Generic}))
+	assert.False(t, Matches(container.Build{
+		BuildType: container.GoLang,
+		Custom:    container.Custom{Property: {"Dockerfile"}},
+	}))
+}
+
+func TestDockerfileBuildStep(t *testing.T) {
+	step := New()
+
+	assert.Equal(t, "dockerfile", step.Name())
+	assert.Equal(t, "push", step.Alias())
+	assert.Equal(t, container.Generic, *step.BuildType())
+	assert.False(t, step.IsAsync())
+
+	res, err := step.RunWithBuild(container.Build{
+		BuildType: container.Generic,
+		Custom:    container.Custom{Property: {"Dockerfile"}},
+	})
+	assert.NoError(t, err, "builds without image are skipped")
+	assert.Empty(t, res)
+}
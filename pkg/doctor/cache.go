@@ -0,0 +1,172 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containifyci/engine-ci/pkg/cri"
+	"github.com/containifyci/engine-ci/pkg/cri/utils"
+	"github.com/containifyci/engine-ci/pkg/sonarcloud"
+)
+
+// testContainerPrefix is the name prefix of the containers started by the doctor checks.
+const testContainerPrefix = "engine-ci-doctor-"
+
+// runContainerPrefix is the name prefix of the test containers of this doctor run,
+// the checks run in parallel and must not report each others containers.
+var runContainerPrefix = fmt.Sprintf("%s%d-", testContainerPrefix, os.Getpid())
+
+// SonarCacheCheck verifies that the sonar cache folder is writable by the
+// SonarScanner container that runs as root.
+type SonarCacheCheck struct {
+	*Check
+	path func() (string, error)
+}
+
+// NewSonarCacheCheck creates a new sonar cache check
+func NewSonarCacheCheck() *SonarCacheCheck {
+	return &SonarCacheCheck{
+		Check: &Check{
+			Name:      "Sonar Cache Permissions",
+			Category:  CategoryPermissions,
+			Severity:  SeverityInfo,
+			ShouldRun: true,
+		},
+		path: sonarcloud.CachePath,
+	}
+}
+
+func (c *SonarCacheCheck) Run(_ context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	folder, err := c.path()
+	if err != nil {
+		result.Status = StatusSkipped
+		result.Message = "Could not determine the sonar cache folder"
+		result.Error = err
+		return result
+	}
+	result.Metadata["folder"] = folder
+
+	info, err := os.Stat(folder)
+	if errors.Is(err, os.ErrNotExist) {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("Sonar cache %s does not exist", folder)
+		result.Suggestions = []string{fmt.Sprintf("Create it: mkdir -p %s && chmod 0777 %s", folder, folder)}
+		result.Remediation = &Remediation{
+			Description: fmt.Sprintf("Create %s with mode 0777", folder),
+			Apply: func(context.Context) error {
+				if err := os.MkdirAll(folder, 0755); err != nil {
+					return err
+				}
+				return os.Chmod(folder, 0777)
+			},
+		}
+		return result
+	}
+	if err != nil {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("Could not read sonar cache %s", folder)
+		result.Error = err
+		return result
+	}
+
+	if info.Mode().Perm() != 0777 {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("Sonar cache %s has mode %04o", folder, info.Mode().Perm())
+		result.Details = []string{"The SonarScanner container runs as root and needs to create folders like _tmp in it"}
+		result.Suggestions = []string{fmt.Sprintf("Make it world-writable: chmod 0777 %s", folder)}
+		result.Remediation = &Remediation{
+			Description: fmt.Sprintf("Change the mode of %s to 0777", folder),
+			Command:     fmt.Sprintf("chmod 0777 %s", folder),
+			Apply: func(context.Context) error {
+				return os.Chmod(folder, 0777)
+			},
+		}
+		return result
+	}
+
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("Sonar cache %s is writable", folder)
+	return result
+}
+
+// LeftoverContainersCheck finds test containers kept by failed doctor runs.
+type LeftoverContainersCheck struct {
+	*Check
+}
+
+// NewLeftoverContainersCheck creates a new leftover test containers check,
+// it does not run when the test containers are kept on purpose
+func NewLeftoverContainersCheck(keepTestContainers bool) *LeftoverContainersCheck {
+	return &LeftoverContainersCheck{
+		Check: &Check{
+			Name:      "Leftover Test Containers",
+			Category:  CategoryRuntime,
+			Severity:  SeverityInfo,
+			ShouldRun: !keepTestContainers && cri.DetectContainerRuntime() != utils.RuntimeType("unknown"),
+		},
+	}
+}
+
+func (c *LeftoverContainersCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	manager, err := cri.InitContainerRuntime()
+	if err != nil {
+		result.Status = StatusSkipped
+		result.Message = "Container runtime not available"
+		result.Error = err
+		return result
+	}
+	return checkLeftoverContainers(ctx, result, manager)
+}
+
+func checkLeftoverContainers(ctx context.Context, result CheckResult, manager cri.ContainerManager) CheckResult {
+	containers, err := manager.ContainerList(ctx, true)
+	if err != nil {
+		result.Status = StatusSkipped
+		result.Message = "Could not list containers"
+		result.Error = err
+		return result
+	}
+
+	ids := []string{}
+	for _, con := range containers {
+		for _, name := range con.Names {
+			name = strings.TrimPrefix(name, "/")
+			if strings.HasPrefix(name, testContainerPrefix) && !strings.HasPrefix(name, runContainerPrefix) {
+				ids = append(ids, con.ID)
+				result.Details = append(result.Details, fmt.Sprintf("%s (%s)", name, con.ID))
+				break
+			}
+		}
+	}
+	result.Metadata["containers"] = len(ids)
+
+	if len(ids) == 0 {
+		result.Status = StatusPass
+		result.Message = "No leftover test containers"
+		return result
+	}
+
+	result.Status = StatusWarning
+	result.Message = fmt.Sprintf("Found %d leftover test containers", len(ids))
+	result.Suggestions = []string{"Remove them with: engine-ci doctor --fix"}
+	result.Remediation = &Remediation{
+		Description: fmt.Sprintf("Remove %d leftover test containers", len(ids)),
+		Apply: func(ctx context.Context) error {
+			var errs []error
+			for _, id := range ids {
+				if err := manager.RemoveContainer(ctx, id); err != nil {
+					errs = append(errs, fmt.Errorf("remove %s: %w", id, err))
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}
+	return result
+}
@@ -13,6 +13,7 @@ const (
 	CategorySystem       CheckCategory = "System Resources"
 	CategoryPermissions  CheckCategory = "User Permissions"
 	CategoryGitHub       CheckCategory = "GitHub Actions"
+	CategoryConfig       CheckCategory = "Build Configuration"
 )
 
 // CheckSeverity indicates check importance
@@ -74,4 +75,6 @@ type CheckResult struct {
 	Message     string
 	Details     []string
 	Suggestions []string
+	// Remediation is the known fix applied with doctor --fix
+	Remediation *Remediation
 }
@@ -0,0 +1,10 @@
+//go:build windows
+
+package doctor
+
+import "errors"
+
+// diskSpace is not supported on Windows, the data root is inside the WSL2 VM.
+func diskSpace(string) (uint64, uint64, error) {
+	return 0, 0, errors.New("not supported on windows")
+}
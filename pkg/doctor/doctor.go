@@ -20,6 +20,8 @@ type DoctorOptions struct {
 	IncludeWarnings    bool
 	Parallel           bool
 	KeepTestContainers bool // Don't cleanup test containers (for debugging)
+	// Registries resolves the registry credentials of the build configuration
+	Registries RegistryResolver
 }
 
 // NewDoctor creates a new doctor instance
@@ -37,14 +39,32 @@ func NewDoctor(opts DoctorOptions) *Doctor {
 
 // registerChecks adds all available checks
 func (d *Doctor) registerChecks() {
+	// the build configuration is loaded by the first check that needs it
+	source := NewBuildSource()
+
 	// Runtime checks
 	d.checks = append(d.checks, NewRuntimeDetectionCheck())
 	d.checks = append(d.checks, NewRuntimeConnectivityCheck())
 	d.checks = append(d.checks, NewRuntimeVersionCheck())
+	d.checks = append(d.checks, NewLeftoverContainersCheck(d.opts.KeepTestContainers))
 
 	// Volume permission checks
 	d.checks = append(d.checks, NewVolumeConfigCheck())
 	d.checks = append(d.checks, NewVolumeWriteTestCheck(d.opts.KeepTestContainers))
+	d.checks = append(d.checks, NewGoModCacheCheck())
+	d.checks = append(d.checks, NewSonarCacheCheck())
+
+	// System checks
+	d.checks = append(d.checks, NewDiskSpaceCheck())
+
+	// Network checks
+	d.checks = append(d.checks, NewSSHAgentCheck())
+	d.checks = append(d.checks, NewRegistryAuthCheck(source, d.opts.Registries))
+
+	// Build configuration checks
+	d.checks = append(d.checks, NewGoToolchainCheck())
+	d.checks = append(d.checks, NewPluginModuleCheck())
+	d.checks = append(d.checks, NewPluginBuildCheck(source))
 }
 
 // RegisterCheck adds a check to the doctor
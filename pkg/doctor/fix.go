@@ -0,0 +1,80 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+)
+
+// Remediation is a known fix for a failed check. Fixes without an Apply
+// function need elevated permissions and are only printed as Command.
+type Remediation struct {
+	Apply       func(ctx context.Context) error `json:"-"`
+	Description string
+	Command     string
+}
+
+// Automatic reports whether the fix can be applied by engine-ci.
+func (r *Remediation) Automatic() bool {
+	return r != nil && r.Apply != nil
+}
+
+// FixResult records the outcome of a remediation.
+type FixResult struct {
+	Error       error `json:"-"`
+	CheckName   string
+	Description string
+	Command     string
+	Message     string
+	Before      CheckStatus
+	After       CheckStatus
+	Applied     bool
+	Manual      bool
+	Declined    bool
+}
+
+// Fix applies the remediations of the failed and warning results that are
+// confirmed and re-runs the affected checks. It returns the updated results.
+func (d *Doctor) Fix(ctx context.Context, results []CheckResult, confirm func(CheckResult) bool) ([]CheckResult, []FixResult) {
+	runners := make(map[string]CheckRunner, len(d.checks))
+	for _, runner := range d.checks {
+		runners[runner.GetCheck().Name] = runner
+	}
+
+	updated := make([]CheckResult, len(results))
+	copy(updated, results)
+	fixes := []FixResult{}
+
+	for i, result := range results {
+		if result.Remediation == nil || (result.Status != StatusFail && result.Status != StatusWarning) {
+			continue
+		}
+		fix := FixResult{
+			CheckName:   result.CheckName,
+			Description: result.Remediation.Description,
+			Command:     result.Remediation.Command,
+			Before:      result.Status,
+			After:       result.Status,
+		}
+
+		switch {
+		case !result.Remediation.Automatic():
+			fix.Manual = true
+		case !confirm(result):
+			fix.Declined = true
+		default:
+			if err := result.Remediation.Apply(ctx); err != nil {
+				fix.Error = err
+				fix.Message = fmt.Sprintf("Fix failed: %v", err)
+				break
+			}
+			fix.Applied = true
+			if runner, ok := runners[result.CheckName]; ok {
+				updated[i] = runner.Run(ctx)
+				fix.After = updated[i].Status
+				fix.Message = updated[i].Message
+			}
+		}
+		fixes = append(fixes, fix)
+	}
+	return updated, fixes
+}
@@ -0,0 +1,118 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/cri/critest"
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixableCheck struct {
+	*Check
+	fixed    bool
+	applyErr error
+	manual   bool
+}
+
+func (c *fixableCheck) Run(_ context.Context) CheckResult {
+	result := c.NewCheckResult()
+	if c.fixed {
+		result.Status = StatusPass
+		result.Message = "fixed"
+		return result
+	}
+	result.Status = StatusFail
+	result.Remediation = &Remediation{Description: "fix " + c.Name, Command: "sudo fix"}
+	if !c.manual {
+		result.Remediation.Apply = func(context.Context) error {
+			if c.applyErr != nil {
+				return c.applyErr
+			}
+			c.fixed = true
+			return nil
+		}
+	}
+	return result
+}
+
+func newFixableCheck(name string) *fixableCheck {
+	return &fixableCheck{Check: &Check{Name: name, Category: CategorySystem, Severity: SeverityWarning, ShouldRun: true}}
+}
+
+func TestDoctorFix(t *testing.T) {
+	applied := newFixableCheck("applied")
+	declined := newFixableCheck("declined")
+	manual := newFixableCheck("manual")
+	manual.manual = true
+	failing := newFixableCheck("failing")
+	failing.applyErr = errors.New("boom")
+
+	d := &Doctor{checks: []CheckRunner{applied, declined, manual, failing}}
+	results := d.RunChecks(context.Background())
+
+	updated, fixes := d.Fix(context.Background(), results, func(r CheckResult) bool {
+		return r.CheckName != "declined"
+	})
+
+	require.Len(t, fixes, 4)
+	assert.True(t, fixes[0].Applied)
+	assert.Equal(t, StatusFail, fixes[0].Before)
+	assert.Equal(t, StatusPass, fixes[0].After)
+	assert.Equal(t, StatusPass, updated[0].Status)
+
+	assert.True(t, fixes[1].Declined)
+	assert.Equal(t, StatusFail, updated[1].Status)
+
+	assert.True(t, fixes[2].Manual)
+	assert.Equal(t, "sudo fix", fixes[2].Command)
+
+	assert.False(t, fixes[3].Applied)
+	assert.ErrorContains(t, fixes[3].Error, "boom")
+	assert.Equal(t, StatusFail, updated[3].Status)
+}
+
+func TestSonarCacheCheck_Fix(t *testing.T) {
+	folder := filepath.Join(t.TempDir(), ".sonar", "cache")
+	check := NewSonarCacheCheck()
+	check.path = func() (string, error) { return folder, nil }
+
+	result := check.Run(context.Background())
+	assert.Equal(t, StatusWarning, result.Status)
+	require.True(t, result.Remediation.Automatic())
+	require.NoError(t, result.Remediation.Apply(context.Background()))
+
+	info, err := os.Stat(folder)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0777), info.Mode().Perm())
+	assert.Equal(t, StatusPass, check.Run(context.Background()).Status)
+}
+
+func TestLeftoverContainers_Fix(t *testing.T) {
+	ctx := context.Background()
+	manager, err := critest.NewMockContainerManager()
+	require.NoError(t, err)
+	_, err = manager.CreateContainer(ctx, &types.ContainerConfig{Name: testContainerPrefix + "volume-test-1"}, "")
+	require.NoError(t, err)
+	_, err = manager.CreateContainer(ctx, &types.ContainerConfig{Name: "app"}, "")
+	require.NoError(t, err)
+	// the volume test of the current run is not a leftover
+	_, err = manager.CreateContainer(ctx, &types.ContainerConfig{Name: runContainerPrefix + "volume-test-1"}, "")
+	require.NoError(t, err)
+
+	check := NewLeftoverContainersCheck(false)
+	result := checkLeftoverContainers(ctx, check.NewCheckResult(), manager)
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.Equal(t, 1, result.Metadata["containers"])
+	require.NoError(t, result.Remediation.Apply(ctx))
+
+	assert.Len(t, manager.Containers, 2)
+	assert.Equal(t, StatusPass, checkLeftoverContainers(ctx, check.NewCheckResult(), manager).Status)
+
+	assert.False(t, NewLeftoverContainersCheck(true).ShouldRun)
+}
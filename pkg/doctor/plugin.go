@@ -0,0 +1,318 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/config"
+	"github.com/containifyci/engine-ci/protos2"
+	"golang.org/x/mod/semver"
+)
+
+// GoToolchainCheck verifies the Go toolchain that compiles the containifyci.go plugin.
+type GoToolchainCheck struct {
+	*Check
+	plugin config.Plugin
+}
+
+// NewGoToolchainCheck creates a new Go toolchain check
+func NewGoToolchainCheck() *GoToolchainCheck {
+	plugin := config.DefaultPlugin()
+	return &GoToolchainCheck{
+		Check: &Check{
+			Name:      "Go Toolchain",
+			Category:  CategoryConfig,
+			Severity:  SeverityCritical,
+			ShouldRun: plugin.Exists(),
+		},
+		plugin: plugin,
+	}
+}
+
+func (c *GoToolchainCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		result.Status = StatusFail
+		result.Message = "Go toolchain not found"
+		result.Error = err
+		result.Details = []string{fmt.Sprintf("%s is compiled with go build", c.plugin.Path())}
+		result.Suggestions = []string{"Install Go: https://go.dev/doc/install"}
+		return result
+	}
+
+	out, err := exec.CommandContext(ctx, "go", "env", "GOVERSION", "GOTOOLCHAIN").Output()
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = "Could not run the Go toolchain"
+		result.Error = err
+		return result
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	version := lines[0]
+	toolchain := ""
+	if len(lines) > 1 {
+		toolchain = lines[1]
+	}
+	result.Metadata["version"] = version
+	result.Metadata["toolchain"] = toolchain
+
+	mod, err := config.FindModule(c.plugin.Dir)
+	if err != nil {
+		result.Status = StatusPass
+		result.Message = fmt.Sprintf("Go toolchain %s", version)
+		return result
+	}
+	return checkGoVersion(result, version, toolchain, mod.GoVersion())
+}
+
+func checkGoVersion(result CheckResult, version, toolchain, required string) CheckResult {
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("Go toolchain %s", version)
+	if required == "" {
+		return result
+	}
+	result.Metadata["required"] = required
+
+	have := "v" + strings.TrimPrefix(version, "go")
+	want := "v" + required
+	if !semver.IsValid(have) || !semver.IsValid(want) || semver.Compare(have, want) >= 0 {
+		return result
+	}
+
+	if toolchain == "local" {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("Go toolchain %s is older than go %s of the plugin module", version, required)
+		result.Details = []string{"GOTOOLCHAIN=local prevents the download of a newer toolchain"}
+		result.Suggestions = []string{
+			fmt.Sprintf("Install Go %s or newer", required),
+			"Allow the toolchain download: go env -w GOTOOLCHAIN=auto",
+		}
+		return result
+	}
+	result.Status = StatusWarning
+	result.Message = fmt.Sprintf("Go toolchain %s is older than go %s of the plugin module", version, required)
+	result.Details = []string{fmt.Sprintf("GOTOOLCHAIN=%s downloads go %s on every fresh machine", toolchain, required)}
+	result.Suggestions = []string{fmt.Sprintf("Install Go %s or newer", required)}
+	return result
+}
+
+// PluginModuleCheck verifies the go.mod of the containifyci.go plugin and the
+// protos2 and client versions it requires.
+type PluginModuleCheck struct {
+	*Check
+	plugin  config.Plugin
+	protos2 string
+}
+
+// NewPluginModuleCheck creates a new plugin module check
+func NewPluginModuleCheck() *PluginModuleCheck {
+	plugin := config.DefaultPlugin()
+	return &PluginModuleCheck{
+		Check: &Check{
+			Name:      "Build Configuration Module",
+			Category:  CategoryConfig,
+			Severity:  SeverityCritical,
+			ShouldRun: plugin.Exists(),
+		},
+		plugin:  plugin,
+		protos2: config.EngineProtos2Version(),
+	}
+}
+
+func (c *PluginModuleCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	modCache := ""
+	if out, err := exec.CommandContext(ctx, "go", "env", "GOMODCACHE").Output(); err == nil {
+		modCache = strings.TrimSpace(string(out))
+	}
+	return checkPluginModule(result, c.plugin, c.protos2, modCache)
+}
+
+func checkPluginModule(result CheckResult, plugin config.Plugin, protos2, modCache string) CheckResult {
+	mod, err := config.FindModule(plugin.Dir)
+	if errors.Is(err, config.ErrNoModule) {
+		create := fmt.Sprintf("go -C %s mod init containifyci && go -C %s mod tidy", plugin.Dir, plugin.Dir)
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("No go.mod found for %s", plugin.Path())
+		result.Suggestions = []string{fmt.Sprintf("Create the module: %s", create)}
+		result.Remediation = &Remediation{
+			Description: fmt.Sprintf("Create the Go module of %s", plugin.Path()),
+			Command:     create,
+		}
+		return result
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = "Could not read the go.mod of the build configuration"
+		result.Error = err
+		result.Details = []string{fmt.Sprintf("Error: %v", err)}
+		return result
+	}
+	result.Metadata["go_mod"] = mod.Path
+	if filepath.Dir(mod.Path) != mustAbs(plugin.Dir) {
+		result.Details = append(result.Details, fmt.Sprintf("%s is compiled with %s", plugin.Path(), mod.Path))
+	}
+
+	problems := config.CheckVersions(mod, protos2, modCache)
+	if len(problems) == 0 {
+		result.Status = StatusPass
+		result.Message = fmt.Sprintf("%s matches the engine", mod.Path)
+		return result
+	}
+
+	result.Status = StatusWarning
+	result.Message = fmt.Sprintf("%s has outdated dependencies", mod.Path)
+	updates := []string{}
+	for _, p := range problems {
+		result.Details = append(result.Details, p.Message)
+		if p.Incompatible {
+			result.Status = StatusFail
+			result.Message = fmt.Sprintf("%s has dependencies incompatible with the engine", mod.Path)
+		}
+		version := p.Expected
+		if p.Module == config.ClientModule || version == "" {
+			version = "latest"
+		}
+		updates = append(updates, fmt.Sprintf("%s@%s", p.Module, version))
+	}
+	update := fmt.Sprintf("go -C %s get %s && go -C %s mod tidy", filepath.Dir(mod.Path), strings.Join(updates, " "), filepath.Dir(mod.Path))
+	result.Suggestions = []string{fmt.Sprintf("Update the dependencies: %s", update)}
+	result.Remediation = &Remediation{
+		Description: fmt.Sprintf("Update the dependencies of %s", mod.Path),
+		Command:     update,
+	}
+	return result
+}
+
+func mustAbs(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	return abs
+}
+
+// BuildSource loads the build configuration at most once, the checks running
+// in parallel share the loaded build groups.
+type BuildSource struct {
+	config.Source
+	groups []*protos2.BuildArgsGroup
+	err    error
+	once   sync.Once
+}
+
+// NewBuildSource returns the build configuration used by a build
+func NewBuildSource() *BuildSource {
+	source := config.DefaultSource()
+	if plugin, ok := source.(config.Plugin); ok {
+		// the compiler output is part of the check result
+		plugin.Stderr = io.Discard
+		source = plugin
+	}
+	return &BuildSource{Source: source}
+}
+
+// Load loads the build groups on the first call and returns them afterwards.
+func (s *BuildSource) Load(ctx context.Context) ([]*protos2.BuildArgsGroup, error) {
+	s.once.Do(func() {
+		ctx, cancel := context.WithTimeout(ctx, config.DefaultStartTimeout)
+		defer cancel()
+		s.groups, s.err = s.Source.Load(ctx)
+	})
+	return s.groups, s.err
+}
+
+// PluginBuildCheck loads the build configuration like a build does, it compiles
+// and launches the containifyci.go plugin or parses the containifyci.yaml, and
+// validates the returned build arguments.
+type PluginBuildCheck struct {
+	*Check
+	source  *BuildSource
+	timeout time.Duration
+}
+
+// NewPluginBuildCheck creates a new plugin build check
+func NewPluginBuildCheck(source *BuildSource) *PluginBuildCheck {
+	return &PluginBuildCheck{
+		Check: &Check{
+			Name:      "Build Configuration Plugin",
+			Category:  CategoryConfig,
+			Severity:  SeverityCritical,
+			ShouldRun: source.Exists(),
+		},
+		source:  source,
+		timeout: config.DefaultStartTimeout,
+	}
+}
+
+func (c *PluginBuildCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+	result.Metadata["plugin"] = c.source.Path()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	groups, err := c.source.Load(ctx)
+	result.Metadata["duration"] = time.Since(start).String()
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("Could not load the builds from %s", c.source.Path())
+		result.Error = err
+		result.Details = strings.Split(err.Error(), "\n")
+		if plugin, ok := c.source.Source.(config.Plugin); ok {
+			result.Suggestions = []string{
+				fmt.Sprintf("Run the plugin manually: go run -C %s %s", plugin.Dir, plugin.File),
+			}
+		} else {
+			result.Suggestions = []string{
+				fmt.Sprintf("Fix the reported lines of %s", c.source.Path()),
+			}
+		}
+		return result
+	}
+	return checkBuildArgs(result, groups)
+}
+
+func checkBuildArgs(result CheckResult, groups []*protos2.BuildArgsGroup) CheckResult {
+	problems := config.Validate(groups)
+	builds := 0
+	for i, group := range groups {
+		for _, arg := range group.Args {
+			builds++
+			name := fmt.Sprintf("group %d: %s (%s)", i+1, arg.Application, arg.BuildType)
+			if len(problems[arg]) == 0 {
+				result.Details = append(result.Details, fmt.Sprintf("%s: ok", name))
+				continue
+			}
+			for _, p := range problems[arg] {
+				result.Details = append(result.Details, fmt.Sprintf("%s: %s", name, p))
+			}
+		}
+	}
+	result.Metadata["groups"] = len(groups)
+	result.Metadata["builds"] = builds
+
+	if builds == 0 {
+		result.Status = StatusFail
+		result.Message = "The build configuration returned no builds"
+		return result
+	}
+	if len(problems) > 0 {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("%d of %d builds have configuration problems", len(problems), builds)
+		return result
+	}
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("Loaded %d builds in %d groups", builds, len(groups))
+	return result
+}
@@ -0,0 +1,56 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/config"
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGoVersion(t *testing.T) {
+	check := NewGoToolchainCheck()
+
+	assert.Equal(t, StatusPass, checkGoVersion(check.NewCheckResult(), "go1.26.3", "auto", "1.25.5").Status)
+	assert.Equal(t, StatusWarning, checkGoVersion(check.NewCheckResult(), "go1.24.1", "auto", "1.25.5").Status)
+	assert.Equal(t, StatusFail, checkGoVersion(check.NewCheckResult(), "go1.24.1", "local", "1.25.5").Status)
+	assert.Equal(t, StatusPass, checkGoVersion(check.NewCheckResult(), "devel go1.27", "auto", "1.25.5").Status)
+}
+
+func TestCheckPluginModule(t *testing.T) {
+	dir := t.TempDir()
+	plugin := config.Plugin{Dir: dir, File: "containifyci.go"}
+	check := NewPluginModuleCheck()
+
+	result := checkPluginModule(check.NewCheckResult(), plugin, "v0.27.0", "")
+	assert.Equal(t, StatusFail, result.Status)
+	require.NotNil(t, result.Remediation)
+	assert.Contains(t, result.Remediation.Command, "mod init")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"),
+		[]byte("module containifyci\n\ngo 1.25.5\n\nrequire "+config.Protos2Module+" v0.26.6\n"), 0644))
+	result = checkPluginModule(check.NewCheckResult(), plugin, "v0.27.0", "")
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.Equal(t, "go -C "+dir+" get "+config.Protos2Module+"@v0.27.0 && go -C "+dir+" mod tidy", result.Remediation.Command)
+}
+
+func TestCheckBuildArgs(t *testing.T) {
+	check := NewPluginBuildCheck(NewBuildSource())
+	dir := t.TempDir()
+
+	result := checkBuildArgs(check.NewCheckResult(), []*protos2.BuildArgsGroup{
+		{Args: []*protos2.BuildArgs{{Application: "app", Folder: dir}}},
+		{Args: []*protos2.BuildArgs{{Folder: dir}}},
+	})
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "1 of 2 builds have configuration problems", result.Message)
+	assert.Equal(t, []string{
+		"group 1: app (GoLang): ok",
+		"group 2:  (GoLang): Application is empty",
+	}, result.Details)
+
+	assert.Equal(t, StatusFail, checkBuildArgs(check.NewCheckResult(), nil).Status)
+}
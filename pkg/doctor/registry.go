@@ -0,0 +1,281 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/config"
+	"github.com/containifyci/engine-ci/protos2"
+)
+
+// errUnauthorized is returned when the registry rejects the credentials.
+var errUnauthorized = errors.New("credentials rejected")
+
+// RegistryCredentials are the resolved credentials of a registry in Build.Registries.
+type RegistryCredentials struct {
+	Host     string
+	Username string
+	Password string
+	// Ref is the configured password reference, e.g. env:REGISTRY_TOKEN
+	Ref string
+}
+
+// RegistryResolver resolves the credentials of the registries configured in the build groups.
+type RegistryResolver func(groups []*protos2.BuildArgsGroup) []RegistryCredentials
+
+// RegistryAuthCheck authenticates against the configured registries with the
+// token flow of the registry v2 API.
+type RegistryAuthCheck struct {
+	*Check
+	source  config.Source
+	resolve RegistryResolver
+	client  *http.Client
+	scheme  string
+	timeout time.Duration
+}
+
+// NewRegistryAuthCheck creates a new registry credentials check. The build
+// configuration is only loaded when the check runs.
+func NewRegistryAuthCheck(source config.Source, resolve RegistryResolver) *RegistryAuthCheck {
+	return &RegistryAuthCheck{
+		Check: &Check{
+			Name:      "Registry Credentials",
+			Category:  CategoryNetwork,
+			Severity:  SeverityWarning,
+			ShouldRun: resolve != nil && source.Exists(),
+		},
+		source:  source,
+		resolve: resolve,
+		client:  http.DefaultClient,
+		scheme:  "https",
+		timeout: 10 * time.Second,
+	}
+}
+
+func (c *RegistryAuthCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	groups, err := c.source.Load(ctx)
+	if err != nil {
+		// reported by the build configuration checks
+		result.Status = StatusSkipped
+		result.Message = "Could not load the build configuration"
+		result.Error = err
+		return result
+	}
+	registries := c.resolve(groups)
+	if len(registries) == 0 {
+		result.Status = StatusSkipped
+		result.Message = "No registries configured in the build configuration"
+		return result
+	}
+	sort.Slice(registries, func(i, j int) bool { return registries[i].Host < registries[j].Host })
+	return c.authenticateAll(ctx, result, registries)
+}
+
+// authenticateAll verifies the credentials of every registry.
+func (c *RegistryAuthCheck) authenticateAll(ctx context.Context, result CheckResult, registries []RegistryCredentials) CheckResult {
+	result.Status = StatusPass
+
+	failed := []string{}
+	for _, reg := range registries {
+		if reg.Password == "" {
+			result.Status = StatusWarning
+			result.Details = append(result.Details, fmt.Sprintf("%s: no credentials resolved from %q", reg.Host, reg.Ref))
+			if strings.HasPrefix(reg.Ref, "mem:") {
+				result.Suggestions = append(result.Suggestions,
+					fmt.Sprintf("%s: mem: references are only set during a build run, verify the step storing %s", reg.Host, strings.TrimPrefix(reg.Ref, "mem:")))
+			} else {
+				result.Suggestions = append(result.Suggestions,
+					fmt.Sprintf("%s: export the variable referenced by %q (the _LOCAL suffix is used with ENV=local)", reg.Host, reg.Ref))
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := c.authenticate(ctx, reg)
+		cancel()
+		switch {
+		case err == nil:
+			result.Details = append(result.Details, fmt.Sprintf("%s: authenticated as %s", reg.Host, reg.Username))
+		case errors.Is(err, errUnauthorized):
+			failed = append(failed, reg.Host)
+			result.Details = append(result.Details, fmt.Sprintf("%s: %v", reg.Host, err))
+			result.Suggestions = append(result.Suggestions,
+				fmt.Sprintf("%s: the credentials from %q are expired or invalid, renew them and verify with: docker login %s", reg.Host, reg.Ref, reg.Host))
+		default:
+			if result.Status == StatusPass {
+				result.Status = StatusWarning
+			}
+			result.Details = append(result.Details, fmt.Sprintf("%s: %v", reg.Host, err))
+			result.Suggestions = append(result.Suggestions,
+				fmt.Sprintf("%s: check the network access to the registry: curl -I %s://%s/v2/", reg.Host, c.scheme, registryHost(reg.Host)))
+		}
+	}
+	result.Metadata["registries"] = len(registries)
+	result.Metadata["failed"] = failed
+
+	if len(failed) > 0 {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("Registry authentication failed for %s", strings.Join(failed, ", "))
+		return result
+	}
+	if result.Status == StatusWarning {
+		result.Message = "Could not verify all registry credentials"
+		return result
+	}
+	result.Message = fmt.Sprintf("Authenticated against %d registries", len(registries))
+	return result
+}
+
+// registryHost maps the Docker Hub aliases to the registry API host.
+func registryHost(host string) string {
+	switch host {
+	case "docker.io", "index.docker.io":
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+// authenticate performs the registry v2 authentication. Registries either
+// accept basic auth directly or return a bearer challenge with the token realm.
+func (c *RegistryAuthCheck) authenticate(ctx context.Context, reg RegistryCredentials) error {
+	base := fmt.Sprintf("%s://%s/v2/", c.scheme, registryHost(reg.Host))
+
+	resp, err := c.get(ctx, base, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, base)
+	}
+
+	scheme, params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	switch scheme {
+	case "basic":
+		resp, err := c.get(ctx, base, func(r *http.Request) { r.SetBasicAuth(reg.Username, reg.Password) })
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return checkStatus(resp, base)
+	case "bearer":
+		token, err := c.token(ctx, reg, params)
+		if err != nil {
+			return err
+		}
+		resp, err := c.get(ctx, base, func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) })
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return checkStatus(resp, base)
+	}
+	return fmt.Errorf("unsupported authentication challenge %q", resp.Header.Get("WWW-Authenticate"))
+}
+
+// token requests a bearer token from the realm of the challenge.
+func (c *RegistryAuthCheck) token(ctx context.Context, reg RegistryCredentials, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v := params[key]; v != "" {
+			query.Set(key, v)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := c.get(ctx, realm.String(), func(r *http.Request) { r.SetBasicAuth(reg.Username, reg.Password) })
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, realm.Host); err != nil {
+		return "", err
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response from %s: %w", realm.Host, err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("no token in response from %s", realm.Host)
+}
+
+func (c *RegistryAuthCheck) get(ctx context.Context, url string, auth func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		auth(req)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry not reachable: %w", err)
+	}
+	return resp, nil
+}
+
+func checkStatus(resp *http.Response, target string) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w by %s (%s)", errUnauthorized, target, resp.Status)
+	}
+	return fmt.Errorf("unexpected status %s from %s", resp.Status, target)
+}
+
+// parseChallenge parses a WWW-Authenticate header like
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var pair string
+		pair, rest = cutParam(rest)
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return strings.ToLower(scheme), params
+}
+
+// cutParam returns the next comma separated parameter, commas in quotes are kept.
+func cutParam(s string) (string, string) {
+	quoted := false
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
@@ -0,0 +1,141 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRegistry starts a registry with the v2 token flow, the token realm is
+// served by the registry itself.
+func newRegistry(t *testing.T, bearer bool) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, basic := r.BasicAuth()
+		switch r.URL.Path {
+		case "/token":
+			if !basic || user != "user" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "test-registry", r.URL.Query().Get("service"))
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "registry-token"})
+		case "/v2/":
+			if bearer && r.Header.Get("Authorization") == "Bearer registry-token" {
+				return
+			}
+			if !bearer && basic && user == "user" && pass == "secret" {
+				return
+			}
+			if bearer {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, srv.URL))
+			} else {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// staticSource is a build configuration that is always loaded
+type staticSource struct {
+	loads *int
+}
+
+func (s staticSource) Load(context.Context) ([]*protos2.BuildArgsGroup, error) {
+	if s.loads != nil {
+		*s.loads++
+	}
+	return nil, nil
+}
+
+func (staticSource) Path() string { return "containifyci.yaml" }
+
+func (staticSource) Exists() bool { return true }
+
+func newTestRegistryCheck(srv *httptest.Server, password string) *RegistryAuthCheck {
+	host := strings.TrimPrefix(srv.URL, "https://")
+	check := NewRegistryAuthCheck(staticSource{}, func([]*protos2.BuildArgsGroup) []RegistryCredentials {
+		return []RegistryCredentials{{Host: host, Username: "user", Password: password, Ref: "env:REGISTRY_PASSWORD"}}
+	})
+	check.client = srv.Client()
+	return check
+}
+
+func TestRegistryAuthCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		bearer   bool
+		password string
+		status   CheckStatus
+	}{
+		{"bearer token flow", true, "secret", StatusPass},
+		{"bearer expired credentials", true, "expired", StatusFail},
+		{"basic auth", false, "secret", StatusPass},
+		{"basic auth expired credentials", false, "expired", StatusFail},
+		{"no credentials", true, "", StatusWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := newTestRegistryCheck(newRegistry(t, tt.bearer), tt.password)
+
+			result := check.Run(context.Background())
+
+			assert.Equal(t, tt.status, result.Status, result.Details)
+			if tt.status != StatusPass {
+				assert.NotEmpty(t, result.Suggestions)
+			}
+		})
+	}
+}
+
+func TestRegistryAuthCheck_Unreachable(t *testing.T) {
+	srv := newRegistry(t, true)
+	check := newTestRegistryCheck(srv, "secret")
+	srv.Close()
+
+	result := check.Run(context.Background())
+
+	assert.Equal(t, StatusWarning, result.Status)
+}
+
+func TestRegistryAuthCheck_SharedSource(t *testing.T) {
+	loads := 0
+	source := &BuildSource{Source: staticSource{loads: &loads}}
+	check := NewRegistryAuthCheck(source, func([]*protos2.BuildArgsGroup) []RegistryCredentials { return nil })
+	assert.True(t, check.ShouldRun)
+	// the configuration is only loaded when a check runs
+	assert.Equal(t, 0, loads)
+
+	result := check.Run(context.Background())
+	assert.Equal(t, StatusSkipped, result.Status)
+
+	_, err := source.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loads)
+
+	assert.False(t, NewRegistryAuthCheck(source, nil).ShouldRun)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:a/b:pull,push"`)
+
+	assert.Equal(t, "bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:a/b:pull,push",
+	}, params)
+}
@@ -41,20 +41,27 @@ func NewResultFormatter(w io.Writer, verbose, jsonOutput, useColor bool) *Result
 
 // FormatResults outputs all results
 func (f *ResultFormatter) FormatResults(results []CheckResult) error {
+	return f.FormatReport(results, nil)
+}
+
+// FormatReport outputs all results and the fixes applied with doctor --fix
+func (f *ResultFormatter) FormatReport(results []CheckResult, fixes []FixResult) error {
 	if f.jsonOutput {
-		return f.formatJSON(results)
+		return f.formatJSON(results, fixes)
 	}
 
-	return f.formatHuman(results)
+	return f.formatHuman(results, fixes)
 }
 
 // formatJSON outputs results as JSON
-func (f *ResultFormatter) formatJSON(results []CheckResult) error {
+func (f *ResultFormatter) formatJSON(results []CheckResult, fixes []FixResult) error {
 	output := struct {
 		Results []CheckResult `json:"results"`
+		Fixes   []FixResult   `json:"fixes,omitempty"`
 		Summary Summary       `json:"summary"`
 	}{
 		Results: results,
+		Fixes:   fixes,
 		Summary: f.calculateSummary(results),
 	}
 
@@ -64,7 +71,7 @@ func (f *ResultFormatter) formatJSON(results []CheckResult) error {
 }
 
 // formatHuman outputs human-readable format
-func (f *ResultFormatter) formatHuman(results []CheckResult) error {
+func (f *ResultFormatter) formatHuman(results []CheckResult, fixes []FixResult) error {
 	// Group by category
 	categoryGroups := make(map[CheckCategory][]CheckResult)
 	for _, result := range results {
@@ -86,6 +93,7 @@ func (f *ResultFormatter) formatHuman(results []CheckResult) error {
 		CategorySystem,
 		CategoryPermissions,
 		CategoryGitHub,
+		CategoryConfig,
 	}
 
 	for _, cat := range categories {
@@ -94,6 +102,10 @@ func (f *ResultFormatter) formatHuman(results []CheckResult) error {
 		}
 	}
 
+	if len(fixes) > 0 {
+		f.printFixes(fixes)
+	}
+
 	// Print summary
 	f.printSummary(results)
 
@@ -154,11 +166,40 @@ func (f *ResultFormatter) printResult(result CheckResult) {
 		for _, suggestion := range result.Suggestions {
 			fmt.Fprintf(f.writer, "      → %s\n", suggestion)
 		}
+		if result.Remediation.Automatic() {
+			fmt.Fprintf(f.writer, "      → Fix with engine-ci doctor --fix: %s\n", result.Remediation.Description)
+		}
 	}
 
 	fmt.Fprintln(f.writer)
 }
 
+// printFixes prints the outcome of the applied fixes
+func (f *ResultFormatter) printFixes(fixes []FixResult) {
+	fmt.Fprintf(f.writer, "\n%sFixes%s\n", f.color(colorBold), f.color(colorReset))
+	fmt.Fprintf(f.writer, "%s\n", strings.Repeat("─", 5))
+
+	for _, fix := range fixes {
+		switch {
+		case fix.Applied:
+			color := colorGreen
+			if fix.After != StatusPass {
+				color = colorYellow
+			}
+			fmt.Fprintf(f.writer, "  %s%s%s %s: %s\n", f.color(color), checkmark, f.color(colorReset), fix.CheckName, fix.Description)
+			fmt.Fprintf(f.writer, "    %s%s → %s %s%s\n", f.color(colorGray), fix.Before, fix.After, fix.Message, f.color(colorReset))
+		case fix.Manual:
+			fmt.Fprintf(f.writer, "  %s%s%s %s: %s\n", f.color(colorYellow), warning, f.color(colorReset), fix.CheckName, fix.Description)
+			fmt.Fprintf(f.writer, "    Run manually: %s\n", fix.Command)
+		case fix.Declined:
+			fmt.Fprintf(f.writer, "  %s%s%s %s: %s (skipped)\n", f.color(colorGray), info, f.color(colorReset), fix.CheckName, fix.Description)
+		default:
+			fmt.Fprintf(f.writer, "  %s%s%s %s: %s\n", f.color(colorRed), cross, f.color(colorReset), fix.CheckName, fix.Description)
+			fmt.Fprintf(f.writer, "    %s\n", fix.Message)
+		}
+	}
+}
+
 // Summary contains check result summary
 type Summary struct {
 	Total    int `json:"total"`
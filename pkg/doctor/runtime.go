@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	goruntime "runtime"
 	"strings"
 
 	"github.com/containifyci/engine-ci/pkg/cri"
@@ -98,12 +99,30 @@ func (c *RuntimeConnectivityCheck) Run(ctx context.Context) CheckResult {
 				"Add your user to docker group: sudo usermod -aG docker $USER",
 				"Restart your session after adding to docker group",
 			}
+			if strings.Contains(err.Error(), "permission denied") {
+				result.Remediation = &Remediation{
+					Description: "Add the current user to the docker group and start a new login session",
+					Command:     "sudo usermod -aG docker $USER",
+				}
+			}
 		case utils.Podman:
 			result.Suggestions = []string{
 				"Check if Podman socket is running: systemctl --user status podman.socket",
 				"Start Podman socket: systemctl --user start podman.socket",
 				"Verify Podman connection: podman info",
 			}
+			if goruntime.GOOS == "linux" {
+				result.Remediation = &Remediation{
+					Description: "Start the Podman user socket",
+					Command:     "systemctl --user start podman.socket",
+					Apply: func(ctx context.Context) error {
+						if out, err := exec.CommandContext(ctx, "systemctl", "--user", "start", "podman.socket").CombinedOutput(); err != nil {
+							return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+						}
+						return nil
+					},
+				}
+			}
 		default:
 			result.Suggestions = []string{
 				"Check runtime daemon/service status",
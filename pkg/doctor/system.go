@@ -0,0 +1,281 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/cri"
+	"github.com/containifyci/engine-ci/pkg/cri/utils"
+	"github.com/containifyci/engine-ci/pkg/network"
+)
+
+const (
+	gib = 1 << 30
+	// minFreeSpace fails the disk check, warnFreeSpace only warns.
+	minFreeSpace  = 2 * gib
+	warnFreeSpace = 10 * gib
+)
+
+// SSHAgentCheck verifies the SSH agent forwarded into the build containers
+// to fetch private Go modules. On macOS Docker Desktop forwards the agent of
+// the host to /run/host-services/ssh-auth.sock, Podman does not forward it.
+type SSHAgentCheck struct {
+	*Check
+	runtime func() utils.RuntimeType
+	goos    string
+}
+
+// NewSSHAgentCheck creates a new SSH agent check
+func NewSSHAgentCheck() *SSHAgentCheck {
+	return &SSHAgentCheck{
+		Check: &Check{
+			Name:      "SSH Agent",
+			Category:  CategoryNetwork,
+			Severity:  SeverityWarning,
+			ShouldRun: runtime.GOOS == "linux" || runtime.GOOS == "darwin",
+		},
+		runtime: cri.DetectContainerRuntime,
+		goos:    runtime.GOOS,
+	}
+}
+
+func (c *SSHAgentCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	if c.goos == "darwin" {
+		if c.runtime() == utils.Podman {
+			result.Status = StatusSkipped
+			result.Message = "SSH agent forwarding is not supported on macOS with Podman"
+			return result
+		}
+		result.Metadata["container_socket"] = network.DARWIN_SSH_AUTH_SOCK
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		result.Status = StatusWarning
+		result.Message = "SSH_AUTH_SOCK is not set"
+		result.Details = []string{"Private Go modules fetched with git+ssh will fail inside the build containers"}
+		result.Suggestions = []string{
+			"Start an agent: eval $(ssh-agent -s)",
+			"Add your key: ssh-add ~/.ssh/id_ed25519",
+			"In GitHub Actions use webfactory/ssh-agent to load a deploy key",
+		}
+		return result
+	}
+	result.Metadata["socket"] = sock
+
+	conn, err := net.DialTimeout("unix", sock, 2*time.Second)
+	if err != nil {
+		result.Status = StatusWarning
+		result.Message = "SSH agent is not reachable"
+		result.Error = err
+		result.Details = []string{fmt.Sprintf("SSH_AUTH_SOCK=%s: %v", sock, err)}
+		result.Suggestions = []string{
+			"The agent of SSH_AUTH_SOCK is gone, start a new one: eval $(ssh-agent -s)",
+		}
+		return result
+	}
+	_ = conn.Close()
+
+	if _, err := exec.LookPath("ssh-add"); err != nil {
+		result.Status = StatusPass
+		result.Message = "SSH agent is reachable"
+		result.Details = []string{"ssh-add not found, the loaded keys were not checked"}
+		return result
+	}
+
+	out, err := exec.CommandContext(ctx, "ssh-add", "-l").CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		result.Status = StatusWarning
+		result.Message = "SSH agent has no keys loaded"
+		result.Suggestions = []string{"Add your key: ssh-add ~/.ssh/id_ed25519"}
+		return result
+	}
+	if err != nil {
+		result.Status = StatusWarning
+		result.Message = "Could not list the keys of the SSH agent"
+		result.Error = err
+		result.Details = []string{strings.TrimSpace(string(out))}
+		return result
+	}
+
+	keys := strings.Count(strings.TrimSpace(string(out)), "\n") + 1
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("SSH agent has %d keys loaded", keys)
+	result.Metadata["keys"] = keys
+	return result
+}
+
+// DiskSpaceCheck verifies the free space of the container runtime data root.
+type DiskSpaceCheck struct {
+	*Check
+}
+
+// NewDiskSpaceCheck creates a new disk space check
+func NewDiskSpaceCheck() *DiskSpaceCheck {
+	return &DiskSpaceCheck{
+		Check: &Check{
+			Name:      "Container Data Root Disk Space",
+			Category:  CategorySystem,
+			Severity:  SeverityWarning,
+			ShouldRun: cri.DetectContainerRuntime() != utils.RuntimeType("unknown"),
+		},
+	}
+}
+
+func (c *DiskSpaceCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	runtime := cri.DetectContainerRuntime()
+	root, err := dataRoot(ctx, runtime)
+	if err != nil {
+		result.Status = StatusSkipped
+		result.Message = "Could not determine the data root of the container runtime"
+		result.Details = []string{fmt.Sprintf("Error: %v", err)}
+		return result
+	}
+	result.Metadata["data_root"] = root
+
+	free, total, err := diskSpace(root)
+	if err != nil {
+		// Docker Desktop and Podman machines keep the data root inside a VM
+		result.Status = StatusSkipped
+		result.Message = fmt.Sprintf("Data root %s is not accessible from the host", root)
+		result.Details = []string{fmt.Sprintf("Error: %v", err)}
+		return result
+	}
+	result.Metadata["free_bytes"] = free
+	result.Metadata["total_bytes"] = total
+
+	result.Status, result.Message = evaluateDiskSpace(root, free, total)
+	result.Details = []string{fmt.Sprintf("%s: %.1f GiB free of %.1f GiB", root, float64(free)/gib, float64(total)/gib)}
+	if result.Status != StatusPass {
+		result.Suggestions = []string{
+			fmt.Sprintf("Show the space used by images, containers and volumes: %s system df", runtime),
+			fmt.Sprintf("Remove unused data: %s system prune", runtime),
+			"Remove unused engine-ci cache volumes: engine-ci clean",
+		}
+	}
+	return result
+}
+
+func evaluateDiskSpace(root string, free, total uint64) (CheckStatus, string) {
+	switch {
+	case free < minFreeSpace:
+		return StatusFail, fmt.Sprintf("Only %.1f GiB free on %s", float64(free)/gib, root)
+	case free < warnFreeSpace || (total > 0 && free*10 < total):
+		return StatusWarning, fmt.Sprintf("Low disk space %.1f GiB free on %s", float64(free)/gib, root)
+	}
+	return StatusPass, fmt.Sprintf("%.1f GiB free on %s", float64(free)/gib, root)
+}
+
+func dataRoot(ctx context.Context, runtime utils.RuntimeType) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime {
+	case utils.Docker:
+		cmd = exec.CommandContext(ctx, "docker", "info", "--format", "{{.DockerRootDir}}")
+	case utils.Podman:
+		cmd = exec.CommandContext(ctx, "podman", "info", "--format", "{{.Store.GraphRoot}}")
+	default:
+		return "", fmt.Errorf("runtime %s not supported", runtime)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	root := strings.TrimSpace(string(out))
+	if root == "" {
+		return "", fmt.Errorf("empty data root")
+	}
+	return root, nil
+}
+
+// GoModCacheCheck verifies that the Go module cache mounted into the
+// build containers is writable by the current user.
+type GoModCacheCheck struct {
+	*Check
+}
+
+// NewGoModCacheCheck creates a new Go module cache check
+func NewGoModCacheCheck() *GoModCacheCheck {
+	_, err := exec.LookPath("go")
+	return &GoModCacheCheck{
+		Check: &Check{
+			Name:      "Go Module Cache Permissions",
+			Category:  CategoryPermissions,
+			Severity:  SeverityWarning,
+			ShouldRun: err == nil,
+		},
+	}
+}
+
+func (c *GoModCacheCheck) Run(ctx context.Context) CheckResult {
+	result := c.NewCheckResult()
+
+	out, err := exec.CommandContext(ctx, "go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		result.Status = StatusSkipped
+		result.Message = "Could not determine GOMODCACHE"
+		result.Error = err
+		return result
+	}
+	return checkGoModCache(result, strings.TrimSpace(string(out)))
+}
+
+func checkGoModCache(result CheckResult, dir string) CheckResult {
+	result.Metadata["gomodcache"] = dir
+	fix := fmt.Sprintf("sudo chown -R $(id -u):$(id -g) %s", dir)
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("GOMODCACHE %s does not exist", dir)
+		result.Details = []string{"The build container creates it as root when it is missing"}
+		result.Suggestions = []string{fmt.Sprintf("Create it as your user: mkdir -p %s", dir)}
+		result.Remediation = &Remediation{
+			Description: fmt.Sprintf("Create %s owned by the current user", dir),
+			Apply: func(context.Context) error {
+				return os.MkdirAll(dir, 0755)
+			},
+		}
+		return result
+	}
+
+	// the download cache is written by the go command of the host and the build containers
+	for _, d := range []string{dir, filepath.Join(dir, "cache", "download")} {
+		if _, err := os.Stat(d); err != nil {
+			continue
+		}
+		f, err := os.CreateTemp(d, ".engine-ci-doctor-*")
+		if err != nil {
+			result.Status = StatusFail
+			result.Message = fmt.Sprintf("GOMODCACHE %s is not writable", d)
+			result.Error = err
+			result.Details = []string{fmt.Sprintf("Error: %v", err)}
+			result.Suggestions = []string{
+				"The folder is probably owned by root after a build container wrote to it",
+				fmt.Sprintf("Take ownership: %s", fix),
+			}
+			result.Remediation = &Remediation{
+				Description: fmt.Sprintf("Take ownership of %s", dir),
+				Command:     fix,
+			}
+			return result
+		}
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("GOMODCACHE %s is writable", dir)
+	return result
+}
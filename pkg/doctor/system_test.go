@@ -0,0 +1,62 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/cri/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateDiskSpace(t *testing.T) {
+	status, _ := evaluateDiskSpace("/var/lib/docker", 1*gib, 100*gib)
+	assert.Equal(t, StatusFail, status)
+
+	status, _ = evaluateDiskSpace("/var/lib/docker", 5*gib, 20*gib)
+	assert.Equal(t, StatusWarning, status)
+
+	status, _ = evaluateDiskSpace("/var/lib/docker", 50*gib, 1000*gib)
+	assert.Equal(t, StatusWarning, status)
+
+	status, _ = evaluateDiskSpace("/var/lib/docker", 50*gib, 100*gib)
+	assert.Equal(t, StatusPass, status)
+}
+
+func TestSSHAgentCheckDarwin(t *testing.T) {
+	check := NewSSHAgentCheck()
+	check.goos = "darwin"
+
+	check.runtime = func() utils.RuntimeType { return utils.Podman }
+	result := check.Run(context.Background())
+	assert.Equal(t, StatusSkipped, result.Status)
+
+	// Docker Desktop forwards the agent of the host
+	check.runtime = func() utils.RuntimeType { return utils.Docker }
+	t.Setenv("SSH_AUTH_SOCK", "")
+	result = check.Run(context.Background())
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.Equal(t, "SSH_AUTH_SOCK is not set", result.Message)
+	assert.Equal(t, "/run/host-services/ssh-auth.sock", result.Metadata["container_socket"])
+}
+
+func TestCheckGoModCache(t *testing.T) {
+	check := NewGoModCacheCheck()
+
+	dir := t.TempDir()
+	result := checkGoModCache(check.NewCheckResult(), dir)
+	assert.Equal(t, StatusPass, result.Status)
+
+	result = checkGoModCache(check.NewCheckResult(), filepath.Join(dir, "missing"))
+	assert.Equal(t, StatusWarning, result.Status)
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only folders")
+	}
+	readonly := filepath.Join(dir, "readonly")
+	assert.NoError(t, os.Mkdir(readonly, 0555))
+	result = checkGoModCache(check.NewCheckResult(), readonly)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.NotEmpty(t, result.Suggestions)
+}
@@ -315,7 +315,7 @@ func (c *VolumeWriteTestCheck) Run(ctx context.Context) CheckResult {
 // runVolumeTest creates and runs the test container
 func (c *VolumeWriteTestCheck) runVolumeTest(ctx context.Context, manager cri.ContainerManager, tempDir, testContent string) (string, error) {
 	// Prepare container configuration
-	containerName := fmt.Sprintf("engine-ci-doctor-volume-test-%d", time.Now().Unix())
+	containerName := fmt.Sprintf("%svolume-test-%d", runContainerPrefix, time.Now().Unix())
 	imageName := "alpine:latest"
 
 	// Create container config
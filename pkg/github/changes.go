@@ -3,6 +3,7 @@ package github
 import (
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -42,6 +43,49 @@ func GetChangedFiles() []string {
 	return files
 }
 
+// GetChangedFilesSince returns the files changed between the merge base of the
+// ref and HEAD together with the uncommitted changes. The paths are relative to
+// the repository root.
+func GetChangedFilesSince(ref string) ([]string, error) {
+	cmd := execCommand("git", "diff", "--name-only", ref+"...HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the changes since %s: %w", ref, err)
+	}
+
+	seen := map[string]bool{}
+	var files []string
+	add := func(file string) {
+		if unquoted, err := strconv.Unquote(file); err == nil {
+			file = unquoted
+		}
+		if file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		add(strings.TrimSpace(line))
+	}
+	for _, file := range GetChangedFiles() {
+		// renames are reported as "old -> new", both locations changed
+		for _, part := range strings.Split(file, " -> ") {
+			add(part)
+		}
+	}
+	return files, nil
+}
+
+// RepositoryRoot returns the top level folder of the git repository
+func RepositoryRoot() (string, error) {
+	cmd := execCommand("git", "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the repository root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GenerateFallbackMessage generates a commit message based on changed files
 func GenerateFallbackMessage(files []string) string {
 	if len(files) == 0 {
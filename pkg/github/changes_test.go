@@ -3,9 +3,11 @@ package github
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockExecCommand creates a mock exec.Command that runs a test helper
@@ -190,3 +192,45 @@ func TestGenerateFallbackMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestGetChangedFilesSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(file, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	}
+
+	git("init", "-q", "-b", "main")
+	write("api/main.go", "package main")
+	write("lib/lib.go", "package lib")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "feature")
+	write("lib/lib.go", "package lib // changed")
+	git("commit", "-q", "-am", "change lib")
+	write("web/index.ts", "export {}")
+
+	files, err := GetChangedFilesSince("main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lib/lib.go", "web/"}, files)
+
+	root, err := RepositoryRoot()
+	require.NoError(t, err)
+	resolved, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	assert.Equal(t, resolved, root)
+
+	_, err = GetChangedFilesSince("unknown-ref")
+	assert.ErrorContains(t, err, "failed to get the changes since unknown-ref")
+}
@@ -145,7 +145,7 @@ func (c *GithubContainer) Comment() error {
 
 	opts.Cmd = []string{"sh", "/tmp/script.sh"}
 	// opts.Cmd = []string{"pr", "comment", "4", "--repo", "containifyci/engine-ci-example", "--edit-last", "--body-file", "/src/trivy.json"}
-	opts.Env = []string{"GITHUB_TOKEN=" + container.GetEnv("CONTAINIFYCI_GITHUB_TOKEN")}
+	opts.Env = []string{"GITHUB_TOKEN=" + container.GetSecret("CONTAINIFYCI_GITHUB_TOKEN")}
 	err = c.Create(opts)
 	if err != nil {
 		return err
@@ -0,0 +1,85 @@
+package buildscript
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Base images supported by the prod_base property.
+const (
+	ProdBaseAlpine           = "alpine"
+	ProdBaseScratch          = "scratch"
+	ProdBaseDistrolessStatic = "distroless-static"
+	ProdBaseDistrolessBase   = "distroless-base"
+)
+
+// ProdUser is the numeric non-root user and group of the prod image.
+const ProdUser = "1121:11211"
+
+// certsImage provides the CA certificates and the timezone database.
+const certsImage = "gcr.io/distroless/static-debian12"
+
+var prodBaseImages = map[string]string{
+	ProdBaseAlpine:           "alpine:latest",
+	ProdBaseScratch:          "scratch",
+	ProdBaseDistrolessStatic: "gcr.io/distroless/static-debian12:nonroot",
+	ProdBaseDistrolessBase:   "gcr.io/distroless/base-debian12:nonroot",
+}
+
+// ProdImage describes the declaratively built Go prod image.
+type ProdImage struct {
+	App     string
+	Base    string
+	CACerts bool
+	TZData  bool
+}
+
+// BaseImage returns the image of the configured base, alpine is the default.
+func (p ProdImage) BaseImage() (string, error) {
+	base := p.Base
+	if base == "" {
+		base = ProdBaseAlpine
+	}
+	image, ok := prodBaseImages[base]
+	if !ok {
+		return "", fmt.Errorf("unknown prod_base %q supported are %s, %s, %s and %s", p.Base,
+			ProdBaseAlpine, ProdBaseScratch, ProdBaseDistrolessStatic, ProdBaseDistrolessBase)
+	}
+	return image, nil
+}
+
+// Dockerfile returns the Dockerfile of the prod image. The binary is selected
+// by the TARGETOS and TARGETARCH build args so the same Dockerfile works for
+// single and multi platform builds.
+func (p ProdImage) Dockerfile() (string, error) {
+	image, err := p.BaseImage()
+	if err != nil {
+		return "", err
+	}
+
+	// distroless already contains the CA certificates and the timezone
+	// database and alpine the CA certificates.
+	distroless := strings.HasPrefix(image, "gcr.io/distroless/")
+	caCerts := p.CACerts && !distroless && p.Base != ProdBaseAlpine && p.Base != ""
+	tzData := p.TZData && !distroless
+
+	var sb strings.Builder
+	if caCerts || tzData {
+		fmt.Fprintf(&sb, "FROM %s AS certs\n", certsImage)
+	}
+	fmt.Fprintf(&sb, "FROM %s\n", image)
+	if caCerts {
+		sb.WriteString("COPY --from=certs /etc/ssl/certs/ca-certificates.crt /etc/ssl/certs/ca-certificates.crt\n")
+	}
+	if tzData {
+		sb.WriteString("COPY --from=certs /usr/share/zoneinfo /usr/share/zoneinfo\n")
+	}
+	fmt.Fprintf(&sb, `ARG TARGETOS
+ARG TARGETARCH
+COPY --chown=%[2]s %[1]s-${TARGETOS}-${TARGETARCH} /app/%[1]s
+USER %[2]s
+WORKDIR /app
+CMD ["/app/%[1]s"]
+`, p.App, ProdUser)
+	return sb.String(), nil
+}
@@ -0,0 +1,66 @@
+package buildscript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProdImageDockerfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    ProdImage
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "default alpine",
+			image:    ProdImage{App: "app", CACerts: true},
+			contains: []string{"FROM alpine:latest\n", "COPY --chown=1121:11211 app-${TARGETOS}-${TARGETARCH} /app/app", "USER 1121:11211", `CMD ["/app/app"]`},
+			excludes: []string{"AS certs", "ca-certificates.crt"},
+		},
+		{
+			name:     "scratch with certs and tzdata",
+			image:    ProdImage{App: "app", Base: ProdBaseScratch, CACerts: true, TZData: true},
+			contains: []string{"FROM gcr.io/distroless/static-debian12 AS certs\nFROM scratch\n", "COPY --from=certs /etc/ssl/certs/ca-certificates.crt", "COPY --from=certs /usr/share/zoneinfo"},
+		},
+		{
+			name:     "scratch without certs",
+			image:    ProdImage{App: "app", Base: ProdBaseScratch},
+			contains: []string{"FROM scratch\n"},
+			excludes: []string{"AS certs"},
+		},
+		{
+			name:     "distroless already contains certs",
+			image:    ProdImage{App: "app", Base: ProdBaseDistrolessStatic, CACerts: true, TZData: true},
+			contains: []string{"FROM gcr.io/distroless/static-debian12:nonroot\n"},
+			excludes: []string{"AS certs"},
+		},
+		{
+			name:     "alpine with tzdata",
+			image:    ProdImage{App: "app", Base: ProdBaseAlpine, CACerts: true, TZData: true},
+			contains: []string{"COPY --from=certs /usr/share/zoneinfo"},
+			excludes: []string{"ca-certificates.crt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerfile, err := tt.image.Dockerfile()
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, dockerfile, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, dockerfile, s)
+			}
+			assert.NotContains(t, dockerfile, "RUN ")
+		})
+	}
+}
+
+func TestProdImageUnknownBase(t *testing.T) {
+	_, err := ProdImage{App: "app", Base: "ubuntu"}.Dockerfile()
+	assert.ErrorContains(t, err, "unknown prod_base")
+}
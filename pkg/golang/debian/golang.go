@@ -73,6 +73,8 @@ func new(build container.Build) *GoContainer {
 		slog.Debug("Different platform detected", "host", build.Platform.Host, "container", build.Platform.Container)
 		platforms = []*types.PlatformSpec{types.ParsePlatform("darwin/arm64"), types.ParsePlatform("linux/arm64")}
 	}
+	// the multi-arch prod image reuses the cross compiled binaries
+	platforms = types.AppendPlatforms(platforms, build.ProdPlatforms()...)
 	return &GoContainer{
 		App:       build.App,
 		Container: container.New(build),
@@ -210,7 +212,7 @@ func NewProd() build.BuildStep {
 }
 
 func (c *GoContainer) Prod() (string, error) {
-	if c.GetBuild().Env == container.LocalEnv {
+	if c.GetBuild().Env == container.LocalEnv && c.GetBuild().ExportFormat() == "" {
 		slog.Info("Skip building prod image in local environment")
 		return "", nil
 	}
@@ -227,73 +229,64 @@ func (c *GoContainer) Prod() (string, error) {
 		return image, nil
 	}
 
-	imageTag := "alpine"
-
-	opts := types.ContainerConfig{}
-	opts.Image = imageTag
-	opts.Env = []string{}
-	opts.Cmd = []string{"sleep", "300"}
-	opts.Platform = types.AutoPlatform
-	opts.WorkingDir = "/src"
-
-	err := c.Create(opts)
-	if err != nil {
-		slog.Error("Failed to create container: %s", "error", err)
-		os.Exit(1)
+	if platforms := c.GetBuild().PushPlatforms(); len(platforms) > 0 {
+		return c.ProdByPlatforms(platforms)
 	}
 
-	err = c.Start()
+	platform := c.GetBuild().Platform.Container
+	name := fmt.Sprintf("%s-%s-%s", c.App, platform.OS, platform.Architecture)
+	dockerfile, err := c.prodImage().Dockerfile()
 	if err != nil {
-		slog.Error("Failed to start container: %s", "error", err)
-		os.Exit(1)
-	}
-
-	err = c.Exec("addgroup", "-g", "11211", "app")
-	if err != nil {
-		slog.Error("Failed to execute command: %s", "error", err)
-		os.Exit(1)
+		slog.Error("Failed to generate prod Dockerfile", "error", err)
+		return c.ID, err
 	}
 
-	err = c.Exec("adduser", "-D", "-u", "1121", "-G", "app", "app")
+	image, err := c.BuildProdImage(dockerfile, map[string]string{name: filepath.Join(c.Folder, name)})
 	if err != nil {
-		slog.Error("Failed to execute command", "error", err)
-		os.Exit(1)
+		slog.Error("Failed to build prod image", "error", err)
+		return c.ID, err
 	}
+	return image, nil
+}
 
-	containerInfo, err := c.Inspect()
-	if err != nil {
-		slog.Error("Failed to inspect container", "error", err)
-		os.Exit(1)
+// prodImage returns the prod image configured with the prod_base,
+// prod_ca_certs and prod_tzdata properties.
+func (c *GoContainer) prodImage() buildscript.ProdImage {
+	b := c.GetBuild()
+	return buildscript.ProdImage{
+		App:     c.App,
+		Base:    b.Custom.String("prod_base"),
+		CACerts: b.Custom.Bool("prod_ca_certs", false),
+		TZData:  b.Custom.Bool("prod_tzdata", false),
 	}
+}
 
-	slog.Info("Container info", "name", containerInfo.Name, "image", containerInfo.Image, "arch", containerInfo.Platform.Container.Architecture, "os", containerInfo.Platform.Container.OS, "varian", containerInfo.Platform.Container.Variant)
-
-	err = c.CopyFileTo(fmt.Sprintf("%s/%s-%s-%s", c.Folder, c.App, containerInfo.Platform.Container.OS, containerInfo.Platform.Container.Architecture), fmt.Sprintf("/app/%s", c.App))
-	if err != nil {
-		slog.Error("Failed to copy file to container", "error", err)
-		os.Exit(1)
+// ProdByPlatforms builds the prod image for all platforms from the cross compiled
+// binaries and pushes it as manifest list.
+func (c *GoContainer) ProdByPlatforms(platforms []string) (string, error) {
+	if !c.GetBuild().ShouldPush() {
+		slog.Info("Skip multi-arch prod image it can only be built when pushing", "platforms", platforms)
+		return "", nil
 	}
 
-	imageId, err := c.Commit(fmt.Sprintf("%s:%s", c.Image, c.ImageTag), "Created from container", fmt.Sprintf("CMD [\"/app/%s\"]", c.App), "USER app", "WORKDIR /app")
-	if err != nil {
-		slog.Error("Failed to commit container", "error", err)
-		os.Exit(1)
+	files := map[string]string{}
+	for _, platform := range types.ParsePlatforms(platforms...) {
+		name := fmt.Sprintf("%s-%s-%s", c.App, platform.OS, platform.Architecture)
+		files[name] = filepath.Join(c.Folder, name)
 	}
 
-	err = c.Stop()
+	dockerfile, err := c.prodImage().Dockerfile()
 	if err != nil {
-		slog.Error("Failed to stop container: %s", "error", err)
-		os.Exit(1)
+		slog.Error("Failed to generate prod Dockerfile", "error", err)
+		return c.ID, err
 	}
 
-	imageUri := utils.ImageURI(c.GetBuild().Registry, c.Image, c.ImageTag)
-	err = c.Push(imageId, imageUri, container.PushOption{Remove: false})
+	image, err := c.BuildProdImageByPlatforms(dockerfile, files, platforms)
 	if err != nil {
-		slog.Error("Failed to push image: %s", "error", err)
-		os.Exit(1)
+		slog.Error("Failed to build multi-arch prod image", "error", err)
+		return c.ID, err
 	}
-
-	return c.ID, err
+	return image, nil
 }
 
 func (c *GoContainer) Run() (string, error) {
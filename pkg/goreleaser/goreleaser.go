@@ -162,7 +162,7 @@ func writeDefaultConfigContent(content []byte) (string, error) {
 var ErrMissingToken = fmt.Errorf("missing CONTAINIFYCI_GITHUB_TOKEN")
 
 func (c *GoReleaserContainer) Release(env container.EnvType) error {
-	token := container.GetEnv("CONTAINIFYCI_GITHUB_TOKEN")
+	token := container.GetSecret("CONTAINIFYCI_GITHUB_TOKEN")
 	if token == "" {
 		slog.Warn("Skip goreleaser missing CONTAINIFYCI_GITHUB_TOKEN")
 		return ErrMissingToken
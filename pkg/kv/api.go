@@ -1,11 +1,8 @@
 package kv
 
 import (
-	"crypto/hmac"
 	"crypto/rand"
-	"crypto/sha256"
 	"crypto/subtle"
-	"encoding/base64"
 	"fmt"
 	"io"
 	"log/slog"
@@ -20,17 +17,29 @@ import (
 
 type Server struct {
 	Listener   net.Listener
+	Artifacts  *ArtifactStore
 	Secret     string
 	signingKey string
 	Port       int
 }
 
-// In-memory key-value store
+// In-memory key-value store, optionally persisted to a file with Persist.
 type KeyValueStore struct {
-	store map[string]string
+	store map[string]entry
+	file  string
 	mu    sync.RWMutex
 }
 
+// entry is a stored value with an optional expiry.
+type entry struct {
+	Expires time.Time `json:"expires,omitzero"`
+	Value   string    `json:"value"`
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
 var kvStore *KeyValueStore
 
 // Define a sync.Once variable to ensure the singleton is only created once
@@ -40,9 +49,9 @@ func NewKeyValueStore() *KeyValueStore {
 	// Ensure that the singleton is initialized only once
 	once.Do(func() {
 		kvStore = &KeyValueStore{
-			store: make(map[string]string),
+			store: make(map[string]entry),
 		}
-		fmt.Println("Singleton instance created")
+		slog.Debug("Key value store created")
 	})
 	return kvStore
 }
@@ -50,26 +59,39 @@ func NewKeyValueStore() *KeyValueStore {
 func (kv *KeyValueStore) Clear() {
 	kv.mu.Lock()
 	defer kv.mu.Unlock()
-	kv.store = make(map[string]string)
+	kv.store = make(map[string]entry)
+	kv.save()
 }
 
 func (kv *KeyValueStore) GetVal(key string) (val string, ok bool) {
 	kv.mu.RLock()
 	defer kv.mu.RUnlock()
-	val, ok = kv.store[key]
-	return
+	e, ok := kv.store[key]
+	if !ok || e.expired(time.Now()) {
+		return "", false
+	}
+	return e.Value, true
 }
 
 func (kv *KeyValueStore) SetVal(key, val string) {
+	kv.SetValTTL(key, val, 0)
+}
+
+// SetValTTL stores the value for the given duration, a ttl of 0 never expires.
+func (kv *KeyValueStore) SetValTTL(key, val string, ttl time.Duration) {
+	e := entry{Value: val}
+	if ttl > 0 {
+		e.Expires = time.Now().Add(ttl)
+	}
 	kv.mu.Lock()
-	kv.store[key] = val
-	kv.mu.Unlock()
+	defer kv.mu.Unlock()
+	kv.store[key] = e
+	kv.save()
 }
 
 // Get retrieves a value by key from the key-value store.
 func (kv *KeyValueStore) Get(w http.ResponseWriter, r *http.Request) {
-	key := r.PathValue("key")
-	value, ok := kv.GetVal(key)
+	value, ok := kv.lookup(r, r.PathValue("key"))
 	if !ok {
 		http.Error(w, "Key not found", http.StatusNotFound)
 		return
@@ -80,16 +102,26 @@ func (kv *KeyValueStore) Get(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Set stores a value for a key in the key-value store.
+// Set stores a value for a key in the key-value store. The optional ttl
+// query parameter sets the expiry as duration, e.g. ?ttl=1h.
 func (kv *KeyValueStore) Set(w http.ResponseWriter, r *http.Request) {
-	key := r.PathValue("key")
+	key := requestKey(r, r.PathValue("key"))
 	value, err := io.ReadAll(r.Body)
 	if err != nil || len(value) == 0 {
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	kv.SetVal(key, string(value))
+	var ttl time.Duration
+	if v := r.URL.Query().Get("ttl"); v != "" {
+		ttl, err = time.ParseDuration(v)
+		if err != nil || ttl < 0 {
+			http.Error(w, "Invalid ttl", http.StatusBadRequest)
+			return
+		}
+	}
+
+	kv.SetValTTL(key, string(value), ttl)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -124,15 +156,31 @@ func authMiddleware(signingKey string, maxAge time.Duration, next http.Handler)
 			return
 		}
 		token := authHeader[len(bearerPrefix):]
-		if !ValidateToken(token, signingKey, maxAge) {
+		if ValidateToken(token, signingKey, maxAge) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		scope, ok := ValidateScopedToken(token, signingKey)
+		if !ok {
 			http.Error(w, "invalid auth", http.StatusUnauthorized)
 			return
 		}
-		next.ServeHTTP(w, r)
+		if key, write := resource(r, scope); key != "health" && !scope.Allows(key, write) {
+			slog.Warn("Denied access for scoped token", "build", scope.Build, "step", scope.Step, "method", r.Method, "key", key)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withScope(r.Context(), scope)))
 	})
 }
 
-func StartHttpServer(kvStore *KeyValueStore) (*Server, func(), error) {
+// Route is an additional authenticated endpoint of the engine server.
+type Route struct {
+	Handler http.Handler
+	Pattern string
+}
+
+func StartHttpServer(kvStore *KeyValueStore, routes ...Route) (*Server, func(), error) {
 	srv, err := getRandomPort()
 	if err != nil {
 		slog.Error("Failed to find available port", "error", err)
@@ -143,8 +191,22 @@ func StartHttpServer(kvStore *KeyValueStore) (*Server, func(), error) {
 	handler.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "okay")
 	})
-	handler.Handle("GET /mem/{key}", http.HandlerFunc(kvStore.Get))
-	handler.Handle("POST /mem/{key}", http.HandlerFunc(kvStore.Set))
+	handler.Handle("GET /mem", http.HandlerFunc(kvStore.List))
+	handler.Handle("GET /mem/{key...}", http.HandlerFunc(kvStore.Get))
+	handler.Handle("POST /mem/{key...}", http.HandlerFunc(kvStore.Set))
+	handler.Handle("DELETE /mem/{key...}", http.HandlerFunc(kvStore.Delete))
+
+	artifacts, err := NewArtifactStore(DefaultMaxArtifactSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	srv.Artifacts = artifacts
+	handler.Handle("PUT /artifacts/{build}/{name}", http.HandlerFunc(artifacts.HandlePut))
+	handler.Handle("GET /artifacts/{build}/{name}", http.HandlerFunc(artifacts.HandleGet))
+	handler.Handle("GET /artifacts/{build}", http.HandlerFunc(artifacts.HandleList))
+	for _, route := range routes {
+		handler.Handle(route.Pattern, route.Handler)
+	}
 
 	srv.signingKey = randomString(32)
 	srv.Secret = GenerateToken(srv.signingKey)
@@ -184,11 +246,7 @@ func GenerateToken(secret string) string {
 	nonce := randomString(16)
 	payload := fmt.Sprintf("%d.%s", timestamp, nonce)
 
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write([]byte(payload))
-	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
-
-	return fmt.Sprintf("%s.%s", payload, signature)
+	return fmt.Sprintf("%s.%s", payload, sign(secret, payload))
 }
 
 // ValidateToken checks if the token is valid and not expired.
@@ -213,9 +271,7 @@ func ValidateToken(token, secret string, maxAge time.Duration) bool {
 
 	// Recompute signature
 	payload := fmt.Sprintf("%d.%s", timestamp, nonce)
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write([]byte(payload))
-	expectedSig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	expectedSig := sign(secret, payload)
 
 	// Constant-time comparison
 	return subtle.ConstantTimeCompare([]byte(providedSig), []byte(expectedSig)) == 1
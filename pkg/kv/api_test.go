@@ -97,6 +97,23 @@ func TestKeyValueStore_Set_InvalidRequest(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "Expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
 }
 
+func TestKeyValueStore_Set_TTL(t *testing.T) {
+	store := &KeyValueStore{store: map[string]entry{}}
+
+	req := httptest.NewRequest(http.MethodPost, "/mem/foo?ttl=1h", bytes.NewBufferString("bar"))
+	req.SetPathValue("key", "foo")
+	w := httptest.NewRecorder()
+	store.Set(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), store.store["foo"].Expires, time.Minute)
+
+	req = httptest.NewRequest(http.MethodPost, "/mem/foo?ttl=soon", bytes.NewBufferString("bar"))
+	req.SetPathValue("key", "foo")
+	w = httptest.NewRecorder()
+	store.Set(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
 func TestStartHttpServer(t *testing.T) {
 	srv, fnc, err := StartHttpServer(NewKeyValueStore())
 	assert.NoError(t, err, "Failed to start HTTP server")
@@ -0,0 +1,191 @@
+package kv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxArtifactSize is the maximum size of a single artifact.
+	DefaultMaxArtifactSize int64 = 1 << 30
+	// DigestHeader carries the sha256 digest of an artifact, e.g. sha256:abc...
+	DigestHeader = "X-Artifact-Digest"
+)
+
+var (
+	ErrArtifactNotFound = errors.New("artifact not found")
+	ErrArtifactTooLarge = errors.New("artifact too large")
+	ErrDigestMismatch   = errors.New("artifact digest mismatch")
+	ErrInvalidArtifact  = errors.New("invalid artifact name")
+)
+
+// Artifact is a file exchanged between steps and builds through the engine.
+type Artifact struct {
+	Created time.Time `json:"created"`
+	Build   string    `json:"build"`
+	Name    string    `json:"name"`
+	Digest  string    `json:"digest"`
+	Size    int64     `json:"size"`
+}
+
+// ArtifactStore stores the artifacts of a run in a temporary folder.
+type ArtifactStore struct {
+	artifacts map[string]Artifact
+	dir       string
+	maxSize   int64
+	mu        sync.RWMutex
+}
+
+func NewArtifactStore(maxSize int64) (*ArtifactStore, error) {
+	dir, err := os.MkdirTemp("", "engine-ci-artifacts-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact folder: %w", err)
+	}
+	return &ArtifactStore{
+		artifacts: map[string]Artifact{},
+		dir:       dir,
+		maxSize:   maxSize,
+	}, nil
+}
+
+// Close removes all stored artifacts.
+func (s *ArtifactStore) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+func validSegment(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
+
+func (s *ArtifactStore) path(build, name string) string {
+	return filepath.Join(s.dir, build, name)
+}
+
+// Put streams the artifact into the store. An expected digest is verified.
+func (s *ArtifactStore) Put(build, name string, r io.Reader, expectedDigest string) (*Artifact, error) {
+	if !validSegment(build) || !validSegment(name) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrInvalidArtifact, build, name)
+	}
+	if err := os.MkdirAll(filepath.Join(s.dir, build), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create artifact folder: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, build), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	// read one byte more than allowed to detect too large artifacts
+	size, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, s.maxSize+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+	if size > s.maxSize {
+		return nil, ErrArtifactTooLarge
+	}
+
+	digest := "sha256:" + hex.EncodeToString(h.Sum(nil))
+	if expectedDigest != "" && expectedDigest != digest {
+		return nil, fmt.Errorf("%w: expected %s got %s", ErrDigestMismatch, expectedDigest, digest)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Rename(tmp.Name(), s.path(build, name)); err != nil {
+		return nil, fmt.Errorf("failed to store artifact: %w", err)
+	}
+	artifact := Artifact{Build: build, Name: name, Digest: digest, Size: size, Created: time.Now()}
+	s.artifacts[build+"/"+name] = artifact
+	return &artifact, nil
+}
+
+// Open returns the artifact content, the caller has to close the file.
+func (s *ArtifactStore) Open(build, name string) (*os.File, *Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	artifact, ok := s.artifacts[build+"/"+name]
+	if !ok {
+		return nil, nil, ErrArtifactNotFound
+	}
+	f, err := os.Open(s.path(build, name))
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, &artifact, nil
+}
+
+// List returns the artifacts of the build sorted by name.
+func (s *ArtifactStore) List(build string) []Artifact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	artifacts := []Artifact{}
+	for _, a := range s.artifacts {
+		if a.Build == build {
+			artifacts = append(artifacts, a)
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+	return artifacts
+}
+
+// HandlePut stores the request body as artifact.
+func (s *ArtifactStore) HandlePut(w http.ResponseWriter, r *http.Request) {
+	artifact, err := s.Put(r.PathValue("build"), r.PathValue("name"), r.Body, r.Header.Get(DigestHeader))
+	switch {
+	case errors.Is(err, ErrArtifactTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, ErrDigestMismatch), errors.Is(err, ErrInvalidArtifact):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		slog.Error("Failed to store artifact", "error", err)
+		http.Error(w, "Failed to store artifact", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(DigestHeader, artifact.Digest)
+	writeJSON(w, artifact)
+}
+
+// HandleGet streams the artifact with its digest in the X-Artifact-Digest header.
+func (s *ArtifactStore) HandleGet(w http.ResponseWriter, r *http.Request) {
+	f, artifact, err := s.Open(r.PathValue("build"), r.PathValue("name"))
+	if err != nil {
+		http.Error(w, "Artifact not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set(DigestHeader, artifact.Digest)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, artifact.Name, artifact.Created, f)
+}
+
+// HandleList returns the artifacts of the build as JSON.
+func (s *ArtifactStore) HandleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.List(r.PathValue("build")))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Failed to write response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,104 @@
+package kv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func artifactURL(host, build, name string) string {
+	return fmt.Sprintf("http://%s/artifacts/%s/%s", host, build, name)
+}
+
+func doArtifactRequest(req *http.Request, auth string) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+auth)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("artifact request failed: %s %s", resp.Status, body)
+	}
+	return resp, nil
+}
+
+// UploadArtifact streams the content as artifact of the build to the engine.
+func UploadArtifact(host, auth, build, name string, content io.Reader) (*Artifact, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, artifactURL(host, build, name), content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := doArtifactRequest(req, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var artifact Artifact
+	if err := json.NewDecoder(resp.Body).Decode(&artifact); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &artifact, nil
+}
+
+// DownloadArtifact streams the artifact into the writer and verifies its digest.
+func DownloadArtifact(host, auth, build, name string, w io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, artifactURL(host, build, name), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := doArtifactRequest(req, auth)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read artifact: %w", err)
+	}
+	digest := "sha256:" + hex.EncodeToString(h.Sum(nil))
+	if expected := resp.Header.Get(DigestHeader); expected != "" && expected != digest {
+		return "", fmt.Errorf("%w: expected %s got %s", ErrDigestMismatch, expected, digest)
+	}
+	return digest, nil
+}
+
+// ListArtifacts returns the artifacts published by the build.
+func ListArtifacts(host, auth, build string) ([]Artifact, error) {
+	url := fmt.Sprintf("http://%s/artifacts/%s", host, build)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := doArtifactRequest(req, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var artifacts []Artifact
+	if err := json.NewDecoder(resp.Body).Decode(&artifacts); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return artifacts, nil
+}
+
+// PublishArtifactScript returns the shell command to publish a file as
+// artifact from inside a build container. The file is streamed with -T
+// instead of being read into memory by curl.
+func PublishArtifactScript(build, name, file string) string {
+	return fmt.Sprintf(`curl -fsS -H "Authorization: Bearer ${CONTAINIFYCI_AUTH}" -T %q "${CONTAINIFYCI_HOST}/artifacts/%s/%s"`, file, build, name)
+}
+
+// FetchArtifactScript returns the shell command to fetch an artifact into
+// a file from inside a build container and to verify its digest.
+func FetchArtifactScript(build, name, file string) string {
+	return fmt.Sprintf(`digest=$(curl -fsS -D - -o %[3]q -H "Authorization: Bearer ${CONTAINIFYCI_AUTH}" "${CONTAINIFYCI_HOST}/artifacts/%[1]s/%[2]s" | tr -d '\r' | sed -n 's/^%[4]s: sha256://p') && echo "${digest}  %[3]s" | sha256sum -c -`, build, name, file, DigestHeader)
+}
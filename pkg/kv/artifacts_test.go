@@ -0,0 +1,132 @@
+package kv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newArtifactServer(t *testing.T, maxSize int64) (*ArtifactStore, string) {
+	t.Helper()
+	store, err := NewArtifactStore(maxSize)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /artifacts/{build}/{name}", store.HandlePut)
+	mux.HandleFunc("GET /artifacts/{build}/{name}", store.HandleGet)
+	mux.HandleFunc("GET /artifacts/{build}", store.HandleList)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return store, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestArtifactUploadDownload(t *testing.T) {
+	_, host := newArtifactServer(t, DefaultMaxArtifactSize)
+	content := []byte("binary\x00content")
+	sum := sha256.Sum256(content)
+	expected := "sha256:" + hex.EncodeToString(sum[:])
+
+	artifact, err := UploadArtifact(host, "token", "app", "app-linux-amd64", bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, expected, artifact.Digest)
+	assert.Equal(t, int64(len(content)), artifact.Size)
+
+	var buf bytes.Buffer
+	digest, err := DownloadArtifact(host, "token", "app", "app-linux-amd64", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, expected, digest)
+	assert.Equal(t, content, buf.Bytes())
+
+	_, err = UploadArtifact(host, "token", "app", "coverage.out", strings.NewReader("mode: set"))
+	require.NoError(t, err)
+
+	artifacts, err := ListArtifacts(host, "token", "app")
+	require.NoError(t, err)
+	require.Len(t, artifacts, 2)
+	assert.Equal(t, "app-linux-amd64", artifacts[0].Name)
+	assert.Equal(t, expected, artifacts[0].Digest)
+	assert.Equal(t, "coverage.out", artifacts[1].Name)
+
+	artifacts, err = ListArtifacts(host, "token", "other")
+	require.NoError(t, err)
+	assert.Empty(t, artifacts)
+}
+
+func TestArtifactDownloadNotFound(t *testing.T) {
+	_, host := newArtifactServer(t, DefaultMaxArtifactSize)
+	_, err := DownloadArtifact(host, "token", "app", "missing", &bytes.Buffer{})
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestDownloadArtifactScopedToken(t *testing.T) {
+	secret := "test-secret"
+	store, err := NewArtifactStore(DefaultMaxArtifactSize)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /artifacts/{build}/{name}", store.HandlePut)
+	mux.HandleFunc("GET /artifacts/{build}/{name}", store.HandleGet)
+	srv := httptest.NewServer(authMiddleware(secret, time.Hour, mux))
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	lib := GenerateScopedToken(secret, StepScope("lib", "zig", time.Hour))
+	app := GenerateScopedToken(secret, StepScope("app", "dockerfile", time.Hour))
+
+	_, err = UploadArtifact(host, lib, "lib", "lib-linux-amd64", strings.NewReader("binary"))
+	require.NoError(t, err)
+
+	// a later build fetches the artifact of another build
+	var buf bytes.Buffer
+	_, err = DownloadArtifact(host, app, "lib", "lib-linux-amd64", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "binary", buf.String())
+
+	// but can not replace it
+	_, err = UploadArtifact(host, app, "lib", "lib-linux-amd64", strings.NewReader("other"))
+	assert.ErrorContains(t, err, "403")
+
+	github := GenerateScopedToken(secret, StepScope("app", "github", time.Hour))
+	_, err = DownloadArtifact(host, github, "lib", "lib-linux-amd64", &bytes.Buffer{})
+	assert.ErrorContains(t, err, "403")
+}
+
+func TestArtifactTooLarge(t *testing.T) {
+	_, host := newArtifactServer(t, 4)
+	_, err := UploadArtifact(host, "token", "app", "big", strings.NewReader("12345"))
+	assert.ErrorContains(t, err, "413")
+
+	_, err = UploadArtifact(host, "token", "app", "small", strings.NewReader("1234"))
+	assert.NoError(t, err)
+}
+
+func TestArtifactPut(t *testing.T) {
+	store, err := NewArtifactStore(DefaultMaxArtifactSize)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Put("app", "..", strings.NewReader("x"), "")
+	assert.ErrorIs(t, err, ErrInvalidArtifact)
+
+	_, err = store.Put("app", "file", strings.NewReader("x"), "sha256:0000")
+	assert.ErrorIs(t, err, ErrDigestMismatch)
+
+	_, _, err = store.Open("app", "file")
+	assert.ErrorIs(t, err, ErrArtifactNotFound)
+}
+
+func TestArtifactScripts(t *testing.T) {
+	assert.Equal(t, `curl -fsS -H "Authorization: Bearer ${CONTAINIFYCI_AUTH}" -T "/src/app" "${CONTAINIFYCI_HOST}/artifacts/app/app-linux"`,
+		PublishArtifactScript("app", "app-linux", "/src/app"))
+	assert.Contains(t, FetchArtifactScript("app", "app-linux", "/src/app"), `sha256sum -c -`)
+}
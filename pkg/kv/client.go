@@ -3,9 +3,12 @@ package kv
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"time"
 )
 
 // GetValue retrieves a value from the KV store via HTTP
@@ -43,7 +46,15 @@ func GetValue(host, auth, key string) (string, error) {
 
 // SetValue stores a value in the KV store via HTTP
 func SetValue(host, auth, key, value string) error {
+	return SetValueTTL(host, auth, key, value, 0)
+}
+
+// SetValueTTL stores a value in the KV store via HTTP that expires after the ttl.
+func SetValueTTL(host, auth, key, value string, ttl time.Duration) error {
 	url := fmt.Sprintf("http://%s/mem/%s", host, key)
+	if ttl > 0 {
+		url += "?ttl=" + ttl.String()
+	}
 
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewBuffer([]byte(value)))
 	if err != nil {
@@ -64,3 +75,55 @@ func SetValue(host, auth, key, value string) error {
 
 	return nil
 }
+
+// DeleteValue removes a key from the KV store via HTTP
+func DeleteValue(host, auth, key string) error {
+	url := fmt.Sprintf("http://%s/mem/%s", host, key)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+auth)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete key: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// ListKeys returns the keys starting with the prefix from the KV store via HTTP
+func ListKeys(host, auth, prefix string) ([]string, error) {
+	url := fmt.Sprintf("http://%s/mem?prefix=%s", host, neturl.QueryEscape(prefix))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+auth)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list keys: %s", resp.Status)
+	}
+
+	var keys []string
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to decode keys: %w", err)
+	}
+	return keys, nil
+}
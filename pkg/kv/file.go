@@ -0,0 +1,125 @@
+package kv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultFile is the file of the persistent store shared across runs. It is
+// kept in its own git ignored folder next to the committed build configuration.
+const DefaultFile = ".containifyci/.kv/kv.json"
+
+// Persist loads the entries from the file and writes every change back to it
+// so that values survive the engine run. The file is only readable by the
+// owner as it holds tokens, values set with a ttl expire in the file as well.
+func (kv *KeyValueStore) Persist(file string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read kv store %s: %w", file, err)
+	}
+
+	stored := map[string]entry{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to parse kv store %s: %w", file, err)
+		}
+	}
+
+	now := time.Now()
+	for k, e := range stored {
+		if _, ok := kv.store[k]; ok || e.expired(now) {
+			continue
+		}
+		kv.store[k] = e
+	}
+	kv.file = file
+	if err := ignoreFolder(filepath.Dir(file)); err != nil {
+		return err
+	}
+	return kv.write()
+}
+
+// EventsTokenFile holds the token to read the event stream during the run.
+const EventsTokenFile = ".containifyci/.kv/events.token"
+
+// WriteToken writes the token to a file only readable by the owner.
+func WriteToken(file, token string) error {
+	if err := ignoreFolder(filepath.Dir(file)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, []byte(token), 0600); err != nil {
+		return fmt.Errorf("failed to write token %s: %w", file, err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(file, 0600)
+}
+
+// ignoreFolder keeps the folder of the store out of git.
+func ignoreFolder(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create kv store folder: %w", err)
+	}
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", ignore, err)
+		}
+	}
+	return nil
+}
+
+// save persists the store, has to be called with the lock held.
+func (kv *KeyValueStore) save() {
+	if kv.file == "" {
+		return
+	}
+	if err := kv.write(); err != nil {
+		slog.Warn("Failed to persist kv store", "file", kv.file, "error", err)
+	}
+}
+
+// write replaces the file atomically, has to be called with the lock held.
+func (kv *KeyValueStore) write() error {
+	now := time.Now()
+	entries := make(map[string]entry, len(kv.store))
+	for k, e := range kv.store {
+		if !e.expired(now) {
+			entries[k] = e
+		}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal kv store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(kv.file), 0700); err != nil {
+		return fmt.Errorf("failed to create kv store folder: %w", err)
+	}
+
+	// the temp file is created with 0600 as the store can contain tokens
+	tmp, err := os.CreateTemp(filepath.Dir(kv.file), ".kv-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create kv store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write kv store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write kv store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), kv.file); err != nil {
+		return fmt.Errorf("failed to write kv store: %w", err)
+	}
+	return nil
+}
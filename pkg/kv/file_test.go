@@ -0,0 +1,92 @@
+package kv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".containifyci", ".kv", "kv.json")
+
+	store := &KeyValueStore{store: map[string]entry{}}
+	require.NoError(t, store.Persist(file))
+	store.SetVal("last_commit", "abc123")
+	store.SetValTTL("token", "secret", time.Hour)
+	store.SetVal("global/accesstoken", "ya29")
+	store.SetValTTL("expired", "old", time.Nanosecond)
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	ignore, err := os.ReadFile(filepath.Join(filepath.Dir(file), ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "*\n", string(ignore))
+
+	// a new run loads the persisted values
+	next := &KeyValueStore{store: map[string]entry{}}
+	require.NoError(t, next.Persist(file))
+
+	val, ok := next.GetVal("last_commit")
+	assert.True(t, ok)
+	assert.Equal(t, "abc123", val)
+
+	val, ok = next.GetVal("global/accesstoken")
+	assert.True(t, ok)
+	assert.Equal(t, "ya29", val)
+
+	// the ttl survives the run
+	next.mu.RLock()
+	expires := next.store["token"].Expires
+	next.mu.RUnlock()
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	_, ok = next.GetVal("expired")
+	assert.False(t, ok)
+}
+
+func TestPersistKeepsCurrentValues(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "kv.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"key":{"value":"old"}}`), 0600))
+
+	store := &KeyValueStore{store: map[string]entry{"key": {Value: "new"}}}
+	require.NoError(t, store.Persist(file))
+
+	val, _ := store.GetVal("key")
+	assert.Equal(t, "new", val)
+}
+
+func TestPersistInvalidFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "kv.json")
+	require.NoError(t, os.WriteFile(file, []byte("not json"), 0600))
+
+	store := &KeyValueStore{store: map[string]entry{}}
+	assert.ErrorContains(t, store.Persist(file), "failed to parse kv store")
+}
+
+func TestWriteToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".kv", "events.token")
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0700))
+	require.NoError(t, os.WriteFile(file, []byte("old"), 0644))
+
+	require.NoError(t, WriteToken(file, "token"))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "token", string(data))
+}
+
+func TestGetValExpired(t *testing.T) {
+	store := &KeyValueStore{store: map[string]entry{}}
+	store.SetValTTL("key", "value", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	_, ok := store.GetVal("key")
+	assert.False(t, ok)
+}
@@ -0,0 +1,129 @@
+package kv
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GlobalNamespace holds the keys shared by all builds, e.g. global/github_token.
+const GlobalNamespace = "global"
+
+type scopeKey struct{}
+
+func withScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+func scopeFrom(ctx context.Context) *Scope {
+	scope, _ := ctx.Value(scopeKey{}).(*Scope)
+	return scope
+}
+
+// Namespace returns the stored key of a key used by the build. Keys are
+// stored in the namespace of the build unless they address the global namespace.
+func Namespace(build, key string) string {
+	if build == "" || key == GlobalNamespace || strings.HasPrefix(key, GlobalNamespace+"/") {
+		return key
+	}
+	return build + "/" + key
+}
+
+// requestKey returns the stored key of the request. Requests with a scoped
+// token are namespaced by the build of the token, the engine token uses the key as is.
+func requestKey(r *http.Request, key string) string {
+	if scope := scopeFrom(r.Context()); scope != nil {
+		return Namespace(scope.Build, key)
+	}
+	return key
+}
+
+// lookup returns the value of the request key. Requests with a scoped token
+// resolve plain keys in the namespace of the build and then in the global
+// namespace, each resolved key has to be readable with the token.
+func (kv *KeyValueStore) lookup(r *http.Request, key string) (string, bool) {
+	scope := scopeFrom(r.Context())
+	if scope == nil || strings.Contains(key, "/") {
+		return kv.GetVal(requestKey(r, key))
+	}
+	for _, k := range []string{Namespace(scope.Build, key), GlobalNamespace + "/" + key} {
+		if !scope.Allows(k, false) {
+			continue
+		}
+		if v, ok := kv.GetVal(k); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// visibleKey reverts Namespace for the keys returned to a scoped request.
+func visibleKey(r *http.Request, key string) string {
+	if scope := scopeFrom(r.Context()); scope != nil {
+		if k, ok := strings.CutPrefix(key, scope.Build+"/"); ok {
+			return k
+		}
+	}
+	return key
+}
+
+// Resolve looks up the key in the namespace of the build, then in the global
+// namespace and at last the key as is. Keys with a namespace are looked up directly.
+func (kv *KeyValueStore) Resolve(build, key string) (string, bool) {
+	if strings.Contains(key, "/") {
+		return kv.GetVal(key)
+	}
+	for _, k := range []string{Namespace(build, key), GlobalNamespace + "/" + key, key} {
+		if v, ok := kv.GetVal(k); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Keys returns the sorted not expired keys starting with the prefix.
+func (kv *KeyValueStore) Keys(prefix string) []string {
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+	now := time.Now()
+	keys := []string{}
+	for k, e := range kv.store {
+		if strings.HasPrefix(k, prefix) && !e.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DeleteVal removes the key and reports whether it existed.
+func (kv *KeyValueStore) DeleteVal(key string) bool {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	_, ok := kv.store[key]
+	if ok {
+		delete(kv.store, key)
+		kv.save()
+	}
+	return ok
+}
+
+// List returns the keys starting with the prefix query parameter as JSON.
+func (kv *KeyValueStore) List(w http.ResponseWriter, r *http.Request) {
+	keys := kv.Keys(requestKey(r, r.URL.Query().Get("prefix")))
+	for i, k := range keys {
+		keys[i] = visibleKey(r, k)
+	}
+	writeJSON(w, keys)
+}
+
+// Delete removes a key from the key-value store.
+func (kv *KeyValueStore) Delete(w http.ResponseWriter, r *http.Request) {
+	if !kv.DeleteVal(requestKey(r, r.PathValue("key"))) {
+		http.Error(w, "Key not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package kv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNamespaceServer(t *testing.T, store *KeyValueStore, secret string) string {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /mem", store.List)
+	mux.HandleFunc("GET /mem/{key...}", store.Get)
+	mux.HandleFunc("POST /mem/{key...}", store.Set)
+	mux.HandleFunc("DELETE /mem/{key...}", store.Delete)
+	server := httptest.NewServer(authMiddleware(secret, time.Hour, mux))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestNamespacedKeys(t *testing.T) {
+	secret := "test-secret"
+	store := &KeyValueStore{store: map[string]entry{}}
+	host := newNamespaceServer(t, store, secret)
+
+	app1 := GenerateScopedToken(secret, StepScope("app1", "golang", time.Hour))
+	app2 := GenerateScopedToken(secret, StepScope("app2", "golang", time.Hour))
+
+	require.NoError(t, SetValue(host, app1, "commit_message", "from app1"))
+	require.NoError(t, SetValue(host, app2, "commit_message", "from app2"))
+	require.NoError(t, SetValue(host, GenerateToken(secret), "global/github_token", "shared"))
+	// the global namespace is read-only for the builds
+	assert.Error(t, SetValue(host, app1, "global/github_token", "overwritten"))
+
+	v, err := GetValue(host, app1, "commit_message")
+	require.NoError(t, err)
+	assert.Equal(t, "from app1", v)
+
+	v, err = GetValue(host, app2, "commit_message")
+	require.NoError(t, err)
+	assert.Equal(t, "from app2", v)
+
+	v, err = GetValue(host, app2, "global/github_token")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", v)
+
+	// the other namespace can not be addressed with a scoped token
+	v, err = GetValue(host, app2, "app1/commit_message")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	keys, err := ListKeys(host, app1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"commit_message"}, keys)
+
+	keys, err = ListKeys(host, app1, "global/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global/github_token"}, keys)
+
+	keys, err = ListKeys(host, GenerateToken(secret), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app1/commit_message", "app2/commit_message", "global/github_token"}, keys)
+
+	require.NoError(t, DeleteValue(host, app1, "commit_message"))
+	_, ok := store.GetVal("app1/commit_message")
+	assert.False(t, ok)
+	_, ok = store.GetVal("app2/commit_message")
+	assert.True(t, ok)
+
+	github := GenerateScopedToken(secret, StepScope("app2", "github", time.Hour))
+	v, err = GetValue(host, github, "commit_message")
+	require.NoError(t, err)
+	assert.Equal(t, "from app2", v)
+	assert.Error(t, DeleteValue(host, github, "commit_message"))
+	_, err = ListKeys(host, github, "")
+	assert.Error(t, err)
+
+	// values written with the engine token into the global namespace are resolved
+	app3 := GenerateScopedToken(secret, StepScope("app3", "github", time.Hour))
+	require.NoError(t, SetValue(host, GenerateToken(secret), "commit_message", "from engine"))
+	v, err = GetValue(host, app3, "commit_message")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+
+	require.NoError(t, SetValue(host, GenerateToken(secret), "global/commit_message", "from global"))
+	v, err = GetValue(host, app3, "commit_message")
+	require.NoError(t, err)
+	assert.Equal(t, "from global", v)
+
+	// the fallback to the global namespace is checked against the token permissions
+	require.NoError(t, SetValue(host, GenerateToken(secret), "global/accesstoken", "ya29"))
+	buildOnly := GenerateScopedToken(secret, Scope{
+		Build:       "app3",
+		Permissions: []Permission{ReadOnly("app3/")},
+		Expires:     time.Now().Add(time.Hour).Unix(),
+	})
+	v, err = GetValue(host, buildOnly, "accesstoken")
+	require.NoError(t, err)
+	assert.Empty(t, v)
+	v, err = GetValue(host, app2, "accesstoken")
+	require.NoError(t, err)
+	assert.Equal(t, "ya29", v)
+
+	v, err = GetValue(host, app2, "commit_message")
+	require.NoError(t, err)
+	assert.Equal(t, "from app2", v)
+}
+
+func TestResolve(t *testing.T) {
+	store := &KeyValueStore{store: map[string]entry{}}
+	store.SetVal("app/token", "app")
+	store.SetVal("global/token", "global")
+	store.SetVal("global/shared", "shared")
+	store.SetVal("legacy", "flat")
+
+	tests := []struct {
+		build string
+		key   string
+		want  string
+	}{
+		{"app", "token", "app"},
+		{"other", "token", "global"},
+		{"app", "shared", "shared"},
+		{"app", "legacy", "flat"},
+		{"", "token", "global"},
+		{"other", "app/token", "app"},
+		{"app", "global/token", "global"},
+	}
+	for _, tt := range tests {
+		v, ok := store.Resolve(tt.build, tt.key)
+		assert.True(t, ok, tt.key)
+		assert.Equal(t, tt.want, v, tt.key)
+	}
+
+	_, ok := store.Resolve("app", "missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,168 @@
+package kv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Permission grants read and/or write access to all keys starting with Prefix.
+// Artifacts are addressed as artifacts/{build}/{name} and the event stream as events.
+type Permission struct {
+	Prefix string `json:"p"`
+	Read   bool   `json:"r,omitempty"`
+	Write  bool   `json:"w,omitempty"`
+}
+
+// Scope restricts a token to a build step and the keys it is allowed to access.
+type Scope struct {
+	Build       string       `json:"b"`
+	Step        string       `json:"s"`
+	Permissions []Permission `json:"perms"`
+	Expires     int64        `json:"exp"`
+}
+
+// ReadWrite grants full access to the keys with the prefix.
+func ReadWrite(prefix string) Permission {
+	return Permission{Prefix: prefix, Read: true, Write: true}
+}
+
+// ReadOnly grants read access to the keys with the prefix.
+func ReadOnly(prefix string) Permission {
+	return Permission{Prefix: prefix, Read: true}
+}
+
+// stepPermissions restricts steps that only need a few keys, the prefixes are
+// relative to the namespace of the build. All other steps get defaultPermissions.
+var stepPermissions = map[string][]Permission{
+	"github": {
+		ReadOnly("commit_message"), ReadOnly("github_token"),
+		ReadOnly(GlobalNamespace + "/commit_message"), ReadOnly(GlobalNamespace + "/github_token"),
+	},
+}
+
+func defaultPermissions(build string) []Permission {
+	return []Permission{
+		ReadWrite(build + "/"),
+		// the global namespace is written by the engine only
+		ReadOnly(GlobalNamespace + "/"),
+		ReadWrite("artifacts/" + build + "/"),
+		// later builds fetch the artifacts of the builds before them
+		ReadOnly("artifacts/"),
+		ReadOnly("events"),
+	}
+}
+
+// StepScope returns the scope of a build step valid for the given duration.
+func StepScope(build, step string, ttl time.Duration) Scope {
+	perms := defaultPermissions(build)
+	if relative, ok := stepPermissions[step]; ok {
+		perms = make([]Permission, 0, len(relative))
+		for _, p := range relative {
+			p.Prefix = Namespace(build, p.Prefix)
+			perms = append(perms, p)
+		}
+	}
+	return Scope{
+		Build:       build,
+		Step:        step,
+		Permissions: perms,
+		Expires:     time.Now().Add(ttl).Unix(),
+	}
+}
+
+// EventsTokenTTL is the lifetime of the token to read the event stream, it
+// does not outlive the engine token of the run.
+const EventsTokenTTL = TokenMaxAge
+
+// EventsScope returns the scope of a token that can only read the event stream.
+func EventsScope(ttl time.Duration) Scope {
+	return Scope{
+		Step:        "events",
+		Permissions: []Permission{ReadOnly("events")},
+		Expires:     time.Now().Add(ttl).Unix(),
+	}
+}
+
+// Allows reports whether the scope grants access to the resource.
+func (s Scope) Allows(resource string, write bool) bool {
+	for _, p := range s.Permissions {
+		if !strings.HasPrefix(resource, p.Prefix) {
+			continue
+		}
+		if (write && p.Write) || (!write && p.Read) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateScopedToken creates a signed token restricted to the scope.
+// Format: {base64 scope}.{signature}
+func GenerateScopedToken(secret string, scope Scope) string {
+	data, err := json.Marshal(scope)
+	if err != nil {
+		// a Scope always marshals
+		panic(err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + sign(secret, payload)
+}
+
+// ValidateScopedToken returns the scope of a valid and not expired scoped token.
+func ValidateScopedToken(token, secret string) (*Scope, bool) {
+	payload, providedSig, ok := strings.Cut(token, ".")
+	if !ok || strings.Contains(providedSig, ".") {
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(providedSig), []byte(sign(secret, payload))) != 1 {
+		return nil, false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, false
+	}
+	var scope Scope
+	if err := json.Unmarshal(data, &scope); err != nil {
+		return nil, false
+	}
+	if time.Now().Unix() > scope.Expires {
+		return nil, false
+	}
+	return &scope, true
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// resource maps the request to the namespaced key checked against the token
+// permissions and reports whether the request writes.
+func resource(r *http.Request, scope *Scope) (string, bool) {
+	write := r.Method != http.MethodGet && r.Method != http.MethodHead
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "mem" {
+		return Namespace(scope.Build, r.URL.Query().Get("prefix")), write
+	}
+	if key, ok := strings.CutPrefix(path, "mem/"); ok {
+		return Namespace(scope.Build, key), write
+	}
+	// listing artifacts/{build} is covered by the artifacts/{build}/ permission
+	if build, ok := strings.CutPrefix(path, "artifacts/"); ok && !strings.Contains(build, "/") {
+		return path + "/", write
+	}
+	return path, write
+}
+
+// Token mints a scoped token signed by the server.
+func (s *Server) Token(scope Scope) string {
+	return GenerateScopedToken(s.signingKey, scope)
+}
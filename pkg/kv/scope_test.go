@@ -0,0 +1,110 @@
+package kv
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedToken(t *testing.T) {
+	secret := "test-secret"
+	scope := StepScope("app", "github", time.Hour)
+	token := GenerateScopedToken(secret, scope)
+
+	got, ok := ValidateScopedToken(token, secret)
+	assert.True(t, ok)
+	assert.Equal(t, &scope, got)
+
+	_, ok = ValidateScopedToken(token, "other-secret")
+	assert.False(t, ok)
+
+	assert.False(t, ValidateToken(token, secret, time.Hour), "scoped token is no root token")
+
+	_, ok = ValidateScopedToken(GenerateScopedToken(secret, StepScope("app", "golang", -time.Minute)), secret)
+	assert.False(t, ok, "expired token")
+
+	_, ok = ValidateScopedToken(GenerateToken(secret), secret)
+	assert.False(t, ok, "root token is no scoped token")
+}
+
+func TestAuthMiddleware_ScopedToken(t *testing.T) {
+	secret := "test-secret"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	middleware := authMiddleware(secret, time.Hour, handler)
+
+	github := GenerateScopedToken(secret, StepScope("app", "github", time.Hour))
+	golang := GenerateScopedToken(secret, StepScope("app", "golang", time.Hour))
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		status int
+	}{
+		{"read commit message", github, http.MethodGet, "/mem/commit_message", http.StatusOK},
+		{"write commit message", github, http.MethodPost, "/mem/commit_message", http.StatusForbidden},
+		{"read registry credentials", github, http.MethodGet, "/mem/registry_password", http.StatusForbidden},
+		{"read artifacts", github, http.MethodGet, "/artifacts/app/bin", http.StatusForbidden},
+		{"health", github, http.MethodGet, "/health", http.StatusOK},
+		{"default read", golang, http.MethodGet, "/mem/accesstoken", http.StatusOK},
+		{"default write", golang, http.MethodPost, "/mem/commit_message", http.StatusOK},
+		{"read global", golang, http.MethodGet, "/mem/global/accesstoken", http.StatusOK},
+		{"write global", golang, http.MethodPost, "/mem/global/accesstoken", http.StatusForbidden},
+		{"default artifacts", golang, http.MethodPut, "/artifacts/app/bin", http.StatusOK},
+		{"list artifacts", golang, http.MethodGet, "/artifacts/app", http.StatusOK},
+		{"artifacts of other build", golang, http.MethodPut, "/artifacts/other/bin", http.StatusForbidden},
+		{"list artifacts of other build", golang, http.MethodGet, "/artifacts/other", http.StatusOK},
+		{"read artifacts of other build", golang, http.MethodGet, "/artifacts/other/bin", http.StatusOK},
+		{"tampered token", github + "x", http.MethodGet, "/mem/commit_message", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tt.token))
+			w := httptest.NewRecorder()
+
+			middleware.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestEventsScope(t *testing.T) {
+	secret := "test-secret"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	middleware := authMiddleware(secret, time.Hour, handler)
+	token := GenerateScopedToken(secret, EventsScope(time.Hour))
+
+	for path, status := range map[string]int{
+		"/events":                 http.StatusOK,
+		"/mem/commit_message":     http.StatusForbidden,
+		"/mem/global/accesstoken": http.StatusForbidden,
+		"/artifacts/app":          http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
+
+func TestScopeAllows(t *testing.T) {
+	scope := Scope{Permissions: []Permission{ReadOnly("global/"), ReadWrite("app/")}}
+
+	assert.True(t, scope.Allows("global/token", false))
+	assert.False(t, scope.Allows("global/token", true))
+	assert.True(t, scope.Allows("app/key", true))
+	assert.False(t, scope.Allows("other/key", false))
+}
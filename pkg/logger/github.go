@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// GitHub writes GitHub Actions workflow commands for groups and annotations.
+// Groups can not be nested in GitHub Actions so a group is only opened when
+// no other group is open, e.g. parallel builds log outside of groups. The
+// group of a step "app / golang" replaces the open group of its build "app"
+// which is reopened when the step ends.
+type GitHub struct {
+	out        io.Writer
+	group      *logGroup
+	failedTest string
+	mu         sync.Mutex
+	enabled    bool
+}
+
+// logGroup is the group opened by a single StartGroup call, steps with the
+// same name must not close each others group.
+type logGroup struct {
+	parent *logGroup
+	name   string
+}
+
+var (
+	github     *GitHub
+	githubOnce sync.Once
+
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	// locationPattern matches the file:line:col: message diagnostics of the Go
+	// compiler, go vet, golangci-lint, zig and gcc style compilers and linters.
+	// The column is required and the line must not be indented, test logs like
+	// t.Log print an indented file:line: message without a column.
+	locationPattern = regexp.MustCompile(`^(?:/src/|\./)?([\w./-]+\.(?:go|py|rs|zig|java|kt|ts|tsx|js|c|h|cpp)):(\d+):(\d+):\s+(.+)$`)
+	// testFailPattern matches the --- FAIL: TestName line of go test, the
+	// indented file:line: message lines following it are the test failures.
+	testFailPattern     = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+	testLocationPattern = regexp.MustCompile(`^\s+([\w./-]+_test\.go):(\d+):\s+(.+)$`)
+)
+
+// GitHubActions returns the process wide GitHub integration which is enabled
+// when running in GitHub Actions (GITHUB_ACTIONS=true).
+func GitHubActions() *GitHub {
+	githubOnce.Do(func() {
+		github = NewGitHub(os.Stdout, os.Getenv("GITHUB_ACTIONS") == "true")
+	})
+	return github
+}
+
+func NewGitHub(out io.Writer, enabled bool) *GitHub {
+	return &GitHub{out: out, enabled: enabled}
+}
+
+func (g *GitHub) Enabled() bool {
+	return g != nil && g.enabled
+}
+
+// StartGroup opens a collapsible log group and returns the function to close it.
+func (g *GitHub) StartGroup(name string) func() {
+	if !g.Enabled() {
+		return func() {}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	parent := g.group
+	if parent != nil && !strings.HasPrefix(name, parent.name+" / ") {
+		return func() {}
+	}
+	if parent != nil {
+		fmt.Fprintln(g.out, "::endgroup::")
+	}
+	group := &logGroup{parent: parent, name: name}
+	g.group = group
+	fmt.Fprintf(g.out, "::group::%s\n", escapeData(name))
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.group != group {
+			return
+		}
+		fmt.Fprintln(g.out, "::endgroup::")
+		g.group = group.parent
+		if g.group != nil {
+			fmt.Fprintf(g.out, "::group::%s\n", escapeData(g.group.name))
+		}
+	}
+}
+
+// Error writes an error annotation, file and line are optional.
+func (g *GitHub) Error(title, file string, line, col int, msg string) {
+	g.annotate("error", title, file, line, col, msg)
+}
+
+func (g *GitHub) annotate(level, title, file string, line, col int, msg string) {
+	if !g.Enabled() {
+		return
+	}
+	var props []string
+	if file != "" {
+		props = append(props, "file="+escapeProperty(file))
+		if line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", line))
+		}
+		if col > 0 {
+			props = append(props, fmt.Sprintf("col=%d", col))
+		}
+	}
+	if title != "" {
+		props = append(props, "title="+escapeProperty(title))
+	}
+
+	msg = Redact(msg)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(props) > 0 {
+		fmt.Fprintf(g.out, "::%s %s::%s\n", level, strings.Join(props, ","), escapeData(msg))
+	} else {
+		fmt.Fprintf(g.out, "::%s::%s\n", level, escapeData(msg))
+	}
+}
+
+// AnnotateLine turns compiler and lint output in the file:line:col: message
+// format and the failures of go tests into annotations. Messages starting
+// with "warning" become warnings.
+func (g *GitHub) AnnotateLine(line string) {
+	if !g.Enabled() {
+		return
+	}
+	line = ansiPattern.ReplaceAllString(line, "")
+	if g.annotateTest(line) {
+		return
+	}
+	m := locationPattern.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	var lineNo, col int
+	fmt.Sscanf(m[2], "%d", &lineNo)
+	fmt.Sscanf(m[3], "%d", &col)
+	level := "error"
+	if strings.HasPrefix(strings.ToLower(m[4]), "warning") {
+		level = "warning"
+	}
+	g.annotate(level, "", m[1], lineNo, col, m[4])
+}
+
+// annotateTest annotates the file:line: message lines of a failed go test and
+// reports whether the line belongs to the output of a failed test.
+func (g *GitHub) annotateTest(line string) bool {
+	g.mu.Lock()
+	if m := testFailPattern.FindStringSubmatch(line); m != nil {
+		g.failedTest = m[1]
+		g.mu.Unlock()
+		return true
+	}
+	test := g.failedTest
+	m := testLocationPattern.FindStringSubmatch(line)
+	if m == nil && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+		// the output of the failed test ends with the next not indented line
+		g.failedTest = ""
+	}
+	g.mu.Unlock()
+	if test == "" || m == nil {
+		return false
+	}
+	var lineNo int
+	fmt.Sscanf(m[2], "%d", &lineNo)
+	g.annotate("error", test+" failed", m[1], lineNo, 0, m[3])
+	return true
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
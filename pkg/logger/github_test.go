@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewGitHub(&buf, false)
+	g.StartGroup("app / golang")()
+	g.Error("failed", "", 0, 0, "boom")
+	g.AnnotateLine("main.go:1:1: undefined: foo")
+	assert.Empty(t, buf.String())
+}
+
+func TestGitHubGroups(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewGitHub(&buf, true)
+
+	end := g.StartGroup("app / golang")
+	// groups can not be nested
+	g.StartGroup("other / golang")()
+	// a parallel step with the same name does not close the open group
+	g.StartGroup("app / golang")()
+	end()
+	end()
+
+	assert.Equal(t, "::group::app / golang\n::endgroup::\n", buf.String())
+}
+
+func TestGitHubBuildGroups(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewGitHub(&buf, true)
+
+	endBuild := g.StartGroup("app")
+	endStep := g.StartGroup("app / golang")
+	// an async step of the same build logs into the open step group
+	g.StartGroup("app / sonar")()
+	endStep()
+	g.StartGroup("other / golang")()
+	endBuild()
+
+	assert.Equal(t, "::group::app\n::endgroup::\n::group::app / golang\n::endgroup::\n::group::app\n::endgroup::\n", buf.String())
+}
+
+func TestGitHubAnnotateTestFailure(t *testing.T) {
+	var buf bytes.Buffer
+	g := NewGitHub(&buf, true)
+	for _, line := range []string{
+		"--- FAIL: TestApp (0.00s)",
+		"    app_test.go:42: expected 1, got 2",
+		"    --- FAIL: TestApp/sub (0.00s)",
+		"        app_test.go:50: boom",
+		"FAIL",
+		"    app_test.go:60: not part of a failed test",
+	} {
+		g.AnnotateLine(line)
+	}
+
+	assert.Equal(t, "::error file=app_test.go,line=42,title=TestApp failed::expected 1, got 2\n"+
+		"::error file=app_test.go,line=50,title=TestApp/sub failed::boom\n", buf.String())
+}
+
+func TestGitHubAnnotateLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{"/src/pkg/app/main.go:12:5: undefined: foo", "::error file=pkg/app/main.go,line=12,col=5::undefined: foo\n"},
+		{"src/main.zig:3:5: error: use of undeclared identifier 'foo'", "::error file=src/main.zig,line=3,col=5::error: use of undeclared identifier 'foo'\n"},
+		{"pkg/a.go:7:2: ineffectual assignment to err (ineffassign)", "::error file=pkg/a.go,line=7,col=2::ineffectual assignment to err (ineffassign)\n"},
+		// test logs are not annotated
+		{"    app_test.go:42: expected 1, got 2", ""},
+		{"    app_test.go:42:3: expected 1, got 2", ""},
+		{"app.go:10: starting server", ""},
+		{"\x1b[31mpkg/a.go:3:1: warning: unused\x1b[0m", "::warning file=pkg/a.go,line=3,col=1::warning: unused\n"},
+		{"ok  github.com/org/app 0.01s", ""},
+		{"100%: done", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			var buf bytes.Buffer
+			NewGitHub(&buf, true).AnnotateLine(tt.line)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestGitHubError(t *testing.T) {
+	var buf bytes.Buffer
+	NewGitHub(&buf, true).Error("app: golang", "", 0, 0, "exit 1\n100%")
+	assert.Equal(t, "::error title=app%3A golang::exit 1%0A100%25\n", buf.String())
+}
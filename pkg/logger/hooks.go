@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Line is a redacted output line of a routine, e.g. a container.
+type Line struct {
+	Time      time.Time
+	RoutineID string
+	Msg       string
+	Fields    Fields
+	Failed    bool
+}
+
+var (
+	lineHooks   []func(Line)
+	lineHooksMu sync.RWMutex
+)
+
+// AddLineHook registers a function called for every logged line.
+func AddLineHook(fn func(Line)) {
+	lineHooksMu.Lock()
+	defer lineHooksMu.Unlock()
+	lineHooks = append(lineHooks, fn)
+}
+
+func (la *LogAggregator) notify(routineID, msg string, isFailed bool) {
+	lineHooksMu.RLock()
+	defer lineHooksMu.RUnlock()
+	if len(lineHooks) == 0 {
+		return
+	}
+	fields, _ := la.lookupFields(routineID)
+	line := Line{Time: time.Now(), RoutineID: routineID, Msg: msg, Fields: fields, Failed: isFailed}
+	for _, fn := range lineHooks {
+		fn(line)
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineHook(t *testing.T) {
+	var lines []Line
+	AddLineHook(func(l Line) {
+		if l.RoutineID == "[hook-test]" {
+			lines = append(lines, l)
+		}
+	})
+
+	RegisterSecret("hook-s3cr3t")
+	la := &LogAggregator{format: "json", out: &bytes.Buffer{}}
+	la.SetFields("[hook-test]", Fields{App: "app", Step: "golang"})
+	la.LogMessage("[hook-test]", "token hook-s3cr3t")
+
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, "token ***", lines[0].Msg)
+		assert.Equal(t, "golang", lines[0].Fields.Step)
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"time"
+)
+
+const (
+	// StreamEngine marks records logged by engine-ci itself.
+	StreamEngine = "engine"
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// Fields correlate the lines of a routine with the build that produced them.
+type Fields struct {
+	App       string
+	Step      string
+	Container string
+	Image     string
+}
+
+type jsonLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Msg       string    `json:"msg"`
+	App       string    `json:"app,omitempty"`
+	Step      string    `json:"step,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Stream    string    `json:"stream"`
+}
+
+// NewJSONLog returns a handler writing one JSON object per line in the same
+// shape as the container lines of the json progress mode.
+func NewJSONLog(logOpts slog.HandlerOptions) slog.Handler {
+	return newJSONHandler(NewLogAggregator("json"), logOpts)
+}
+
+func newJSONHandler(out io.Writer, logOpts slog.HandlerOptions) slog.Handler {
+	replace := logOpts.ReplaceAttr
+	logOpts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			a.Key = "timestamp"
+		}
+		if replace != nil {
+			return replace(groups, a)
+		}
+		return a
+	}
+	return slog.NewJSONHandler(out, &logOpts).
+		WithAttrs([]slog.Attr{slog.String("stream", StreamEngine)})
+}
+
+// SetFields registers the fields added to every json line of the routine.
+func (la *LogAggregator) SetFields(routineID string, fields Fields) {
+	la.fields.Store(routineID, fields)
+}
+
+func (la *LogAggregator) lookupFields(routineID string) (Fields, bool) {
+	value, ok := la.fields.Load(routineID)
+	if !ok {
+		return Fields{}, false
+	}
+	return value.(Fields), true
+}
+
+// writeJSON writes a container or engine output line as JSON object. Containers
+// run with a TTY so stdout and stderr are merged, only failures go to stderr.
+func (la *LogAggregator) writeJSON(routineID string, msg string, isFailed bool) {
+	line := jsonLine{
+		Timestamp: time.Now(),
+		Level:     slog.LevelInfo.String(),
+		Msg:       msg,
+		Stream:    StreamStdout,
+	}
+	if isFailed {
+		line.Level = slog.LevelError.String()
+		line.Stream = StreamStderr
+	}
+	if fields, ok := la.lookupFields(routineID); ok {
+		line.App = fields.App
+		line.Step = fields.Step
+		line.Container = fields.Container
+		line.Image = fields.Image
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+	la.writeLine(append(data, '\n'))
+}
+
+func (la *LogAggregator) writeLine(p []byte) {
+	la.outMu.Lock()
+	defer la.outMu.Unlock()
+	_, _ = la.output().Write(p)
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLines(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m), line)
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestJSONContainerLines(t *testing.T) {
+	var buf bytes.Buffer
+	la := &LogAggregator{format: "json", out: &buf}
+	la.SetFields("[abc123 (golang:1.26)]", Fields{App: "app", Step: "golang", Container: "abc123def", Image: "golang:1.26"})
+
+	la.LogMessage("[abc123 (golang:1.26)]", "go build ./...")
+	la.FailedMessage("[abc123 (golang:1.26)]", "Container exited with non 0")
+	la.LogMessage("[engine-ci]", "pulling image")
+
+	lines := decodeLines(t, buf.String())
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, "go build ./...", lines[0]["msg"])
+	assert.Equal(t, "INFO", lines[0]["level"])
+	assert.Equal(t, "app", lines[0]["app"])
+	assert.Equal(t, "golang", lines[0]["step"])
+	assert.Equal(t, "abc123def", lines[0]["container"])
+	assert.Equal(t, "golang:1.26", lines[0]["image"])
+	assert.Equal(t, StreamStdout, lines[0]["stream"])
+	assert.NotEmpty(t, lines[0]["timestamp"])
+
+	assert.Equal(t, "ERROR", lines[1]["level"])
+	assert.Equal(t, StreamStderr, lines[1]["stream"])
+	assert.Equal(t, "app", lines[1]["app"])
+
+	assert.NotContains(t, lines[2], "app")
+
+	// the fields are reset once the container exited
+	_, ok := la.lookupFields("[abc123 (golang:1.26)]")
+	assert.False(t, ok)
+}
+
+func TestJSONEngineRecords(t *testing.T) {
+	var buf bytes.Buffer
+	la := &LogAggregator{format: "json", out: &buf}
+	slog.New(newJSONHandler(la, slog.HandlerOptions{})).Info("Starting build", "app", "app", "step", "golang")
+
+	lines := decodeLines(t, buf.String())
+	require.Len(t, lines, 1)
+	assert.Equal(t, "Starting build", lines[0]["msg"])
+	assert.Equal(t, "golang", lines[0]["step"])
+	assert.Equal(t, StreamEngine, lines[0]["stream"])
+	assert.NotEmpty(t, lines[0]["timestamp"])
+	assert.NotContains(t, lines[0], "time")
+}
+
+func TestJSONWriterLines(t *testing.T) {
+	var buf bytes.Buffer
+	la := &LogAggregator{format: "json", out: &buf}
+
+	w := la.Writer("[buildx]")
+	_, err := w.Write([]byte("#1 load build\r\n#2 exporting"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte(" to image\n#3 done"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	lines := decodeLines(t, buf.String())
+	require.Len(t, lines, 3)
+	assert.Equal(t, "#1 load build", lines[0]["msg"])
+	assert.Equal(t, "#2 exporting to image", lines[1]["msg"])
+	assert.Equal(t, "#3 done", lines[2]["msg"])
+}
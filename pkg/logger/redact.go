@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Redacted replaces secret values in the log output.
+	Redacted = "***"
+	// minSecretLength avoids redacting short common values like true or 1.
+	minSecretLength = 4
+	// maxLineBuffer is the size after which a line without newline is flushed.
+	maxLineBuffer = 64 * 1024
+)
+
+// Redactor replaces registered secret values in log output.
+type Redactor struct {
+	replacer *strings.Replacer
+	secrets  map[string]struct{}
+	maxLen   int
+	mu       sync.RWMutex
+}
+
+var redactor = NewRedactor()
+
+func NewRedactor() *Redactor {
+	return &Redactor{secrets: map[string]struct{}{}}
+}
+
+// RegisterSecret registers secret values to be redacted from all log output.
+func RegisterSecret(values ...string) {
+	redactor.Register(values...)
+}
+
+// Redact replaces all registered secret values with ***.
+func Redact(s string) string {
+	return redactor.Redact(s)
+}
+
+// Register adds the values, the single lines of multi line values and
+// their JSON escaped form to the secrets.
+func (r *Redactor) Register(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := false
+	add := func(v string) {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLength {
+			return
+		}
+		if _, ok := r.secrets[v]; ok {
+			return
+		}
+		r.secrets[v] = struct{}{}
+		r.maxLen = max(r.maxLen, len(v))
+		changed = true
+	}
+	for _, v := range values {
+		add(v)
+		for _, line := range strings.Split(v, "\n") {
+			add(line)
+		}
+		for _, escapeHTML := range []bool{true, false} {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(escapeHTML)
+			if err := enc.Encode(v); err == nil {
+				data := bytes.TrimSpace(buf.Bytes())
+				add(string(data[1 : len(data)-1]))
+			}
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// replace longer secrets first so that a secret containing another one is fully redacted
+	secrets := make([]string, 0, len(r.secrets))
+	for s := range r.secrets {
+		secrets = append(secrets, s)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s, Redacted)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.replacer == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+func (r *Redactor) MaxLen() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.maxLen
+}
+
+// RedactWriter redacts secrets from a byte stream. Complete lines are written
+// immediately, incomplete lines are held back so that a secret split across
+// two writes is still redacted.
+type RedactWriter struct {
+	out      io.Writer
+	redactor *Redactor
+	buf      []byte
+}
+
+func NewRedactWriter(out io.Writer) *RedactWriter {
+	return &RedactWriter{out: out, redactor: redactor}
+}
+
+func (w *RedactWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	if i := bytes.LastIndexByte(w.buf, '\n'); i >= 0 {
+		if err := w.flush(w.redactor.Redact(string(w.buf[:i+1]))); err != nil {
+			return 0, err
+		}
+		w.buf = append(w.buf[:0], w.buf[i+1:]...)
+	}
+
+	if len(w.buf) > maxLineBuffer {
+		// a secret which is not complete yet can only be in the last maxLen-1 bytes
+		redacted := w.redactor.Redact(string(w.buf))
+		keep := min(max(w.redactor.MaxLen()-1, 0), len(redacted))
+		if err := w.flush(redacted[:len(redacted)-keep]); err != nil {
+			return 0, err
+		}
+		w.buf = append(w.buf[:0], redacted[len(redacted)-keep:]...)
+	}
+	return len(p), nil
+}
+
+// Close writes the held back output.
+func (w *RedactWriter) Close() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.flush(w.redactor.Redact(string(w.buf)))
+	w.buf = w.buf[:0]
+	return err
+}
+
+func (w *RedactWriter) flush(s string) error {
+	_, err := io.WriteString(w.out, s)
+	return err
+}
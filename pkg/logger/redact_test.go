@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor()
+	assert.Equal(t, "token s3cr3t-token", r.Redact("token s3cr3t-token"))
+
+	r.Register("s3cr3t-token", "abc", "", "line-one\nline-two", `pa"ss<word>`)
+
+	assert.Equal(t, "token ***", r.Redact("token s3cr3t-token"))
+	assert.Equal(t, "abc", r.Redact("abc"), "short values are not redacted")
+	assert.Equal(t, "*** and ***", r.Redact("line-one and line-two"))
+	assert.Equal(t, `{"password":"***"}`, r.Redact(`{"password":"pa\"ss<word>"}`))
+	assert.Equal(t, `{"password":"***"}`, r.Redact(`{"password":"pa\"ss\u003cword\u003e"}`))
+	assert.Equal(t, len(`pa\"ss\u003cword\u003e`), r.MaxLen())
+}
+
+func TestRedactorLongestFirst(t *testing.T) {
+	r := NewRedactor()
+	r.Register("secret", "secret-extended")
+	assert.Equal(t, "*** ***", r.Redact("secret-extended secret"))
+}
+
+func TestRedactWriterSplitSecret(t *testing.T) {
+	r := NewRedactor()
+	r.Register("s3cr3t-token")
+
+	var out bytes.Buffer
+	w := &RedactWriter{out: &out, redactor: r}
+	for _, chunk := range []string{"+ curl -H 'Authorization: s3c", "r3t", "-token'\nnext ", "line s3cr3t-", "token"} {
+		_, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "+ curl -H 'Authorization: ***'\n", out.String())
+	require.NoError(t, w.Close())
+	assert.Equal(t, "+ curl -H 'Authorization: ***'\nnext line ***", out.String())
+}
+
+func TestRedactWriterLongLine(t *testing.T) {
+	r := NewRedactor()
+	r.Register("s3cr3t-token")
+
+	var out bytes.Buffer
+	w := &RedactWriter{out: &out, redactor: r}
+	line := strings.Repeat("x", maxLineBuffer) + "s3cr3t-token" + strings.Repeat("y", 10)
+	for i := 0; i < len(line); i += 4096 {
+		_, err := w.Write([]byte(line[i:min(i+4096, len(line))]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	assert.NotContains(t, out.String(), "s3cr3t")
+	assert.True(t, strings.HasSuffix(out.String(), "***"+strings.Repeat("y", 10)))
+}
+
+type chunkReader struct {
+	chunks []string
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.chunks[0])
+	c.chunks[0] = c.chunks[0][n:]
+	if c.chunks[0] == "" {
+		c.chunks = c.chunks[1:]
+	}
+	return n, nil
+}
+
+func (c *chunkReader) Close() error { return nil }
+
+func TestCopyRedactsSplitSecret(t *testing.T) {
+	RegisterSecret("copy-s3cr3t-value")
+
+	var buf bytes.Buffer
+	la := &LogAggregator{format: "json", out: &buf}
+	n, err := la.Copy(&chunkReader{chunks: []string{"pushing with copy-s3", "cr3t-value\n", "done"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	lines := decodeLines(t, buf.String())
+	require.Len(t, lines, 2)
+	assert.Equal(t, "pushing with ***", lines[0]["msg"])
+	assert.Equal(t, "done", lines[1]["msg"])
+}
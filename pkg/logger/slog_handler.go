@@ -24,13 +24,23 @@ type Options struct {
 }
 
 func NewRootLog(logOpts slog.HandlerOptions) slog.Handler {
-	return slog.NewTextHandler(os.Stdout, &logOpts)
+	return slog.NewTextHandler(NewRedactWriter(os.Stdout), &logOpts)
 }
 
 func New(progress string, logOpts slog.HandlerOptions) slog.Handler {
 	if progress == "progress" {
 		return NewSimpleLog(NewLogAggregator(progress), logOpts.Level)
 	}
+	if progress == "json" {
+		return NewJSONLog(logOpts)
+	}
+	if progress == "tui" {
+		// the tui needs an interactive terminal otherwise fallback to plain
+		if !IsTerminal(os.Stdout) {
+			return NewPrettyLog("plain", logOpts)
+		}
+		return NewSimpleLog(NewLogAggregator(progress), logOpts.Level)
+	}
 	return NewPrettyLog(progress, logOpts)
 }
 
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -57,17 +58,21 @@ type (
 		messagePool    sync.Pool
 		entryPool      sync.Pool
 		alt            *AltScreen
+		tui            *TUI
+		out            io.Writer
 		shutdown       chan struct{}
 		flushDone      chan struct{}
 		logChannel     chan LogMessage
 		batchProcessor *BatchProcessor
 		logMap         sync.Map
+		fields         sync.Map
 		format         string
 		routineOrder   []string
 		workerWg       sync.WaitGroup
 		batchTimeout   time.Duration
 		batchSize      int
 		processWorkers int
+		outMu          sync.Mutex
 	}
 
 	// LogMessage represents a single log message with optimized layout
@@ -143,6 +148,11 @@ func NewLogAggregator(format string) *LogAggregator {
 			},
 		}
 
+		if format == "tui" {
+			instance.tui = newTUI(os.Stdin, os.Stdout, instance.lookupFields)
+			instance.tui.Start()
+		}
+
 		// Initialize batch processor for concurrent message handling
 		if format == "progress" {
 			instance.batchProcessor = &BatchProcessor{
@@ -161,6 +171,14 @@ func NewLogAggregator(format string) *LogAggregator {
 	return instance
 }
 
+// Shutdown flushes the log aggregator when it is initialized. It has to run
+// before os.Exit, the tui restores the terminal and shows the failures.
+func Shutdown() {
+	if instance != nil {
+		instance.Flush()
+	}
+}
+
 func GetLogAggregator() *LogAggregator {
 	if instance == nil {
 		panic("LogAggregator is not initialized")
@@ -180,35 +198,99 @@ func (la *LogAggregator) LogMessage(routineID string, msg string) {
 }
 
 func (la *LogAggregator) logMessage(routineID string, msg string, isDone bool, isFailed bool) {
-	if la.format == "progress" {
+	msg = Redact(msg)
+	GitHubActions().AnnotateLine(msg)
+	la.notify(routineID, msg, isFailed)
+	if la.format == "json" {
+		la.writeJSON(routineID, msg, isFailed)
+	} else if la.format == "tui" {
+		la.tui.Add(routineID, msg, isDone, isFailed)
+	} else if la.format == "progress" {
 		la.logChannel <- LogMessage{routineID: routineID, message: msg, isDone: isDone, isFailed: isFailed}
 	} else {
 		fmt.Printf("%s%s %s%s\n", grayscale, routineID, reset, msg)
 	}
+	if isDone {
+		// the routine ended, a later routine with the same id gets its own fields
+		la.fields.Delete(routineID)
+	}
 }
 
 func (la *LogAggregator) Write(p []byte) (n int, err error) {
+	if la.format == "json" {
+		// the json handler already writes complete lines
+		la.writeLine([]byte(Redact(string(p))))
+		return len(p), nil
+	}
 	msg := string(p)
 	msg = strings.TrimSuffix(msg, "\n")
 	la.logMessage("[engine-ci]", msg, false, false)
 	return len(p), nil
 }
 
+// Copy logs the output line by line. Whole lines are read regardless of
+// how the output is chunked so that secrets split across chunks are redacted.
 func (la *LogAggregator) Copy(r io.ReadCloser) (n int, err error) {
-	scanner := bufio.NewScanner(r)
+	reader := bufio.NewReader(r)
 
 	i := 0
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "errorDetail") {
-			la.logMessage("[engine-ci]", line, true, true)
-			return i, fmt.Errorf("errorDetail: %s", line)
-		} else {
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		if line != "" || err == nil {
+			if strings.Contains(line, "errorDetail") {
+				la.logMessage("[engine-ci]", line, true, true)
+				return i, fmt.Errorf("errorDetail: %s", Redact(line))
+			}
 			la.logMessage("[engine-ci]", line, false, false)
+			i++
+		}
+		if err == io.EOF {
+			return i, nil
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+}
+
+// Writer returns a writer that logs every written line as message of the
+// routine, e.g. for the output of commands. Close logs the incomplete last line.
+func (la *LogAggregator) Writer(routineID string) io.WriteCloser {
+	return &lineWriter{la: la, routineID: routineID}
+}
+
+type lineWriter struct {
+	la        *LogAggregator
+	routineID string
+	buf       []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
 		}
-		i++
+		w.la.LogMessage(w.routineID, strings.TrimSuffix(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
 	}
-	return i, nil
+}
+
+func (w *lineWriter) Close() error {
+	if len(w.buf) > 0 {
+		w.la.LogMessage(w.routineID, string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+func (la *LogAggregator) output() io.Writer {
+	if la.out == nil {
+		return os.Stdout
+	}
+	return la.out
 }
 
 func (la *LogAggregator) SuccessMessage(routineID string, msg string) {
@@ -221,6 +303,11 @@ func (la *LogAggregator) FailedMessage(routineID string, msg string) {
 
 // Flush will close the log channel and wait for all messages to be processed.
 func (la *LogAggregator) Flush() {
+	if la.format == "tui" {
+		la.tui.Close()
+		once.Reset()
+		return
+	}
 	if la.format == "progress" {
 		// Signal shutdown to batch processor
 		close(la.shutdown)
@@ -0,0 +1,378 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	maxScrollback = 10000
+	tuiTick       = 100 * time.Millisecond
+)
+
+type (
+	// tuiPane holds the full output and state of one routine (build step container).
+	tuiPane struct {
+		started  time.Time
+		finished time.Time
+		id       string
+		title    string
+		lines    []string
+		done     bool
+		failed   bool
+	}
+
+	// TUI is the interactive terminal UI of the tui progress mode. It shows one
+	// pane per build step and allows to expand the full scrollback of a step.
+	TUI struct {
+		in         *os.File
+		out        io.Writer
+		fields     func(routineID string) (Fields, bool)
+		index      map[string]*tuiPane
+		stop       chan struct{}
+		stopped    chan struct{}
+		quit       chan struct{}
+		restore    func()
+		panes      []*tuiPane
+		selected   int
+		scroll     int
+		width      int
+		height     int
+		mu         sync.Mutex
+		drawMu     sync.Mutex
+		expanded   bool
+		failedOnly bool
+		final      bool
+		keys       bool
+	}
+)
+
+// IsTerminal reports whether the file is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return isTTY(f)
+}
+
+func newTUI(in *os.File, out io.Writer, fields func(string) (Fields, bool)) *TUI {
+	return &TUI{
+		in:      in,
+		out:     out,
+		fields:  fields,
+		index:   map[string]*tuiPane{},
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		quit:    make(chan struct{}),
+		width:   80,
+		height:  24,
+	}
+}
+
+// Add appends a line to the pane of the routine and updates its status.
+func (t *TUI) Add(routineID, msg string, isDone, isFailed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.index[routineID]
+	if !ok {
+		p = &tuiPane{id: routineID, title: t.title(routineID), started: time.Now()}
+		t.index[routineID] = p
+		t.panes = append(t.panes, p)
+	}
+	p.lines = append(p.lines, ansiPattern.ReplaceAllString(msg, ""))
+	if len(p.lines) > maxScrollback {
+		p.lines = p.lines[len(p.lines)-maxScrollback:]
+	}
+	if isDone && !p.done {
+		p.done = true
+		p.failed = isFailed
+		p.finished = time.Now()
+	}
+}
+
+func (t *TUI) title(routineID string) string {
+	if t.fields == nil {
+		return routineID
+	}
+	f, ok := t.fields(routineID)
+	if !ok || f.App == "" {
+		return routineID
+	}
+	title := f.App
+	if f.Step != "" {
+		title += " / " + f.Step
+	}
+	if f.Image != "" {
+		title += " (" + f.Image + ")"
+	}
+	return title
+}
+
+// visible returns the panes shown with the current filter, has to be called with the lock held.
+func (t *TUI) visible() []*tuiPane {
+	if !t.failedOnly {
+		return t.panes
+	}
+	var panes []*tuiPane
+	for _, p := range t.panes {
+		if p.failed {
+			panes = append(panes, p)
+		}
+	}
+	return panes
+}
+
+func (t *TUI) hasFailed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range t.panes {
+		if p.failed {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleKey applies a key press and reports whether the UI should quit.
+func (t *TUI) HandleKey(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	page := max(t.height-3, 1)
+	switch key {
+	case "up", "k":
+		if t.selected > 0 {
+			t.selected--
+			t.scroll = 0
+		}
+	case "down", "j":
+		if t.selected < len(t.visible())-1 {
+			t.selected++
+			t.scroll = 0
+		}
+	case "enter", " ":
+		t.expanded = !t.expanded
+		t.scroll = 0
+	case "esc":
+		t.expanded = false
+		t.scroll = 0
+	case "f":
+		t.failedOnly = !t.failedOnly
+		t.selected = 0
+		t.scroll = 0
+	case "pgup":
+		t.scroll += page
+	case "pgdown":
+		t.scroll = max(t.scroll-page, 0)
+	case "q":
+		return t.final
+	}
+	return false
+}
+
+// Render returns the screen lines for the current state.
+func (t *TUI) Render() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	help := "↑/↓ select  enter expand  f failed only"
+	if t.final {
+		help += "  q quit"
+	}
+	lines := []string{fmt.Sprintf("engine-ci  %s%s%s", grayscale, help, reset)}
+
+	panes := t.visible()
+	if len(panes) == 0 {
+		return append(lines, "   no steps yet")
+	}
+	t.selected = min(t.selected, len(panes)-1)
+
+	if t.expanded {
+		p := panes[t.selected]
+		lines = append(lines, t.header(p, true))
+		size := max(t.height-len(lines), 1)
+		end := max(len(p.lines)-t.scroll, 0)
+		start := max(end-size, 0)
+		for _, ln := range p.lines[start:end] {
+			lines = append(lines, t.truncate("   "+ln))
+		}
+		return lines
+	}
+
+	for i, p := range panes {
+		lines = append(lines, t.header(p, i == t.selected))
+		if !p.done || p.failed {
+			if n := len(p.lines); n > 0 {
+				lines = append(lines, t.truncate(fmt.Sprintf("   %s%s%s", grayscale, p.lines[n-1], reset)))
+			}
+		}
+	}
+	return lines
+}
+
+func (t *TUI) header(p *tuiPane, selected bool) string {
+	icon, color, elapsed := "●", grayscale, time.Since(p.started)
+	if p.done {
+		elapsed = p.finished.Sub(p.started)
+		icon, color = "✔", green
+		if p.failed {
+			icon, color = "✘", red
+		}
+	}
+	cursor := " "
+	if selected {
+		cursor = ">"
+	}
+	return fmt.Sprintf("%s %s%s %s%s %v", cursor, color, icon, p.title, reset, elapsed.Round(tuiTick))
+}
+
+func (t *TUI) truncate(line string) string {
+	if r := []rune(line); len(r) > t.width {
+		return string(r[:t.width])
+	}
+	return line
+}
+
+// Start enters the alternate screen, switches the input to raw mode for the
+// keyboard navigation and starts rendering.
+func (t *TUI) Start() {
+	if f, ok := t.out.(*os.File); ok {
+		if w, h, err := term.GetSize(int(f.Fd())); err == nil {
+			t.width, t.height = w, h
+		}
+	}
+	fmt.Fprint(t.out, altEnter, hideCursor)
+
+	t.restore = func() {}
+	if t.in != nil && isTTY(t.in) {
+		if state, err := term.MakeRaw(int(t.in.Fd())); err == nil {
+			t.restore = func() { _ = term.Restore(int(t.in.Fd()), state) }
+			t.keys = true
+			go t.readKeys()
+		}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() { <-ch; t.interrupt() }()
+
+	go t.loop()
+}
+
+func (t *TUI) loop() {
+	defer close(t.stopped)
+	ticker := time.NewTicker(tuiTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.draw()
+		case <-t.stop:
+			t.draw()
+			return
+		}
+	}
+}
+
+func (t *TUI) draw() {
+	lines := t.Render()
+	var sb strings.Builder
+	sb.WriteString(homeClear)
+	for _, ln := range lines {
+		sb.WriteString(eraseLine)
+		sb.WriteString(ln)
+		// the terminal is in raw mode so the carriage return is needed
+		sb.WriteString("\r\n")
+	}
+	t.drawMu.Lock()
+	defer t.drawMu.Unlock()
+	_, _ = io.WriteString(t.out, sb.String())
+}
+
+func (t *TUI) readKeys() {
+	buf := make([]byte, 16)
+	for {
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return
+		}
+		key := parseKey(buf[:n])
+		if key == "ctrl-c" {
+			t.interrupt()
+			return
+		}
+		if t.HandleKey(key) {
+			close(t.quit)
+			return
+		}
+		t.draw()
+	}
+}
+
+func parseKey(b []byte) string {
+	switch string(b) {
+	case "\x1b[A":
+		return "up"
+	case "\x1b[B":
+		return "down"
+	case "\x1b[5~":
+		return "pgup"
+	case "\x1b[6~":
+		return "pgdown"
+	case "\r", "\n":
+		return "enter"
+	case "\x1b":
+		return "esc"
+	case "\x03":
+		return "ctrl-c"
+	}
+	return string(b)
+}
+
+func (t *TUI) interrupt() {
+	t.exit()
+	os.Exit(1)
+}
+
+func (t *TUI) exit() {
+	if t.restore != nil {
+		t.restore()
+	}
+	fmt.Fprint(t.out, showCursor, altExit)
+}
+
+// Close stops rendering. On failures the final screen stays open until q is
+// pressed. The final state is printed to the normal screen to stay in the scrollback.
+func (t *TUI) Close() {
+	t.mu.Lock()
+	t.final = true
+	t.mu.Unlock()
+
+	if t.hasFailed() && t.keys {
+		t.mu.Lock()
+		t.failedOnly = true
+		t.expanded = true
+		t.selected = 0
+		t.mu.Unlock()
+		t.draw()
+		<-t.quit
+	}
+
+	close(t.stop)
+	<-t.stopped
+	t.exit()
+
+	t.mu.Lock()
+	t.expanded = false
+	t.failedOnly = false
+	t.mu.Unlock()
+	for _, ln := range t.Render()[1:] {
+		fmt.Fprintln(t.out, ln)
+	}
+}
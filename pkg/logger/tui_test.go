@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTUI() *TUI {
+	fields := map[string]Fields{
+		"[abc123 (golang)]": {App: "app", Step: "golang", Image: "golang:1.26"},
+		"[def456 (lint)]":   {App: "app", Step: "golangci-lint"},
+	}
+	return newTUI(nil, &bytes.Buffer{}, func(id string) (Fields, bool) {
+		f, ok := fields[id]
+		return f, ok
+	})
+}
+
+func TestTUIPanes(t *testing.T) {
+	tui := newTestTUI()
+	tui.Add("[abc123 (golang)]", "go build ./...", false, false)
+	tui.Add("[def456 (lint)]", "\x1b[31mmain.go:1:1: unused\x1b[0m", false, false)
+	tui.Add("[def456 (lint)]", "Container exited with non 0", true, true)
+	tui.Add("[engine-ci]", "Starting build", false, false)
+
+	screen := strings.Join(tui.Render(), "\n")
+	assert.Contains(t, screen, "> "+grayscale+"● app / golang (golang:1.26)")
+	assert.Contains(t, screen, red+"✘ app / golangci-lint")
+	assert.Contains(t, screen, "● [engine-ci]")
+	assert.Contains(t, screen, "Container exited with non 0")
+	assert.NotContains(t, screen, "\x1b[31mmain.go")
+}
+
+func TestTUIFilterAndExpand(t *testing.T) {
+	tui := newTestTUI()
+	tui.Add("[abc123 (golang)]", "go build ./...", true, false)
+	for i := range 50 {
+		tui.Add("[def456 (lint)]", fmt.Sprintf("line %d", i), false, false)
+	}
+	tui.Add("[def456 (lint)]", "failed", true, true)
+
+	tui.HandleKey("f")
+	screen := tui.Render()
+	require.Len(t, screen, 3)
+	assert.Contains(t, screen[1], "app / golangci-lint")
+
+	tui.HandleKey("enter")
+	screen = tui.Render()
+	require.Len(t, screen, tui.height)
+	assert.Equal(t, "   failed", screen[len(screen)-1])
+
+	tui.HandleKey("pgup")
+	screen = tui.Render()
+	assert.Equal(t, "   line 29", screen[len(screen)-1])
+
+	tui.HandleKey("pgdown")
+	tui.HandleKey("esc")
+	tui.HandleKey("f")
+	assert.Len(t, tui.Render(), 4)
+}
+
+func TestTUIQuitOnlyOnFinalScreen(t *testing.T) {
+	tui := newTestTUI()
+	assert.False(t, tui.HandleKey("q"))
+	tui.final = true
+	assert.True(t, tui.HandleKey("q"))
+}
+
+func TestParseKey(t *testing.T) {
+	assert.Equal(t, "up", parseKey([]byte("\x1b[A")))
+	assert.Equal(t, "down", parseKey([]byte("\x1b[B")))
+	assert.Equal(t, "enter", parseKey([]byte("\r")))
+	assert.Equal(t, "ctrl-c", parseKey([]byte{3}))
+	assert.Equal(t, "q", parseKey([]byte("q")))
+}
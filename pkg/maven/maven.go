@@ -291,7 +291,7 @@ func (c *MavenContainer) Prod() (string, error) {
 		os.Exit(1)
 	}
 
-	imageId, err := c.Commit(fmt.Sprintf("%s:%s", c.Image, c.ImageTag), "Created from container", "CMD [\"/usr/local/s2i/run\"]", "USER 185")
+	imageId, err := c.CommitProd(fmt.Sprintf("%s:%s", c.Image, c.ImageTag), "Created from container", "CMD [\"/usr/local/s2i/run\"]", "USER 185")
 	if err != nil {
 		slog.Error("Failed to commit container: %s", "error", err)
 		os.Exit(1)
@@ -303,8 +303,7 @@ func (c *MavenContainer) Prod() (string, error) {
 		os.Exit(1)
 	}
 
-	imageUri := utils.ImageURI(c.GetBuild().Registry, c.Image, c.ImageTag)
-	err = c.Push(imageId, imageUri)
+	err = c.ExportOrPush(imageId)
 	if err != nil {
 		slog.Error("Failed to push image: %s", "error", err)
 		os.Exit(1)
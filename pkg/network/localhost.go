@@ -8,6 +8,7 @@ import (
 
 	"github.com/containifyci/engine-ci/pkg/container"
 	"github.com/containifyci/engine-ci/pkg/cri/utils"
+	"github.com/containifyci/engine-ci/pkg/kv"
 )
 
 var RuntimeOS = runtime.GOOS
@@ -16,7 +17,9 @@ type Address struct {
 	Host         string
 	InternalHost string
 	Secret       string
-	Port         int
+	// Token mints tokens restricted to a build step, nil when not supported.
+	Token func(scope kv.Scope) string
+	Port  int
 }
 
 func (a *Address) NewAddress(arg *container.Build) {
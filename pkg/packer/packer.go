@@ -128,7 +128,7 @@ func (c *packerContainer) Release(env container.EnvType) error {
 		}
 	}
 
-	token := container.GetEnv("HCLOUD_TOKEN")
+	token := container.GetSecret("HCLOUD_TOKEN")
 	if token == "" {
 		slog.Warn("HCLOUD_TOKEN is not set skip packer")
 		return nil
@@ -0,0 +1,324 @@
+package provenance
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/build"
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/cri"
+	"github.com/containifyci/engine-ci/pkg/kv"
+	"github.com/containifyci/engine-ci/pkg/report"
+	"github.com/containifyci/engine-ci/pkg/utils"
+)
+
+const (
+	attestationFolder = ".containifyci/attestations"
+	releaseFolder     = "dist"
+	// attestationType is the report artifact type of the provenance
+	attestationType = "in-toto"
+)
+
+type ProvenanceContainer struct {
+	*container.Container
+	report *report.Report
+	// registryURL returns the base URL of the registry API of the host
+	registryURL func(host string) string
+}
+
+// Matches returns true when provenance is enabled with the provenance=true property.
+func Matches(build container.Build) bool {
+	return build.Custom.Bool("provenance", false)
+}
+
+func New() build.BuildStep {
+	return build.Stepper{
+		RunFn: func(build container.Build) (string, error) {
+			container := new(build)
+			return "", container.Run()
+		},
+		MatchedFn: Matches,
+		Name_:     "provenance",
+		Alias_:    "provenance",
+		Async_:    false,
+	}
+}
+
+func new(build container.Build) *ProvenanceContainer {
+	return &ProvenanceContainer{
+		Container:   container.New(build),
+		report:      report.Default(),
+		registryURL: registryURL,
+	}
+}
+
+// newWithManager creates a ProvenanceContainer with a custom container manager (for testing)
+func newWithManager(build container.Build, manager cri.ContainerManager, rep *report.Report) *ProvenanceContainer {
+	c := container.NewWithManager(manager)
+	b := build.Defaults()
+	c.Build = b
+	c.Env = b.Env
+	return &ProvenanceContainer{Container: c, report: rep, registryURL: registryURL}
+}
+
+func registryURL(host string) string {
+	return "https://" + registryHost(host)
+}
+
+func (c *ProvenanceContainer) Run() error {
+	b := c.GetBuild()
+
+	images := c.imageSubjects()
+	var releases []Subject
+	// the dist folder belongs to the build that released with goreleaser
+	if b.Custom.Bool("goreleaser", false) {
+		var err error
+		if releases, err = releaseSubjects(releaseFolder); err != nil {
+			return err
+		}
+	}
+
+	published, err := c.artifactSubjects()
+	if err != nil {
+		return err
+	}
+	releases = append(releases, published...)
+
+	if len(images) == 0 && len(releases) == 0 {
+		slog.Info("Skip provenance no prod image or release artifacts found", "app", b.App)
+		return nil
+	}
+
+	signer, err := c.signer()
+	if err != nil {
+		return err
+	}
+
+	statement := NewStatement(*b, c.report, append(images, releases...), c.dependencies())
+	env, err := Seal(statement, signer)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.intoto.jsonl", b.App, b.ImageTag)
+	file := filepath.Join(attestationFolder, name)
+	data, err := writeEnvelope(file, env)
+	if err != nil {
+		return err
+	}
+	slog.Info("Wrote provenance", "file", file, "images", len(images), "releases", len(releases), "signed", signer != nil)
+
+	artifact := report.Artifact{Name: name, Path: file, Type: attestationType, Digest: digest(data)}
+	if err := c.upload(name, data); err != nil {
+		return err
+	}
+	if err := c.pushAttestations(images, bytes.TrimSpace(data)); err != nil {
+		return err
+	}
+	c.report.AddArtifact(b.App, artifact)
+	return nil
+}
+
+// pushAttestations pushes the envelope next to each image as the
+// sha256-<digest>.att tag, verifiable with cosign verify-attestation.
+func (c *ProvenanceContainer) pushAttestations(images []Subject, envelope []byte) error {
+	b := c.GetBuild()
+	for _, image := range images {
+		host, repository := splitRepository(image.Name)
+		client := &registryClient{client: http.DefaultClient, base: c.registryURL(host)}
+		if reg, ok := b.Registries[host]; ok {
+			client.username = utils.GetBuildValue(b.App, reg.Username, b.Env.String())
+			client.password = utils.GetSecretValue(b.App, reg.Password, b.Env.String())
+		}
+		imageDigest := digestAlgorithm + ":" + image.Digest[digestAlgorithm]
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		err := client.pushAttestation(ctx, repository, imageDigest, envelope)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to push provenance of %s: %w", image.Name, err)
+		}
+		slog.Info("Pushed provenance", "image", image.Name, "tag", attestationTag(imageDigest))
+	}
+	return nil
+}
+
+// artifactSubjects returns the artifacts the steps of the build published to
+// the engine, e.g. the cross compiled zig binaries.
+func (c *ProvenanceContainer) artifactSubjects() ([]Subject, error) {
+	b := c.GetBuild()
+	host := b.Custom.String("CONTAINIFYCI_EXTERNAL_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	artifacts, err := kv.ListArtifacts(host, b.Secret["CONTAINIFYCI_AUTH"], b.App)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the artifacts of %s: %w", b.App, err)
+	}
+	var subjects []Subject
+	for _, artifact := range artifacts {
+		// the attestations of previous runs are no subjects
+		if strings.HasSuffix(artifact.Name, ".intoto.jsonl") {
+			continue
+		}
+		subjects = append(subjects, Subject{
+			Name:   artifact.Name,
+			Digest: map[string]string{digestAlgorithm: strings.TrimPrefix(artifact.Digest, digestAlgorithm+":")},
+		})
+	}
+	return subjects, nil
+}
+
+// upload publishes the attestation as artifact of the build to the engine,
+// the later steps and the consumers of the run fetch it from there.
+func (c *ProvenanceContainer) upload(name string, data []byte) error {
+	b := c.GetBuild()
+	host := b.Custom.String("CONTAINIFYCI_EXTERNAL_HOST")
+	if host == "" {
+		return nil
+	}
+	if _, err := kv.UploadArtifact(host, b.Secret["CONTAINIFYCI_AUTH"], b.App, name, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to upload provenance %s: %w", name, err)
+	}
+	slog.Info("Uploaded provenance", "artifact", fmt.Sprintf("artifacts/%s/%s", b.App, name))
+	return nil
+}
+
+// signer loads the signing key from the provenance_key property.
+// The value supports the env:, cmd: and mem: references.
+func (c *ProvenanceContainer) signer() (*Signer, error) {
+	b := c.GetBuild()
+	ref := b.Custom.String("provenance_key")
+	if ref == "" {
+		slog.Warn("No provenance_key configured the provenance will not be signed", "app", b.App)
+		return nil, nil
+	}
+	key := utils.GetSecretValue(b.App, ref, b.Env.String())
+	if key == "" {
+		return nil, fmt.Errorf("provenance_key %s resolved to an empty value", ref)
+	}
+	return NewSigner([]byte(key))
+}
+
+// imageSubjects returns the images pushed by the build with the manifest digest
+// of the registry. The local image ID differs from it and is not used.
+func (c *ProvenanceContainer) imageSubjects() []Subject {
+	b := c.GetBuild()
+	var subjects []Subject
+	for _, image := range c.report.Pushed(b.App) {
+		info, err := c.InspectImage(image)
+		if err != nil || info == nil {
+			slog.Warn("Skip provenance of pushed image it is not available locally", "image", image, "error", err)
+			continue
+		}
+		digest, ok := repoDigest(image, info.RepoDigests)
+		if !ok {
+			slog.Warn("Skip provenance of pushed image no registry digest found", "image", image)
+			continue
+		}
+		subjects = append(subjects, Subject{
+			Name:   image,
+			Digest: map[string]string{digestAlgorithm: strings.TrimPrefix(digest, "sha256:")},
+		})
+	}
+	return subjects
+}
+
+// repoDigest returns the digest of the repository of the image reference.
+func repoDigest(image string, repoDigests []string) (string, bool) {
+	repository := image
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repository = image[:i]
+	}
+	repository = strings.TrimPrefix(repository, "docker.io/")
+	for _, ref := range repoDigests {
+		repo, digest, ok := strings.Cut(ref, "@")
+		if ok && strings.TrimPrefix(repo, "docker.io/") == repository {
+			return digest, true
+		}
+	}
+	return "", false
+}
+
+// dependencies resolves the digests of the builder images used by the recorded steps.
+func (c *ProvenanceContainer) dependencies() []Dependency {
+	seen := map[string]bool{}
+	var deps []Dependency
+	for _, step := range c.report.Steps(c.GetBuild().App) {
+		for _, image := range step.Images {
+			if image == "" || seen[image] {
+				continue
+			}
+			seen[image] = true
+			dep := Dependency{URI: image}
+			if info, err := c.InspectImage(image); err == nil {
+				dep.Digest = info.ID
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// releaseSubjects reads the goreleaser checksum files from the dist folder.
+func releaseSubjects(folder string) ([]Subject, error) {
+	files, err := filepath.Glob(filepath.Join(folder, "*checksums.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find checksum files: %w", err)
+	}
+
+	var subjects []Subject
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 2 {
+				continue
+			}
+			subjects = append(subjects, Subject{
+				Name:   fields[1],
+				Digest: map[string]string{digestAlgorithm: fields[0]},
+			})
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	}
+	return subjects, nil
+}
+
+func writeEnvelope(file string, env *Envelope) ([]byte, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return data, nil
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,245 @@
+package provenance
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/cri/critest"
+	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/pkg/report"
+	"github.com/containifyci/engine-ci/pkg/svc"
+	"github.com/containifyci/engine-ci/protos2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pemKey(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestMatches(t *testing.T) {
+	assert.False(t, Matches(container.Build{}))
+	assert.True(t, Matches(container.Build{Custom: container.Custom{"provenance": {"true"}}}))
+}
+
+func TestSealAndVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  any
+		pub  any
+	}{
+		{"ecdsa", ecKey, &ecKey.PublicKey},
+		{"ed25519", edKey, edPub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewSigner(pemKey(t, tt.key))
+			require.NoError(t, err)
+
+			env, err := Seal(Statement{Type: StatementType, PredicateType: PredicateType}, signer)
+			require.NoError(t, err)
+			require.Len(t, env.Signatures, 1)
+			assert.Equal(t, signer.KeyID(), env.Signatures[0].KeyID)
+			assert.NoError(t, Verify(env, tt.pub))
+
+			env.Payload = base64.StdEncoding.EncodeToString([]byte(`{"tampered":true}`))
+			assert.Error(t, Verify(env, tt.pub))
+		})
+	}
+}
+
+func TestSealUnsigned(t *testing.T) {
+	env, err := Seal(Statement{Type: StatementType}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, env.Signatures)
+	assert.Equal(t, PayloadType, env.PayloadType)
+}
+
+func TestNewSignerInvalidKey(t *testing.T) {
+	_, err := NewSigner([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestPAE(t *testing.T) {
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world",
+		string(PAE("http://example.com/HelloWorld", []byte("hello world"))))
+}
+
+func TestReleaseSubjects(t *testing.T) {
+	dir := t.TempDir()
+	content := "abc123  app_1.0.0_linux_amd64.tar.gz\ndef456  app_1.0.0_darwin_arm64.tar.gz\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app_1.0.0_checksums.txt"), []byte(content), 0644))
+
+	subjects, err := releaseSubjects(dir)
+	require.NoError(t, err)
+	require.Len(t, subjects, 2)
+	assert.Equal(t, "app_1.0.0_linux_amd64.tar.gz", subjects[0].Name)
+	assert.Equal(t, "abc123", subjects[0].Digest["sha256"])
+}
+
+func TestRunWritesImageProvenance(t *testing.T) {
+	t.Chdir(t.TempDir())
+	svc.SetGitInfoForTest("owner", "repo", "main", "v1.0.0")
+	t.Cleanup(svc.ResetGitInfo)
+
+	m, err := critest.NewMockContainerManager()
+	require.NoError(t, err)
+	m.Images["ghcr.io/owner/app:v1.0.0"] = &critest.MockImageLifecycle{Opts: &types.ImageInfo{
+		ID:          "sha256:1234",
+		RepoDigests: []string{"ghcr.io/owner/other@sha256:9999", "ghcr.io/owner/app@sha256:abcd"},
+	}}
+	m.Images["golang:1.26"] = &critest.MockImageLifecycle{Opts: &types.ImageInfo{ID: "sha256:5678"}}
+
+	rep := report.New()
+	rep.SetConfig(".containifyci/containifyci.go", "cafe")
+	rep.StartStep("app", "golang", "golang:1.26")(nil)
+	rep.StartStep("app", "golang-prod")(errors.New("failed"))
+	rep.AddPushed("app", "ghcr.io/owner/app:v1.0.0")
+
+	var uploaded []byte
+	var uploadPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`[{"name":"app-linux-arm64","digest":"sha256:ef01"},{"name":"app-v0.9.0.intoto.jsonl","digest":"sha256:0000"}]`))
+			return
+		}
+		uploadPath = r.Method + " " + r.URL.Path
+		uploaded, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(server.Close)
+
+	b := container.Build{
+		App:      "app",
+		Image:    "app",
+		ImageTag: "v1.0.0",
+		Registry: "ghcr.io/owner",
+		Env:      container.BuildEnv,
+		Custom: container.Custom{
+			"provenance":                 {"true"},
+			"CONTAINIFYCI_EXTERNAL_HOST": {strings.TrimPrefix(server.URL, "http://")},
+		},
+		Registries: map[string]*protos2.ContainerRegistry{
+			"ghcr.io": {Username: "user", Password: "secret"},
+		},
+	}
+	registry, registryServer := newFakeRegistry(t, "user", "secret")
+	c := newWithManager(b, m, rep)
+	c.registryURL = func(host string) string {
+		assert.Equal(t, "ghcr.io", host)
+		return registryServer.URL
+	}
+	require.NoError(t, c.Run())
+
+	data, err := os.ReadFile(filepath.Join(attestationFolder, "app-v1.0.0.intoto.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, "PUT /artifacts/app/app-v1.0.0.intoto.jsonl", uploadPath)
+	assert.Equal(t, data, uploaded)
+
+	// the envelope is pushed next to the image for cosign verify-attestation
+	manifestData, ok := registry.manifests["sha256-abcd.att"]
+	require.True(t, ok)
+	var attestation manifest
+	require.NoError(t, json.Unmarshal(manifestData, &attestation))
+	require.Len(t, attestation.Layers, 1)
+	assert.Equal(t, strings.TrimSpace(string(data)), string(registry.blobs[attestation.Layers[0].Digest]))
+	require.Len(t, rep.Artifacts("app"), 1)
+	assert.Equal(t, "in-toto", rep.Artifacts("app")[0].Type)
+
+	var env Envelope
+	require.NoError(t, json.Unmarshal(data, &env))
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	require.NoError(t, err)
+
+	var statement Statement
+	require.NoError(t, json.Unmarshal(payload, &statement))
+	assert.Equal(t, StatementType, statement.Type)
+	require.Len(t, statement.Subject, 2)
+	assert.Equal(t, "ghcr.io/owner/app:v1.0.0", statement.Subject[0].Name)
+	assert.Equal(t, "abcd", statement.Subject[0].Digest["sha256"], "the registry digest not the image ID")
+	assert.Equal(t, "app-linux-arm64", statement.Subject[1].Name)
+	assert.Equal(t, "ef01", statement.Subject[1].Digest["sha256"])
+
+	source := statement.Predicate.BuildDefinition.ExternalParameters["source"].(map[string]any)
+	assert.Equal(t, "owner/repo", source["repository"])
+	assert.Equal(t, "v1.0.0", source["tag"])
+
+	var uris []string
+	for _, dep := range statement.Predicate.BuildDefinition.ResolvedDependencies {
+		uris = append(uris, dep.URI+dep.Name)
+	}
+	assert.Contains(t, uris, "docker://golang:1.26")
+	assert.Contains(t, uris, ".containifyci/containifyci.go")
+
+	byproducts := statement.Predicate.RunDetails.Byproducts
+	require.Len(t, byproducts, 2)
+	assert.Equal(t, "failed", byproducts[1].Annotations["status"])
+}
+
+func TestRunReleaseSubjects(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(releaseFolder, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(releaseFolder, "checksums.txt"), []byte("abc123  app.tar.gz\n"), 0644))
+	m, err := critest.NewMockContainerManager()
+	require.NoError(t, err)
+
+	// only the build releasing with goreleaser owns the dist folder
+	other := newWithManager(container.Build{App: "other", ImageTag: "v1"}, m, report.New())
+	require.NoError(t, other.Run())
+	assert.NoFileExists(t, filepath.Join(attestationFolder, "other-v1.intoto.jsonl"))
+
+	app := newWithManager(container.Build{App: "app", ImageTag: "v1", Custom: container.Custom{"goreleaser": {"true"}}}, m, report.New())
+	require.NoError(t, app.Run())
+	assert.FileExists(t, filepath.Join(attestationFolder, "app-v1.intoto.jsonl"))
+	assert.NoFileExists(t, filepath.Join(releaseFolder, "app.intoto.jsonl"))
+}
+
+func TestRepoDigest(t *testing.T) {
+	digests := []string{"containifyci/app@sha256:1", "localhost:5000/app@sha256:2"}
+
+	d, ok := repoDigest("docker.io/containifyci/app:v1", digests)
+	assert.True(t, ok)
+	assert.Equal(t, "sha256:1", d)
+
+	d, ok = repoDigest("localhost:5000/app:v1", digests)
+	assert.True(t, ok)
+	assert.Equal(t, "sha256:2", d)
+
+	_, ok = repoDigest("ghcr.io/owner/app:v1", digests)
+	assert.False(t, ok)
+}
+
+func TestRunSkipsWithoutSubjects(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m, err := critest.NewMockContainerManager()
+	require.NoError(t, err)
+
+	c := newWithManager(container.Build{App: "app", Image: "app", ImageTag: "v1"}, m, report.New())
+	require.NoError(t, c.Run())
+	_, err = os.Stat(attestationFolder)
+	assert.True(t, os.IsNotExist(err))
+}
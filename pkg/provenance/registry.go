@@ -0,0 +1,278 @@
+package provenance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// dsseMediaType is the layer media type of the attestations cosign reads
+	// from the sha256-<digest>.att tag of an image.
+	dsseMediaType     = "application/vnd.dsse.envelope.v1+json"
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	emptyMediaType    = "application/vnd.oci.empty.v1+json"
+)
+
+// registryClient pushes the attestations of the images with the registry v2
+// API. Registries either accept basic auth directly or return a bearer
+// challenge with the token realm.
+type registryClient struct {
+	client   *http.Client
+	base     string
+	username string
+	password string
+	token    string
+}
+
+type descriptor struct {
+	Annotations map[string]string `json:"annotations,omitempty"`
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int               `json:"size"`
+}
+
+type manifest struct {
+	MediaType     string       `json:"mediaType"`
+	Config        descriptor   `json:"config"`
+	Layers        []descriptor `json:"layers"`
+	SchemaVersion int          `json:"schemaVersion"`
+}
+
+// splitRepository returns the registry host and the repository path of the
+// image reference, e.g. ghcr.io and owner/app for ghcr.io/owner/app:v1.
+func splitRepository(image string) (string, string) {
+	repository := image
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repository = image[:i]
+	}
+	host, path, ok := strings.Cut(repository, "/")
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		host, path = "docker.io", repository
+	}
+	if host == "docker.io" && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+	return host, path
+}
+
+// registryHost maps the Docker Hub aliases to the registry API host.
+func registryHost(host string) string {
+	switch host {
+	case "docker.io", "index.docker.io":
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+// attestationTag returns the tag cosign looks up the attestations of the digest at.
+func attestationTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".att"
+}
+
+// pushAttestation pushes the envelope as the sha256-<digest>.att image of the
+// repository, the layout cosign verify-attestation reads.
+func (c *registryClient) pushAttestation(ctx context.Context, repository, imageDigest string, envelope []byte) error {
+	empty := []byte("{}")
+	config := descriptor{MediaType: emptyMediaType, Digest: digest(empty), Size: len(empty)}
+	layer := descriptor{
+		MediaType:   dsseMediaType,
+		Digest:      digest(envelope),
+		Size:        len(envelope),
+		Annotations: map[string]string{"predicateType": PredicateType},
+	}
+	if err := c.pushBlob(ctx, repository, config.Digest, empty); err != nil {
+		return err
+	}
+	if err := c.pushBlob(ctx, repository, layer.Digest, envelope); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(manifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		Config:        config,
+		Layers:        []descriptor{layer},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal attestation manifest: %w", err)
+	}
+	target := fmt.Sprintf("%s/v2/%s/manifests/%s", c.base, repository, attestationTag(imageDigest))
+	resp, err := c.do(ctx, http.MethodPut, target, manifestMediaType, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return expectStatus(resp, http.StatusCreated)
+}
+
+// pushBlob uploads the blob with a monolithic upload unless the registry has it.
+func (c *registryClient) pushBlob(ctx context.Context, repository, blobDigest string, data []byte) error {
+	resp, err := c.do(ctx, http.MethodHead, fmt.Sprintf("%s/v2/%s/blobs/%s", c.base, repository, blobDigest), "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, http.MethodPost, fmt.Sprintf("%s/v2/%s/blobs/uploads/", c.base, repository), "", nil)
+	if err != nil {
+		return err
+	}
+	err = expectStatus(resp, http.StatusAccepted)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location %q: %w", resp.Header.Get("Location"), err)
+	}
+	query := location.Query()
+	query.Set("digest", blobDigest)
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, http.MethodPut, location.String(), "application/octet-stream", data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return expectStatus(resp, http.StatusCreated)
+}
+
+// do sends the request and retries it once authenticated when the registry
+// answers with an authentication challenge.
+func (c *registryClient) do(ctx context.Context, method, target, contentType string, body []byte) (*http.Response, error) {
+	resp, err := c.send(ctx, method, target, contentType, body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || c.username == "" {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	scheme, params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	switch scheme {
+	case "basic":
+		c.token = ""
+	case "bearer":
+		if c.token, err = c.fetchToken(ctx, params); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported authentication challenge %q", resp.Header.Get("WWW-Authenticate"))
+	}
+	return c.send(ctx, method, target, contentType, body)
+}
+
+func (c *registryClient) send(ctx context.Context, method, target, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry not reachable: %w", err)
+	}
+	return resp, nil
+}
+
+// fetchToken requests a bearer token from the realm of the challenge.
+func (c *registryClient) fetchToken(ctx context.Context, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v := params[key]; v != "" {
+			query.Set(key, v)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.username, c.password)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token realm not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := expectStatus(resp, http.StatusOK); err != nil {
+		return "", err
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response from %s: %w", realm.Host, err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("no token in response from %s", realm.Host)
+}
+
+func expectStatus(resp *http.Response, status int) error {
+	if resp.StatusCode == status {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("unexpected status %s from %s %s: %s", resp.Status, resp.Request.Method, resp.Request.URL.Path, strings.TrimSpace(string(msg)))
+}
+
+// parseChallenge parses a WWW-Authenticate header like
+// Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:owner/app:push,pull"
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var pair string
+		pair, rest = cutParam(rest)
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return strings.ToLower(scheme), params
+}
+
+// cutParam returns the next comma separated parameter, commas in quotes are kept.
+func cutParam(s string) (string, string) {
+	quoted := false
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
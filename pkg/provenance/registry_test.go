@@ -0,0 +1,123 @@
+package provenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry implements the parts of the registry v2 API used to push the
+// attestations behind the bearer token flow.
+type fakeRegistry struct {
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	mu        sync.Mutex
+}
+
+func newFakeRegistry(t *testing.T, username, password string) (*fakeRegistry, *httptest.Server) {
+	t.Helper()
+	reg := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"registry-token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer registry-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:owner/app:push,pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/v2/owner/app/")
+		switch {
+		case r.Method == http.MethodHead && strings.HasPrefix(path, "blobs/"):
+			if _, ok := reg.blobs[strings.TrimPrefix(path, "blobs/")]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodPost && path == "blobs/uploads/":
+			w.Header().Set("Location", "/v2/owner/app/blobs/uploads/1?state=abc")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && path == "blobs/uploads/1":
+			assert.Equal(t, "abc", r.URL.Query().Get("state"))
+			data, _ := io.ReadAll(r.Body)
+			reg.blobs[r.URL.Query().Get("digest")] = data
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+			assert.Equal(t, manifestMediaType, r.Header.Get("Content-Type"))
+			data, _ := io.ReadAll(r.Body)
+			reg.manifests[strings.TrimPrefix(path, "manifests/")] = data
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return reg, server
+}
+
+func TestSplitRepository(t *testing.T) {
+	tests := []struct {
+		image      string
+		host       string
+		repository string
+	}{
+		{"ghcr.io/owner/app:v1", "ghcr.io", "owner/app"},
+		{"localhost:5000/app:v1", "localhost:5000", "app"},
+		{"containifyci/app:v1", "docker.io", "containifyci/app"},
+		{"docker.io/alpine:3", "docker.io", "library/alpine"},
+		{"app", "docker.io", "library/app"},
+	}
+	for _, tt := range tests {
+		host, repository := splitRepository(tt.image)
+		assert.Equal(t, tt.host, host, tt.image)
+		assert.Equal(t, tt.repository, repository, tt.image)
+	}
+}
+
+func TestPushAttestation(t *testing.T) {
+	reg, server := newFakeRegistry(t, "user", "secret")
+	client := &registryClient{client: server.Client(), base: server.URL, username: "user", password: "secret"}
+
+	envelope := []byte(`{"payloadType":"application/vnd.in-toto+json"}`)
+	require.NoError(t, client.pushAttestation(context.Background(), "owner/app", "sha256:abcd", envelope))
+
+	data, ok := reg.manifests["sha256-abcd.att"]
+	require.True(t, ok)
+	var m manifest
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.Len(t, m.Layers, 1)
+	assert.Equal(t, dsseMediaType, m.Layers[0].MediaType)
+	assert.Equal(t, PredicateType, m.Layers[0].Annotations["predicateType"])
+	assert.Equal(t, envelope, reg.blobs[m.Layers[0].Digest])
+	assert.Equal(t, []byte("{}"), reg.blobs[m.Config.Digest])
+
+	// the blobs the registry has are not uploaded again
+	delete(reg.manifests, "sha256-abcd.att")
+	reg.blobs[m.Layers[0].Digest] = []byte("kept")
+	require.NoError(t, client.pushAttestation(context.Background(), "owner/app", "sha256:abcd", envelope))
+	assert.Equal(t, []byte("kept"), reg.blobs[m.Layers[0].Digest])
+}
+
+func TestPushAttestationUnauthorized(t *testing.T) {
+	_, server := newFakeRegistry(t, "user", "secret")
+	client := &registryClient{client: server.Client(), base: server.URL, username: "user", password: "wrong"}
+
+	err := client.pushAttestation(context.Background(), "owner/app", "sha256:abcd", []byte("{}"))
+	assert.ErrorContains(t, err, "401")
+}
@@ -0,0 +1,142 @@
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+)
+
+// Envelope is a DSSE envelope (https://github.com/secure-systems-lab/dsse).
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// Signer signs DSSE payloads with an ECDSA or Ed25519 private key.
+type Signer struct {
+	key   crypto.Signer
+	keyID string
+}
+
+// NewSigner parses a PEM encoded PKCS#8, EC or Ed25519 private key.
+func NewSigner(pemKey []byte) (*Signer, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in signing key")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	switch signer.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey:
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+
+	pub, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(pub)
+
+	return &Signer{key: signer, keyID: hex.EncodeToString(sum[:])}, nil
+}
+
+func (s *Signer) KeyID() string { return s.keyID }
+
+func (s *Signer) sign(data []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// PAE is the DSSE pre-authentication encoding of the payload.
+func PAE(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// Seal wraps the statement in a DSSE envelope. The envelope is
+// unsigned when no signer is given.
+func Seal(statement Statement, signer *Signer) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+
+	env := &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{},
+	}
+	if signer == nil {
+		return env, nil
+	}
+
+	sig, err := signer.sign(PAE(PayloadType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign statement: %w", err)
+	}
+	env.Signatures = append(env.Signatures, Signature{
+		KeyID: signer.KeyID(),
+		Sig:   base64.StdEncoding.EncodeToString(sig),
+	})
+	return env, nil
+}
+
+// Verify checks the envelope signatures against the public key.
+func Verify(env *Envelope, pub crypto.PublicKey) error {
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	pae := PAE(env.PayloadType, payload)
+
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		switch key := pub.(type) {
+		case *ecdsa.PublicKey:
+			digest := sha256.Sum256(pae)
+			if ecdsa.VerifyASN1(key, digest[:], sig) {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(key, pae, sig) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no valid signature found")
+}
@@ -0,0 +1,213 @@
+package provenance
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/container"
+	"github.com/containifyci/engine-ci/pkg/report"
+	"github.com/containifyci/engine-ci/pkg/svc"
+)
+
+const (
+	StatementType   = "https://in-toto.io/Statement/v1"
+	PredicateType   = "https://slsa.dev/provenance/v1"
+	BuildType       = "https://github.com/containifyci/engine-ci/buildtypes/v1"
+	BuilderID       = "https://github.com/containifyci/engine-ci"
+	PayloadType     = "application/vnd.in-toto+json"
+	digestAlgorithm = "sha256"
+	gitCommitDigest = "gitCommit"
+)
+
+// Statement is an in-toto v1 statement with a SLSA v1 provenance predicate.
+type Statement struct {
+	Type          string     `json:"_type"`
+	PredicateType string     `json:"predicateType"`
+	Subject       []Subject  `json:"subject"`
+	Predicate     Provenance `json:"predicate"`
+}
+
+type Subject struct {
+	Digest map[string]string `json:"digest"`
+	Name   string            `json:"name"`
+}
+
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	InternalParameters   map[string]any       `json:"internalParameters,omitempty"`
+	BuildType            string               `json:"buildType"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type ResourceDescriptor struct {
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]any    `json:"annotations,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Name        string            `json:"name,omitempty"`
+}
+
+type RunDetails struct {
+	Builder    Builder              `json:"builder"`
+	Metadata   Metadata             `json:"metadata"`
+	Byproducts []ResourceDescriptor `json:"byproducts,omitempty"`
+}
+
+type Builder struct {
+	Version map[string]string `json:"version,omitempty"`
+	ID      string            `json:"id"`
+}
+
+type Metadata struct {
+	StartedOn    time.Time `json:"startedOn"`
+	FinishedOn   time.Time `json:"finishedOn"`
+	InvocationID string    `json:"invocationId,omitempty"`
+}
+
+// Dependency is a resolved input of the build like a builder image.
+type Dependency struct {
+	URI    string
+	Digest string
+}
+
+// NewStatement creates the provenance statement for the given subjects
+// from the build configuration, git information and the run report.
+func NewStatement(build container.Build, rep *report.Report, subjects []Subject, deps []Dependency) Statement {
+	steps := rep.Steps(build.App)
+
+	started := rep.Started
+	finished := time.Now()
+	if len(steps) > 0 {
+		started = steps[0].Started
+	}
+
+	return Statement{
+		Type:          StatementType,
+		PredicateType: PredicateType,
+		Subject:       subjects,
+		Predicate: Provenance{
+			BuildDefinition: BuildDefinition{
+				BuildType:            BuildType,
+				ExternalParameters:   externalParameters(build),
+				InternalParameters:   internalParameters(build),
+				ResolvedDependencies: resolvedDependencies(rep, deps),
+			},
+			RunDetails: RunDetails{
+				Builder: Builder{
+					ID:      BuilderID,
+					Version: map[string]string{"engine-ci": svc.Version()},
+				},
+				Metadata: Metadata{
+					InvocationID: invocationID(),
+					StartedOn:    started.UTC(),
+					FinishedOn:   finished.UTC(),
+				},
+				Byproducts: byproducts(steps),
+			},
+		},
+	}
+}
+
+func externalParameters(build container.Build) map[string]any {
+	params := map[string]any{
+		"app":         build.App,
+		"buildType":   string(build.BuildType),
+		"environment": string(build.Env),
+		"image":       build.Image,
+		"imageTag":    build.ImageTag,
+		"registry":    build.Registry,
+		"folder":      build.Folder,
+		"file":        build.File,
+		"platform":    build.Platform.Container.OS + "/" + build.Platform.Container.Architecture,
+	}
+
+	git := svc.GitInfo()
+	if !git.IsUnknown() {
+		source := map[string]any{
+			"repository": git.FullRepo(),
+			"branch":     git.Branch,
+		}
+		if git.IsTag() {
+			source["tag"] = git.Tag
+		}
+		if git.IsPR() {
+			source["pullRequest"] = git.PrNum
+		}
+		params["source"] = source
+	}
+	return params
+}
+
+// internalParameters only contains the property keys, the values
+// could contain secrets or secret references.
+func internalParameters(build container.Build) map[string]any {
+	if len(build.Custom) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(build.Custom))
+	for k := range build.Custom {
+		keys = append(keys, k)
+	}
+	return map[string]any{"properties": keys}
+}
+
+func resolvedDependencies(rep *report.Report, deps []Dependency) []ResourceDescriptor {
+	var resolved []ResourceDescriptor
+
+	git := svc.GitInfo()
+	if !git.IsUnknown() {
+		source := ResourceDescriptor{
+			URI: fmt.Sprintf("git+https://github.com/%s", git.FullRepo()),
+		}
+		if commit := svc.GitCommit(); commit != "" {
+			source.Digest = map[string]string{gitCommitDigest: commit}
+		}
+		resolved = append(resolved, source)
+	}
+
+	if file, checksum := rep.Config(); file != "" {
+		resolved = append(resolved, ResourceDescriptor{
+			Name:   file,
+			Digest: map[string]string{digestAlgorithm: checksum},
+		})
+	}
+
+	for _, dep := range deps {
+		rd := ResourceDescriptor{URI: "docker://" + dep.URI}
+		if dep.Digest != "" {
+			rd.Digest = map[string]string{digestAlgorithm: strings.TrimPrefix(dep.Digest, "sha256:")}
+		}
+		resolved = append(resolved, rd)
+	}
+	return resolved
+}
+
+func byproducts(steps []report.Step) []ResourceDescriptor {
+	products := make([]ResourceDescriptor, 0, len(steps))
+	for _, step := range steps {
+		products = append(products, ResourceDescriptor{
+			Name: "step/" + step.Name,
+			Annotations: map[string]any{
+				"status":     string(step.Status),
+				"startedOn":  step.Started.UTC(),
+				"finishedOn": step.Finished.UTC(),
+			},
+		})
+	}
+	return products
+}
+
+func invocationID() string {
+	for _, key := range []string{"GITHUB_RUN_ID", "CI_PIPELINE_ID"} {
+		if id := os.Getenv(key); id != "" {
+			return id
+		}
+	}
+	return ""
+}
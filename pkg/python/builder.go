@@ -3,6 +3,7 @@ package python
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -91,7 +92,7 @@ func (b *Builder) Analyze() (Tool, error) {
 // TODO: abstract tool into its own struct with build method
 func (b *Builder) Build() (Commands, error) {
 
-	fmt.Printf("🔎 Detected build tool: %s\n", b.Tool)
+	slog.Info("🔎 Detected build tool", "tool", b.Tool)
 
 	// Build command plan
 	var plan Commands
@@ -129,7 +130,7 @@ func (b *Builder) Build() (Commands, error) {
 
 func (b *Builder) Install() (Commands, error) {
 	folder := "/app" //b.Folder
-	fmt.Printf("🔎 Detected Install tool: %s\n", b.Tool)
+	slog.Info("🔎 Detected install tool", "tool", b.Tool)
 	// Build command plan
 	var plan Commands
 	plan = append(plan, []string{"ls", "-lha", folder + "/dist/"})
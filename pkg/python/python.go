@@ -301,7 +301,7 @@ func (c *PythonContainer) Prod() (string, error) {
 		}
 	}
 
-	imageId, err := c.Commit(fmt.Sprintf("%s:%s", c.Image, c.ImageTag), "Created from container", "CMD [\"python\", \"-m\", \""+c.File+"\"]", "WORKDIR /app") /*, "USER 185")*/
+	imageId, err := c.CommitProd(fmt.Sprintf("%s:%s", c.Image, c.ImageTag), "Created from container", "CMD [\"python\", \"-m\", \""+c.File+"\"]", "WORKDIR /app") /*, "USER 185")*/
 	if err != nil {
 		slog.Error("Failed to commit container: %s", "error", err)
 		os.Exit(1)
@@ -313,8 +313,7 @@ func (c *PythonContainer) Prod() (string, error) {
 		os.Exit(1)
 	}
 
-	imageUri := utils.ImageURI(c.GetBuild().Registry, c.Image, c.ImageTag)
-	err = c.Push(imageId, imageUri, container.PushOption{Remove: false})
+	err = c.ExportOrPush(imageId, container.PushOption{Remove: false})
 	if err != nil {
 		slog.Error("Failed to push image: %s", "error", err)
 		os.Exit(1)
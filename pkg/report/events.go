@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventBuildStarted     EventType = "build.started"
+	EventBuildFinished    EventType = "build.finished"
+	EventStepStarted      EventType = "step.started"
+	EventStepFinished     EventType = "step.finished"
+	EventStepSkipped      EventType = "step.skipped"
+	EventContainerCreated EventType = "container.created"
+	EventImagePulled      EventType = "image.pulled"
+	EventImagePushed      EventType = "image.pushed"
+	EventLog              EventType = "log"
+)
+
+// subscriberBuffer is the number of events buffered per subscriber. Events
+// are dropped for subscribers that are too slow to not block the build.
+const subscriberBuffer = 256
+
+// Event is a run lifecycle event. The step and build use the same model as
+// the written run report.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Step      *Step     `json:"step,omitempty"`
+	Build     *Build    `json:"build,omitempty"`
+	Type      EventType `json:"type"`
+	App       string    `json:"app,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+type subscribers struct {
+	subs map[chan Event]struct{}
+	mu   sync.RWMutex
+}
+
+// Subscribe returns a channel receiving all following events and a
+// function to cancel the subscription.
+func (r *Report) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	r.events.mu.Lock()
+	if r.events.subs == nil {
+		r.events.subs = map[chan Event]struct{}{}
+	}
+	r.events.subs[ch] = struct{}{}
+	r.events.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.events.mu.Lock()
+			delete(r.events.subs, ch)
+			r.events.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends the event to all subscribers.
+func (r *Report) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	r.events.mu.RLock()
+	defer r.events.mu.RUnlock()
+	for ch := range r.events.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// EventsHandler streams the events as server-sent events. The optional app
+// query parameter only streams the events of one build.
+func EventsHandler(r *Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+		app := req.URL.Query().Get("app")
+
+		events, cancel := r.Subscribe()
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-req.Context().Done():
+				return
+			case e := <-events:
+				if app != "" && e.App != app {
+					continue
+				}
+				data, err := json.Marshal(e)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func next(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	r := New()
+	events, cancel := r.Subscribe()
+	defer cancel()
+
+	buildDone := r.StartBuild("app")
+	r.StartStep("app", "golang", "golang:1.26")(errors.New("failed"))
+	r.SkipStep("app", "trivy")
+	r.AddPushed("app", "ghcr.io/org/app:v1")
+	buildDone(nil)
+
+	e := next(t, events)
+	assert.Equal(t, EventBuildStarted, e.Type)
+	assert.Equal(t, StatusRunning, e.Build.Status)
+
+	e = next(t, events)
+	assert.Equal(t, EventStepStarted, e.Type)
+	assert.Equal(t, "golang", e.Step.Name)
+
+	e = next(t, events)
+	assert.Equal(t, EventStepFinished, e.Type)
+	assert.Equal(t, StatusFailed, e.Step.Status)
+	assert.Equal(t, "failed", e.Step.Error)
+
+	assert.Equal(t, EventStepSkipped, next(t, events).Type)
+	assert.Equal(t, "ghcr.io/org/app:v1", next(t, events).Image)
+
+	e = next(t, events)
+	assert.Equal(t, EventBuildFinished, e.Type)
+	assert.Equal(t, StatusSuccess, e.Build.Status)
+
+	builds := r.Builds()
+	require.Len(t, builds, 1)
+	assert.Equal(t, StatusSuccess, builds[0].Status)
+}
+
+func TestPublishDoesNotBlock(t *testing.T) {
+	r := New()
+	_, cancel := r.Subscribe()
+	defer cancel()
+	for range subscriberBuffer + 10 {
+		r.Publish(Event{Type: EventLog})
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	r := New()
+	srv := httptest.NewServer(EventsHandler(r))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?app=app", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r.Publish(Event{Type: EventLog, App: "other", Message: "ignored"})
+	r.Publish(Event{Type: EventLog, App: "app", Message: "go build"})
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: log\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	var e Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+	assert.Equal(t, "go build", e.Message)
+}
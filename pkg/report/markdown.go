@@ -0,0 +1,64 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var statusIcons = map[Status]string{
+	StatusRunning: ":hourglass:",
+	StatusSuccess: ":white_check_mark:",
+	StatusFailed:  ":x:",
+	StatusSkipped: ":fast_forward:",
+}
+
+// Markdown renders the builds, steps, durations and pushed images as Markdown
+// summary, e.g. for the GitHub Actions job summary.
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("## engine-ci\n\n")
+
+	builds := r.Builds()
+	if len(builds) == 0 {
+		sb.WriteString("No builds were executed.\n")
+		return sb.String()
+	}
+
+	for _, b := range builds {
+		fmt.Fprintf(&sb, "### %s\n\n", b.App)
+		if b.Status == StatusSkipped {
+			fmt.Fprintf(&sb, "%s Skipped: %s\n\n", statusIcons[b.Status], b.Reason)
+			continue
+		}
+		sb.WriteString("| Step | Status | Duration |\n|------|--------|----------|\n")
+		for _, s := range b.Steps {
+			status := fmt.Sprintf("%s %s", statusIcons[s.Status], s.Status)
+			if s.Error != "" {
+				status += ": " + markdownCell(s.Error)
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n", s.Name, status, s.Duration().Round(time.Millisecond))
+		}
+		sb.WriteString("\n")
+
+		if len(b.Pushed) > 0 {
+			sb.WriteString("**Pushed images**\n\n")
+			for _, image := range b.Pushed {
+				fmt.Fprintf(&sb, "- `%s`\n", image)
+			}
+			sb.WriteString("\n")
+		}
+		if len(b.Artifacts) > 0 {
+			sb.WriteString("**Artifacts**\n\n")
+			for _, a := range b.Artifacts {
+				fmt.Fprintf(&sb, "- `%s` (%s)\n", a.Path, a.Type)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ").Replace(s)
+}
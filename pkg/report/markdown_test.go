@@ -0,0 +1,34 @@
+package report
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	r := New()
+	r.StartStep("app", "golang")(nil)
+	r.StartStep("app", "golang-prod")(errors.New("push | failed\nretry"))
+	r.AddPushed("app", "ghcr.io/org/app:v1")
+
+	md := r.Markdown()
+	assert.Contains(t, md, "### app")
+	assert.Contains(t, md, "| golang | :white_check_mark: success |")
+	assert.Contains(t, md, "| golang-prod | :x: failed: push \\| failed retry |")
+	assert.Contains(t, md, "- `ghcr.io/org/app:v1`")
+}
+
+func TestMarkdownSkipped(t *testing.T) {
+	r := New()
+	r.SkipBuild("web", "not affected by the changes since main")
+
+	md := r.Markdown()
+	assert.Contains(t, md, "### web\n\n:fast_forward: Skipped: not affected by the changes since main")
+	assert.Equal(t, StatusSkipped, r.Builds()[0].Status)
+}
+
+func TestMarkdownEmpty(t *testing.T) {
+	assert.Contains(t, New().Markdown(), "No builds were executed.")
+}
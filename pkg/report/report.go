@@ -0,0 +1,299 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containifyci/engine-ci/pkg/logger"
+)
+
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Step is the record of a single build step execution.
+type Step struct {
+	Started  time.Time `json:"started,omitzero"`
+	Finished time.Time `json:"finished,omitzero"`
+	Name     string    `json:"name"`
+	Status   Status    `json:"status,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Images   []string  `json:"images,omitempty"`
+}
+
+// Duration returns the step duration or the elapsed time when still running.
+func (s Step) Duration() time.Duration {
+	if s.Finished.IsZero() {
+		return time.Since(s.Started)
+	}
+	return s.Finished.Sub(s.Started)
+}
+
+// Artifact is a file produced by a build like an exported image.
+type Artifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// Build collects the steps and artifacts of one build (application).
+type Build struct {
+	Started   time.Time  `json:"started,omitzero"`
+	Finished  time.Time  `json:"finished,omitzero"`
+	App       string     `json:"app"`
+	Status    Status     `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Steps     []*Step    `json:"steps"`
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	Pushed    []string   `json:"pushed,omitempty"`
+}
+
+// Report records what happened during an engine-ci run.
+type Report struct {
+	Started        time.Time `json:"started"`
+	builds         map[string]*Build
+	ConfigFile     string `json:"config_file,omitempty"`
+	ConfigChecksum string `json:"config_checksum,omitempty"`
+	events         subscribers
+	mu             sync.RWMutex
+}
+
+var (
+	defaultReport *Report
+	once          sync.Once
+)
+
+// Default returns the process wide report.
+func Default() *Report {
+	once.Do(func() {
+		defaultReport = New()
+	})
+	return defaultReport
+}
+
+func New() *Report {
+	return &Report{
+		Started: time.Now(),
+		builds:  map[string]*Build{},
+	}
+}
+
+// SetConfig records the build configuration file and its checksum.
+func (r *Report) SetConfig(file, checksum string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ConfigFile = file
+	r.ConfigChecksum = checksum
+}
+
+func (r *Report) Config() (string, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ConfigFile, r.ConfigChecksum
+}
+
+// StartBuild records the start of a build and returns a function
+// that has to be called with the build result once it finished.
+func (r *Report) StartBuild(app string) func(err error) {
+	r.mu.Lock()
+	b := r.build(app)
+	b.Started = time.Now()
+	b.Status = StatusRunning
+	r.mu.Unlock()
+	r.Publish(Event{Type: EventBuildStarted, App: app, Build: &Build{App: app, Started: b.Started, Status: StatusRunning}})
+
+	return func(err error) {
+		r.mu.Lock()
+		b.Finished = time.Now()
+		b.Status = StatusSuccess
+		if err != nil {
+			b.Status = StatusFailed
+			b.Error = logger.Redact(err.Error())
+		}
+		snapshot := Build{App: app, Started: b.Started, Finished: b.Finished, Status: b.Status, Error: b.Error}
+		r.mu.Unlock()
+		r.Publish(Event{Type: EventBuildFinished, App: app, Build: &snapshot})
+	}
+}
+
+// SkipBuild records that the build did not run and why.
+func (r *Report) SkipBuild(app, reason string) {
+	r.mu.Lock()
+	b := r.build(app)
+	b.Status = StatusSkipped
+	b.Reason = reason
+	snapshot := Build{App: app, Status: b.Status, Reason: b.Reason}
+	r.mu.Unlock()
+	r.Publish(Event{Type: EventBuildFinished, App: app, Build: &snapshot})
+}
+
+// StartStep records the start of a step and returns a function
+// that has to be called with the step result once it finished.
+func (r *Report) StartStep(app, name string, images ...string) func(err error) {
+	step := &Step{
+		Name:    name,
+		Status:  StatusRunning,
+		Started: time.Now(),
+		Images:  images,
+	}
+
+	r.mu.Lock()
+	b := r.build(app)
+	b.Steps = append(b.Steps, step)
+	snapshot := *step
+	r.mu.Unlock()
+	r.Publish(Event{Type: EventStepStarted, App: app, Step: &snapshot})
+
+	return func(err error) {
+		r.mu.Lock()
+		step.Finished = time.Now()
+		step.Status = StatusSuccess
+		if err != nil {
+			step.Status = StatusFailed
+			step.Error = logger.Redact(err.Error())
+		}
+		snapshot := *step
+		r.mu.Unlock()
+		r.Publish(Event{Type: EventStepFinished, App: app, Step: &snapshot})
+	}
+}
+
+// SkipStep publishes that the step did not run for the build.
+func (r *Report) SkipStep(app, name string) {
+	r.Publish(Event{Type: EventStepSkipped, App: app, Step: &Step{Name: name}})
+}
+
+// AddArtifact records an artifact produced by the build of the given app.
+func (r *Report) AddArtifact(app string, artifact Artifact) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := r.build(app)
+	b.Artifacts = append(b.Artifacts, artifact)
+}
+
+// AddPushed records an image pushed by the build of the given app.
+func (r *Report) AddPushed(app, image string) {
+	r.mu.Lock()
+	b := r.build(app)
+	b.Pushed = append(b.Pushed, image)
+	r.mu.Unlock()
+	r.Publish(Event{Type: EventImagePushed, App: app, Image: image})
+}
+
+// Artifacts returns a copy of the recorded artifacts of the given app.
+func (r *Report) Artifacts(app string) []Artifact {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.builds[app]
+	if !ok {
+		return nil
+	}
+	return append([]Artifact(nil), b.Artifacts...)
+}
+
+// buildInfo returns a copy of the build without steps, artifacts and pushed images.
+func (r *Report) buildInfo(app string) Build {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b := r.builds[app]
+	return Build{App: app, Started: b.Started, Finished: b.Finished, Status: b.Status, Error: b.Error, Reason: b.Reason}
+}
+
+// Pushed returns a copy of the images pushed by the build of the given app.
+func (r *Report) Pushed(app string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if b, ok := r.builds[app]; ok {
+		return append([]string(nil), b.Pushed...)
+	}
+	return nil
+}
+
+// build has to be called with the lock held.
+func (r *Report) build(app string) *Build {
+	b, ok := r.builds[app]
+	if !ok {
+		b = &Build{App: app}
+		r.builds[app] = b
+	}
+	return b
+}
+
+// Steps returns a copy of the recorded steps of the given app.
+func (r *Report) Steps(app string) []Step {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.builds[app]
+	if !ok {
+		return nil
+	}
+	steps := make([]Step, 0, len(b.Steps))
+	for _, s := range b.Steps {
+		steps = append(steps, *s)
+	}
+	return steps
+}
+
+// Builds returns a copy of all recorded builds sorted by app name.
+func (r *Report) Builds() []Build {
+	r.mu.RLock()
+	apps := make([]string, 0, len(r.builds))
+	for app := range r.builds {
+		apps = append(apps, app)
+	}
+	r.mu.RUnlock()
+	sort.Strings(apps)
+
+	builds := make([]Build, 0, len(apps))
+	for _, app := range apps {
+		b := r.buildInfo(app)
+		b.Artifacts = r.Artifacts(app)
+		b.Pushed = r.Pushed(app)
+		for _, s := range r.Steps(app) {
+			b.Steps = append(b.Steps, &s)
+		}
+		builds = append(builds, b)
+	}
+	return builds
+}
+
+// WriteFile writes the report as JSON to the file.
+func (r *Report) WriteFile(file string) error {
+	r.mu.RLock()
+	data := struct {
+		Started        time.Time `json:"started"`
+		ConfigFile     string    `json:"config_file,omitempty"`
+		ConfigChecksum string    `json:"config_checksum,omitempty"`
+		Builds         []Build   `json:"builds"`
+	}{
+		Started:        r.Started,
+		ConfigFile:     r.ConfigFile,
+		ConfigChecksum: r.ConfigChecksum,
+	}
+	r.mu.RUnlock()
+	data.Builds = r.Builds()
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create report folder: %w", err)
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
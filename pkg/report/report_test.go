@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStep(t *testing.T) {
+	r := New()
+
+	done := r.StartStep("app", "build", "golang:1.26")
+	steps := r.Steps("app")
+	require.Len(t, steps, 1)
+	assert.Equal(t, StatusRunning, steps[0].Status)
+	assert.True(t, steps[0].Finished.IsZero())
+
+	done(nil)
+	r.StartStep("app", "lint")(errors.New("lint failed"))
+
+	steps = r.Steps("app")
+	require.Len(t, steps, 2)
+	assert.Equal(t, StatusSuccess, steps[0].Status)
+	assert.Equal(t, []string{"golang:1.26"}, steps[0].Images)
+	assert.False(t, steps[0].Finished.IsZero())
+	assert.Equal(t, StatusFailed, steps[1].Status)
+	assert.Equal(t, "lint failed", steps[1].Error)
+}
+
+func TestBuilds(t *testing.T) {
+	r := New()
+	r.StartStep("b", "build")(nil)
+	r.StartStep("a", "build")(nil)
+
+	builds := r.Builds()
+	require.Len(t, builds, 2)
+	assert.Equal(t, "a", builds[0].App)
+	assert.Equal(t, "b", builds[1].App)
+	assert.Nil(t, r.Steps("unknown"))
+}
+
+func TestWriteFile(t *testing.T) {
+	r := New()
+	r.StartStep("app", "build")(nil)
+	r.AddArtifact("app", Artifact{Name: "app:v1", Path: "app-v1.tar", Type: "docker-archive", Digest: "sha256:1234"})
+
+	file := filepath.Join(t.TempDir(), "report", "report.json")
+	require.NoError(t, r.WriteFile(file))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	var data struct {
+		Builds []Build `json:"builds"`
+	}
+	require.NoError(t, json.Unmarshal(content, &data))
+	require.Len(t, data.Builds, 1)
+	assert.Equal(t, "sha256:1234", data.Builds[0].Artifacts[0].Digest)
+	assert.Equal(t, StatusSuccess, data.Builds[0].Steps[0].Status)
+}
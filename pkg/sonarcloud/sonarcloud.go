@@ -28,7 +28,7 @@ type SonarcloudContainer struct {
 
 // Matches implements the Build interface - SonarCloud runs for all builds
 func Matches(build container.Build) bool {
-	_token := container.GetEnv("SONAR_TOKEN")
+	_token := container.GetSecret("SONAR_TOKEN")
 	if _token == "" {
 		slog.Warn("SONAR_TOKEN is not set skip sonar analysis")
 		return false
@@ -56,13 +56,18 @@ func new(build container.Build) *SonarcloudContainer {
 	}
 }
 
-func CacheFolder() string {
+// CachePath returns the sonar cache folder without creating it.
+func CachePath() (string, error) {
 	dir := os.Getenv("CONTAINIFYCI_CACHE")
 	if dir == "" {
 		usr, _ := user.Current()
 		dir = usr.HomeDir
 	}
-	folder, err := filepath.Abs(filepath.Join(dir, "/.sonar/cache"))
+	return filepath.Abs(filepath.Join(dir, "/.sonar/cache"))
+}
+
+func CacheFolder() string {
+	folder, err := CachePath()
 	if err != nil {
 		slog.Error("Failed to get cache folder: %s", "error", err)
 		os.Exit(1)
@@ -103,7 +108,7 @@ sonar-scanner -X -Dsonar.projectBaseDir=/usr/src/%s -Dsonar.working.directory=/t
 
 func (c *SonarcloudContainer) Analyze(env container.EnvType, token *string, address *network.Address) error {
 	if token == nil || *token == "" {
-		_token := container.GetEnv("SONAR_TOKEN")
+		_token := container.GetSecret("SONAR_TOKEN")
 		if _token == "" {
 			slog.Warn("SONAR_TOKEN is not set skip sonar analysis")
 			return nil
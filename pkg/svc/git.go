@@ -282,3 +282,22 @@ func GitInfoFromEnv() (*Git, error) {
 	}
 	return git, nil
 }
+
+// GitCommit returns the commit SHA of the current checkout.
+// It prefers the CI provided environment variables over the git command.
+func GitCommit() string {
+	for _, key := range []string{"GITHUB_SHA", "COMMIT_SHA"} {
+		if sha := os.Getenv(key); sha != "" {
+			return sha
+		}
+	}
+	if !gitExists() {
+		return ""
+	}
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		slog.Debug("Failed to get git commit", "error", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,13 @@
+package svc
+
+var version = "dev"
+
+// SetVersion stores the engine-ci version the binary was built with.
+func SetVersion(v string) {
+	if v != "" {
+		version = v
+	}
+}
+
+// Version returns the engine-ci version.
+func Version() string { return version }
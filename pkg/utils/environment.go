@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/containifyci/engine-ci/pkg/kv"
+	"github.com/containifyci/engine-ci/pkg/logger"
 )
 
 func GetEnvWithDefault(key string, def func() string) string {
@@ -53,7 +54,35 @@ func GetEnv(key string, envType string) string {
 	return GetValue(env, envType)
 }
 
+// GetSecret resolves the environment variable like GetEnv and registers the
+// value as secret to be redacted from the log output.
+func GetSecret(key string, envType string) string {
+	v := GetEnv(key, envType)
+	logger.RegisterSecret(v)
+	return v
+}
+
+// GetValue resolves env:, cmd: and mem: references.
 func GetValue(value string, envType string) string {
+	return GetBuildValue("", value, envType)
+}
+
+// GetBuildValue resolves the references like GetValue. A mem:key reference is
+// looked up in the namespace of the build app, then in the global namespace.
+// mem:global/key and mem:app/key address a namespace explicitly.
+func GetBuildValue(app, value string, envType string) string {
+	return getValue(app, value, envType)
+}
+
+// GetSecretValue resolves the references like GetBuildValue and registers the
+// value as secret to be redacted from the log output.
+func GetSecretValue(app, value string, envType string) string {
+	v := GetBuildValue(app, value, envType)
+	logger.RegisterSecret(v)
+	return v
+}
+
+func getValue(app, value string, envType string) string {
 	if strings.HasPrefix(value, "env:") {
 		return Getenv(strings.TrimPrefix(value, "env:"), envType)
 	}
@@ -71,7 +100,7 @@ func GetValue(value string, envType string) string {
 	if strings.HasPrefix(value, "mem:") {
 		key := strings.TrimPrefix(value, "mem:")
 
-		val, ok := kv.NewKeyValueStore().GetVal(key)
+		val, ok := kv.NewKeyValueStore().Resolve(app, key)
 		if !ok {
 			slog.Warn("Key not found in memory")
 			Getenv(key, envType)
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/containifyci/engine-ci/pkg/kv"
+	"github.com/containifyci/engine-ci/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -153,6 +154,17 @@ func TestGetMemValue(t *testing.T) {
 	assert.Equal(t, "", val)
 }
 
+func TestGetBuildValue_Namespaces(t *testing.T) {
+	store := kv.NewKeyValueStore()
+	store.SetVal("app/token", "app token")
+	store.SetVal("global/token", "global token")
+
+	assert.Equal(t, "app token", GetBuildValue("app", "mem:token", "local"))
+	assert.Equal(t, "global token", GetBuildValue("other", "mem:token", "local"))
+	assert.Equal(t, "global token", GetValue("mem:token", "local"))
+	assert.Equal(t, "app token", GetValue("mem:app/token", "local"))
+}
+
 func TestRunCommand(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -200,3 +212,18 @@ func TestRunCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSecretRegistersSecret(t *testing.T) {
+	t.Setenv("REDACT_TEST_TOKEN", "redact-test-value")
+	t.Setenv("REDACT_TEST_PLAIN", "plain-test-value")
+
+	assert.Equal(t, "plain-test-value", GetEnv("REDACT_TEST_PLAIN", "build"))
+	assert.Equal(t, "plain-test-value", logger.Redact("plain-test-value"))
+
+	assert.Equal(t, "redact-test-value", GetSecret("REDACT_TEST_TOKEN", "build"))
+	assert.Equal(t, "token ***", logger.Redact("token redact-test-value"))
+
+	t.Setenv("REDACT_TEST_REF", "redact-ref-value")
+	assert.Equal(t, "redact-ref-value", GetSecretValue("", "env:REDACT_TEST_REF", "build"))
+	assert.Equal(t, "ref ***", logger.Redact("ref redact-ref-value"))
+}
@@ -10,16 +10,21 @@ import (
 	"text/template"
 
 	"github.com/containifyci/engine-ci/pkg/cri/types"
+	"github.com/containifyci/engine-ci/pkg/kv"
 )
 
 type BuildScript struct {
-	Folder      string
-	CacheDir    string
-	Optimize    string
-	Target      string
-	Platforms   []*types.PlatformSpec
-	Verbose     bool
-	HasBuildZon bool
+	// App is the name of the cross compiled binaries, they are published
+	// as artifacts of the build when it is set
+	App           string
+	Folder        string
+	CacheDir      string
+	Optimize      string
+	Target        string
+	Platforms     []*types.PlatformSpec
+	ProdPlatforms []*types.PlatformSpec
+	Verbose       bool
+	HasBuildZon   bool
 }
 
 func NewBuildScript(folder string, optimize string, target string, verbose bool, cacheDir string, platform []*types.PlatformSpec) *BuildScript {
@@ -107,6 +112,19 @@ func zigBuildCmds(bs *BuildScript) string {
 
 	cmds = append(cmds, buildCmd)
 
+	// cross compile the binaries of the multi-arch prod image
+	for _, platform := range bs.ProdPlatforms {
+		crossCmd := "zig build --color off"
+		if bs.Optimize != "" {
+			crossCmd += fmt.Sprintf(" -Doptimize=%s", bs.Optimize)
+		}
+		crossCmd += fmt.Sprintf(" -Dtarget=%s --prefix %s", ZigTarget(platform), ProdPrefix(platform))
+		cmds = append(cmds, crossCmd)
+		if bs.App != "" {
+			cmds = append(cmds, publishCmd(bs.App, platform))
+		}
+	}
+
 	testCmd := "zig test "
 	if bs.Folder != "" && bs.Folder != "." {
 		testCmd += fmt.Sprintf("%s/*.zig", bs.Folder)
@@ -127,3 +145,37 @@ func zigBuildCmds(bs *BuildScript) string {
 
 	return strings.Join(cmds, "\n")
 }
+
+// publishCmd publishes the cross compiled binary to the engine so that the
+// later steps of the run can fetch and attest it, a failed upload does not
+// fail the build.
+func publishCmd(app string, platform *types.PlatformSpec) string {
+	name := fmt.Sprintf("%s-%s", app, platform.FileName())
+	file := filepath.Join(ProdPrefix(platform), "bin", app)
+	return fmt.Sprintf(`if [ -n "${CONTAINIFYCI_HOST}" ]; then %s || echo "Failed to publish %s"; fi`, kv.PublishArtifactScript(app, name, file), name)
+}
+
+// ZigTarget converts the platform to the zig target triple.
+func ZigTarget(platform *types.PlatformSpec) string {
+	arch := platform.Architecture
+	switch arch {
+	case "amd64":
+		arch = "x86_64"
+	case "arm64":
+		arch = "aarch64"
+	case "386":
+		arch = "x86"
+	}
+	if platform.OS == "linux" && arch == "arm" {
+		return "arm-linux-musleabihf"
+	}
+	if platform.OS == "linux" {
+		return fmt.Sprintf("%s-linux-musl", arch)
+	}
+	return fmt.Sprintf("%s-%s", arch, platform.OS)
+}
+
+// ProdPrefix is the install prefix of the cross compiled platform binaries.
+func ProdPrefix(platform *types.PlatformSpec) string {
+	return filepath.Join("zig-out", platform.FileName())
+}
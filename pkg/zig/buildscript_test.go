@@ -141,3 +141,25 @@ func TestBuildScript_WithoutBuildZon(t *testing.T) {
 
 	assert.Contains(t, script, "zig build --color off --summary all")
 }
+
+func TestBuildScript_ProdPlatforms(t *testing.T) {
+	bs := NewBuildScript("/src", "ReleaseSmall", "", false, "", platforms)
+	bs.ProdPlatforms = types.ParsePlatforms("linux/amd64", "linux/arm64", "linux/arm/v7")
+
+	script := bs.Script()
+
+	assert.Contains(t, script, "zig build --color off -Doptimize=ReleaseSmall -Dtarget=x86_64-linux-musl --prefix zig-out/linux-amd64")
+	assert.Contains(t, script, "zig build --color off -Doptimize=ReleaseSmall -Dtarget=aarch64-linux-musl --prefix zig-out/linux-arm64")
+	assert.Contains(t, script, "zig build --color off -Doptimize=ReleaseSmall -Dtarget=arm-linux-musleabihf --prefix zig-out/linux-armv7")
+	assert.NotContains(t, script, "/artifacts/")
+
+	// the binaries are published for the later steps of the run
+	bs.App = "app"
+	script = bs.Script()
+	assert.Contains(t, script, `-T "zig-out/linux-armv7/bin/app" "${CONTAINIFYCI_HOST}/artifacts/app/app-linux-armv7" || echo "Failed to publish app-linux-armv7"`)
+}
+
+func TestZigTarget(t *testing.T) {
+	assert.Equal(t, "x86_64-linux-musl", ZigTarget(types.ParsePlatform("linux/amd64")))
+	assert.Equal(t, "aarch64-macos", ZigTarget(&types.PlatformSpec{OS: "macos", Architecture: "arm64"}))
+}
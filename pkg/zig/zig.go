@@ -226,7 +226,10 @@ func (c *ZigContainer) Build() (string, string, error) {
 }
 
 func (c *ZigContainer) BuildScript() *BuildScript {
-	return NewBuildScript(c.Folder, c.Optimize, c.Target, c.Verbose, CacheLocation, c.Platforms)
+	bs := NewBuildScript(c.Folder, c.Optimize, c.Target, c.Verbose, CacheLocation, c.Platforms)
+	bs.ProdPlatforms = types.ParsePlatforms(c.GetBuild().ProdPlatforms()...)
+	bs.App = c.App
+	return bs
 }
 
 func NewProd() build.BuildStep {
@@ -258,6 +261,10 @@ func (c *ZigContainer) Prod() (string, error) {
 		return image, nil
 	}
 
+	if platforms := c.GetBuild().PushPlatforms(); len(platforms) > 0 {
+		return c.ProdByPlatforms(platforms)
+	}
+
 	opts := types.ContainerConfig{}
 	opts.Image = BaseImage
 	opts.Env = []string{}
@@ -299,7 +306,7 @@ func (c *ZigContainer) Prod() (string, error) {
 		appCmd = "app"
 	}
 
-	imageId, err := c.Commit(
+	imageId, err := c.CommitProd(
 		fmt.Sprintf("%s:%s", c.Image, c.ImageTag),
 		"Created from Zig production build",
 		fmt.Sprintf("CMD [\"/app/bin/%s\"]", appCmd),
@@ -316,8 +323,7 @@ func (c *ZigContainer) Prod() (string, error) {
 		os.Exit(1)
 	}
 
-	imageUri := utils.ImageURI(c.GetBuild().Registry, c.Image, c.ImageTag)
-	err = c.Push(imageId, imageUri, container.PushOption{Remove: false})
+	err = c.ExportOrPush(imageId, container.PushOption{Remove: false})
 	if err != nil {
 		slog.Error("Failed to push image", "error", err)
 		os.Exit(1)
@@ -326,6 +332,46 @@ func (c *ZigContainer) Prod() (string, error) {
 	return c.ID, err
 }
 
+// ProdByPlatforms builds the prod image for all platforms from the cross compiled
+// binaries and pushes it as manifest list.
+func (c *ZigContainer) ProdByPlatforms(platforms []string) (string, error) {
+	if !c.GetBuild().ShouldPush() {
+		slog.Info("Skip multi-arch prod image it can only be built when pushing", "platforms", platforms)
+		return "", nil
+	}
+
+	appCmd := c.App
+	if appCmd == "" {
+		appCmd = "app"
+	}
+
+	files := map[string]string{}
+	for _, platform := range types.ParsePlatforms(platforms...) {
+		name := fmt.Sprintf("%s-%s", appCmd, platform.FileName())
+		files[name] = filepath.Join(ProdPrefix(platform), "bin", appCmd)
+	}
+
+	image, err := c.BuildProdImageByPlatforms(ProdDockerfile(appCmd), files, platforms)
+	if err != nil {
+		slog.Error("Failed to build multi-arch prod image", "error", err)
+		return c.ID, err
+	}
+	return image, nil
+}
+
+// ProdDockerfile returns the Dockerfile of the multi-arch prod image. The binary
+// is selected by the TARGETOS, TARGETARCH and TARGETVARIANT build args.
+func ProdDockerfile(app string) string {
+	return fmt.Sprintf(`FROM %[2]s
+ARG TARGETOS
+ARG TARGETARCH
+ARG TARGETVARIANT
+COPY %[1]s-${TARGETOS}-${TARGETARCH}${TARGETVARIANT} /app/bin/%[1]s
+WORKDIR /app
+CMD ["/app/bin/%[1]s"]
+`, app, BaseImage)
+}
+
 func (c *ZigContainer) Run() (string, error) {
 	err := c.Pull()
 	if err != nil {